@@ -0,0 +1,260 @@
+package loggy
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Async configures asynchronous logging, where messages are queued, then
+// encoded and written by a background goroutine, keeping slow writes off the
+// goroutines that log. See Options.Async.
+//
+// Messages are still filtered, formatted, and processed on the goroutine that
+// logs them, so their arguments may be reused afterwards, but tag and field
+// values must not be modified once logged. Write errors can't be returned to
+// the caller; they're counted in the shutdown summary instead. Call
+// Logger.Flush, or Logger.Close, before exiting, so that queued messages
+// aren't lost.
+type Async struct {
+	// The maximum number of messages waiting to be written. If zero, 1024 is
+	// used.
+	QueueSize int
+	// What happens to a message logged while the queue is full. The zero value,
+	// OverflowBlock, waits for room.
+	Overflow Overflow
+	// Messages that have waited in the queue for longer than this, e.g. while a
+	// destination was stalled, are dropped rather than written late, counted by
+	// Logger.AsyncExpired, and traced to Options.DropTrace with the reason
+	// DropExpired. Messages logged with Bypass never expire. If zero, messages
+	// never expire.
+	TTL time.Duration
+}
+
+// DefaultAsyncQueueSize is the queue size used when Async.QueueSize is zero.
+const DefaultAsyncQueueSize = 1024
+
+// Overflow is the policy for messages logged while the Async queue is full,
+// trading the completeness of the logs against the latency of logging.
+// Dropped messages are counted by Logger.AsyncDropped, and traced to
+// Options.DropTrace with the reason DropQueueFull.
+type Overflow int
+
+const (
+	// OverflowBlock waits until there's room in the queue, so that no messages
+	// are lost, at the cost of slowing the goroutines that log.
+	OverflowBlock Overflow = iota
+	// OverflowDropNewest drops the message being logged, keeping the queued
+	// messages.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued message to make room for the
+	// message being logged, favoring recent messages.
+	OverflowDropOldest
+)
+
+// enqueueResult is the outcome of adding an entry to the queue.
+type enqueueResult int
+
+const (
+	enqueued enqueueResult = iota
+	// The entry was dropped, as the queue was full.
+	rejected
+	// The queue has been closed, so the entry must be written synchronously.
+	closedQueue
+)
+
+// asyncQueue writes entries in the background, in the order they were queued.
+type asyncQueue struct {
+	entries  chan asyncEntry
+	overflow Overflow
+	ttl      time.Duration
+	// The number of entries dropped as the queue was full, and the number
+	// dropped as they waited for longer than the TTL.
+	dropped int64
+	expired int64
+	// Guards closed, and sends on entries against the channel being closed.
+	mux    sync.RWMutex
+	closed bool
+	// Closed once every queued entry has been written, after the queue is
+	// closed.
+	done chan struct{}
+	now  func() time.Time
+}
+
+// asyncEntry is an entry waiting to be written, or a marker for Flush.
+type asyncEntry struct {
+	logger    *logger
+	options   *Options
+	entry     Entry
+	overrides logOptions
+	routed    io.Writer
+	// When the entry was queued.
+	queued time.Time
+	// The memory reserved for the entry.
+	size int
+	// If set, this is a marker, closed once every entry queued before it has
+	// been written.
+	flushed chan struct{}
+}
+
+func newAsyncQueue(async Async) *asyncQueue {
+	size := async.QueueSize
+	if size <= 0 {
+		size = DefaultAsyncQueueSize
+	}
+	q := &asyncQueue{
+		entries:  make(chan asyncEntry, size),
+		overflow: async.Overflow,
+		ttl:      async.TTL,
+		done:     make(chan struct{}),
+		now:      time.Now,
+	}
+	go q.run()
+	return q
+}
+
+// enqueue adds the entry to the queue, applying the overflow policy if it's
+// full. Flush markers and bypassed entries always wait for room.
+func (q *asyncQueue) enqueue(entry asyncEntry) enqueueResult {
+	q.mux.RLock()
+	defer q.mux.RUnlock()
+
+	if q.closed {
+		return closedQueue
+	}
+	if q.ttl > 0 {
+		entry.queued = q.now()
+	}
+	if entry.flushed == nil {
+		entry.size = entrySize(entry.entry)
+		if !q.reserve(entry) {
+			atomic.AddInt64(&q.dropped, 1)
+			return rejected
+		}
+	}
+	if q.overflow == OverflowBlock || entry.flushed != nil || entry.overrides.bypass {
+		q.entries <- entry
+		return enqueued
+	}
+	for {
+		select {
+		case q.entries <- entry:
+			return enqueued
+		default:
+		}
+		if q.overflow == OverflowDropNewest {
+			memory.release(entry.size)
+			atomic.AddInt64(&q.dropped, 1)
+			return rejected
+		}
+		q.evictOldest()
+	}
+}
+
+// reserve claims the memory for an entry from the budget set by SetMemoryLimit.
+// Once the budget is exhausted, OverflowDropNewest rejects the entry, and
+// OverflowDropOldest drops queued entries to make room. Otherwise, the entry
+// is queued regardless, so that the Pressure reflects the backlog.
+func (q *asyncQueue) reserve(entry asyncEntry) bool {
+	for !memory.reserve(entry.size) {
+		if q.overflow == OverflowDropNewest && !entry.overrides.bypass {
+			return false
+		}
+		if q.overflow == OverflowBlock || entry.overrides.bypass || !q.evictOldest() {
+			memory.claim(entry.size)
+			return true
+		}
+	}
+	return true
+}
+
+// evictOldest drops the oldest queued entry, reporting whether one was dropped.
+func (q *asyncQueue) evictOldest() bool {
+	select {
+	case evicted := <-q.entries:
+		if evicted.flushed != nil {
+			// Flush markers can't be dropped; requeue it behind the newer entries.
+			q.entries <- evicted
+			return false
+		}
+		memory.release(evicted.size)
+		atomic.AddInt64(&q.dropped, 1)
+		evicted.logger.dropQueued(evicted, DropQueueFull)
+		return true
+	default:
+		// The queue was drained in the meantime.
+		return false
+	}
+}
+
+// flush waits until every entry queued so far has been written.
+func (q *asyncQueue) flush() {
+	flushed := make(chan struct{})
+	if q.enqueue(asyncEntry{flushed: flushed}) == enqueued {
+		<-flushed
+	} else {
+		<-q.done
+	}
+}
+
+// close writes every queued entry, then stops the background goroutine.
+// Calling close more than once has no effect.
+func (q *asyncQueue) close() {
+	q.mux.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.entries)
+	}
+	q.mux.Unlock()
+	<-q.done
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for queued := range q.entries {
+		if queued.flushed != nil {
+			close(queued.flushed)
+			continue
+		}
+		memory.release(queued.size)
+		if q.ttl > 0 && !queued.overrides.bypass && q.now().Sub(queued.queued) > q.ttl {
+			atomic.AddInt64(&q.expired, 1)
+			queued.logger.dropQueued(queued, DropExpired)
+			continue
+		}
+		_, _ = queued.logger.write(queued.options, queued.entry, queued.overrides, queued.routed, -1)
+	}
+}
+
+// dropQueued records that a queued entry was dropped, e.g. to make room for a
+// newer one.
+func (l *logger) dropQueued(queued asyncEntry, reason DropReason) {
+	atomic.AddInt64(&l.root().stats.dropped, 1)
+	if queued.options.DropTrace == nil {
+		return
+	}
+	caller := queued.entry.Caller
+	if caller == "" {
+		caller = "unknown"
+	}
+	traceDrop(queued.options, reason, queued.entry.Level, caller, queued.entry.Message)
+}
+
+// AsyncDropped returns the number of messages dropped because the queue of
+// Options.Async was full, as configured by Async.Overflow.
+func (l *logger) AsyncDropped() int64 {
+	if queue := l.root().async; queue != nil {
+		return atomic.LoadInt64(&queue.dropped)
+	}
+	return 0
+}
+
+// AsyncExpired returns the number of messages dropped because they waited in the
+// queue of Options.Async for longer than Async.TTL.
+func (l *logger) AsyncExpired() int64 {
+	if queue := l.root().async; queue != nil {
+		return atomic.LoadInt64(&queue.expired)
+	}
+	return 0
+}
@@ -0,0 +1,180 @@
+package loggy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks each write until the gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	out  *lockedBuffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.out.Write(p)
+}
+
+func TestOptions_Async(t *testing.T) {
+	out := &gatedWriter{gate: make(chan struct{}), out: &lockedBuffer{}}
+	options := Options{
+		Out:                 out,
+		Err:                 out,
+		Threshold:           LevelInfo,
+		Async:               &Async{QueueSize: 10},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	message := []interface{}{"first"}
+	for _, text := range []string{"first", "second", "third"} {
+		message[0] = text
+		result, err := l.Emit(ctx, LevelInfo, "", message...)
+		assert.Nil(t, err)
+		assert.True(t, result.Queued)
+		assert.Nil(t, result.Destination)
+	}
+	assert.Equal(t, "", out.out.String())
+
+	close(out.gate)
+	assert.Nil(t, l.Flush())
+	assert.Equal(t, "INFO first\nINFO second\nINFO third\n", out.out.String())
+
+	assert.Nil(t, l.Close())
+	assert.Nil(t, l.Close())
+	result, err := l.Emit(ctx, LevelInfo, "after close")
+	assert.Nil(t, err)
+	assert.False(t, result.Queued)
+	assert.Equal(t, "INFO first\nINFO second\nINFO third\nINFO after close\n", out.out.String())
+	assert.Nil(t, l.Flush())
+}
+
+func TestOptions_Async_Manager(t *testing.T) {
+	out := &lockedBuffer{}
+	options := Options{
+		Out:                 out,
+		Threshold:           LevelInfo,
+		Async:               &Async{},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	m, ctx := NewManager(context.Background(), options)
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, m.Logger("db").Infof(ctx, "%d", i))
+	}
+	assert.Nil(t, m.Close())
+	assert.Equal(t, 100, strings.Count(out.String(), "\n"))
+}
+
+func TestOptions_Async_Fatal(t *testing.T) {
+	code := stubExit(t)
+	out := &lockedBuffer{}
+	options := Options{
+		Out:                 out,
+		Err:                 out,
+		Threshold:           LevelInfo,
+		Async:               &Async{},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Fatal(ctx, "goodbye"))
+	assert.Equal(t, 1, *code)
+	assert.Equal(t, "CRIT goodbye\n", out.String())
+}
+
+var asyncOverflowTestCases = []struct {
+	Name           string
+	Overflow       Overflow
+	ExpectedOutput string
+	ExpectedTrace  string
+	ExpectedResult EmitResult
+}{
+	{
+		Name:           "drop-newest",
+		Overflow:       OverflowDropNewest,
+		ExpectedOutput: "INFO loggy.TestAsync_Overflow.func1 1\nINFO loggy.TestAsync_Overflow.func1 2\nINFO loggy.TestAsync_Overflow.func1 3\n",
+		ExpectedTrace:  "DROP queue-full INFO loggy.TestAsync_Overflow.func1 \"4\"\n",
+		ExpectedResult: EmitResult{Filtered: true, Reason: DropQueueFull},
+	},
+	{
+		Name:           "drop-oldest",
+		Overflow:       OverflowDropOldest,
+		ExpectedOutput: "INFO loggy.TestAsync_Overflow.func1 1\nINFO loggy.TestAsync_Overflow.func1 3\nINFO loggy.TestAsync_Overflow.func1 4\n",
+		ExpectedTrace:  "DROP queue-full INFO loggy.TestAsync_Overflow.func1 \"2\"\n",
+		ExpectedResult: EmitResult{Queued: true},
+	},
+}
+
+func TestAsync_Overflow(t *testing.T) {
+	for _, tc := range asyncOverflowTestCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out := &gatedWriter{gate: make(chan struct{}), out: &lockedBuffer{}}
+			trace := &lockedBuffer{}
+			options := Options{
+				Out:               out,
+				Threshold:         LevelInfo,
+				Async:             &Async{QueueSize: 2, Overflow: tc.Overflow},
+				DropTrace:         trace,
+				DisableTimestamps: true,
+			}
+			l, ctx := New(context.Background(), options)
+
+			// Wait for the first message to be taken off the queue, and held by
+			// the gated writer, so that the next two fill the queue.
+			assert.Nil(t, l.Info(ctx, "1"))
+			assert.Eventually(t, func() bool { return len(l.async.entries) == 0 }, time.Second, time.Millisecond)
+			assert.Nil(t, l.Info(ctx, "2"))
+			assert.Nil(t, l.Info(ctx, "3"))
+			result, err := l.Emit(ctx, LevelInfo, "4")
+			assert.Nil(t, err)
+			assert.Equal(t, tc.ExpectedResult, result)
+
+			close(out.gate)
+			assert.Nil(t, l.Close())
+			assert.Equal(t, tc.ExpectedOutput, out.out.String())
+			assert.Equal(t, tc.ExpectedTrace, trace.String())
+			assert.Equal(t, int64(1), l.AsyncDropped())
+		})
+	}
+}
+
+func TestAsync_TTL(t *testing.T) {
+	out := &gatedWriter{gate: make(chan struct{}), out: &lockedBuffer{}}
+	trace := &lockedBuffer{}
+	options := Options{
+		Out:                 out,
+		Threshold:           LevelInfo,
+		Async:               &Async{TTL: time.Minute},
+		DropTrace:           trace,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	var now int64
+	l.async.now = func() time.Time {
+		return time.Unix(atomic.LoadInt64(&now), 0)
+	}
+
+	assert.Nil(t, l.Info(ctx, "held"))
+	assert.Eventually(t, func() bool { return len(l.async.entries) == 0 }, time.Second, time.Millisecond)
+	assert.Nil(t, l.Info(ctx, "stale"))
+	assert.Nil(t, l.Info(ctx, "bypassed", Bypass()))
+	atomic.StoreInt64(&now, 61)
+	assert.Nil(t, l.Info(ctx, "fresh"))
+
+	close(out.gate)
+	assert.Nil(t, l.Flush())
+	assert.Equal(t, "INFO held\nINFO bypassed\nINFO fresh\n", out.out.String())
+	assert.Equal(t, "DROP expired INFO unknown \"stale\"\n", trace.String())
+	assert.Equal(t, int64(1), l.AsyncExpired())
+	assert.Equal(t, int64(0), l.AsyncDropped())
+	assert.Nil(t, l.Close())
+}
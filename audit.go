@@ -0,0 +1,81 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrAuditDropped is returned when an audit event could not be written because
+// logging is disabled entirely.
+var ErrAuditDropped = errors.New("audit event was dropped")
+
+// AuditEvent records who did what, to what.
+type AuditEvent struct {
+	// Who performed the action, e.g. a user or service ID. Required.
+	Actor string
+	// What was done, e.g. "user.delete". Required.
+	Action string
+	// What the action was performed on, e.g. the ID of the deleted user. Required.
+	Target string
+	// Optional details, output as additional tags.
+	Details map[string]interface{}
+}
+
+// AuditLogger writes audit events to a dedicated stream. Unlike regular
+// messages, audit events are never filtered by threshold, delegation policies,
+// or sampling, and must identify their actor, action, and target.
+type AuditLogger struct {
+	logger Logger
+	out    io.Writer
+}
+
+// NewAuditLogger creates an AuditLogger that formats events with l, and writes
+// them to out.
+func NewAuditLogger(l Logger, out io.Writer) *AuditLogger {
+	return &AuditLogger{
+		logger: l,
+		out:    out,
+	}
+}
+
+// Audit writes the event, along with any tags in the context. The actor, action,
+// and target are output as tags of the same name, replacing any context tags.
+func (a *AuditLogger) Audit(ctx context.Context, event AuditEvent) error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"actor", event.Actor},
+		{"action", event.Action},
+		{"target", event.Target},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			return fmt.Errorf("audit event is missing the %s", field.name)
+		}
+	}
+
+	fields := make(map[string]interface{}, len(event.Details)+3)
+	for name, value := range event.Details {
+		fields[name] = value
+	}
+	fields["actor"] = event.Actor
+	fields["action"] = event.Action
+	fields["target"] = event.Target
+
+	result, err := a.logger.Emit(ctx, LevelInfo, "audit",
+		Bypass(),
+		SkipCaller(),
+		ForceDestination(a.out),
+		Fields(fields),
+	)
+	if err != nil {
+		return err
+	}
+	if result.Filtered {
+		return ErrAuditDropped
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAuditLogger_Audit(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	audit := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Threshold:         LevelCritical,
+		DisableTimestamps: true,
+		Sampling: &Sampling{
+			Initial: 1,
+			Tick:    time.Hour,
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", 7)
+	a := NewAuditLogger(l.Delegate(Delegation{Threshold: -1}), audit)
+
+	event := AuditEvent{
+		Actor:   "admin",
+		Action:  "user.delete",
+		Target:  "bob",
+		Details: map[string]interface{}{"reason": "spam", "actor": "spoofed"},
+	}
+	assert.Nil(t, a.Audit(ctx, event))
+	assert.Nil(t, a.Audit(ctx, event))
+
+	line := "INFO [action:user.delete, actor:admin, reason:spam, request:7, target:bob] audit\n"
+	assert.Equal(t, line+line, audit.String())
+	assert.Equal(t, "", stdout.String())
+}
+
+func TestAuditLogger_Audit_Invalid(t *testing.T) {
+	audit := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{Threshold: LevelInfo})
+	a := NewAuditLogger(l, audit)
+
+	err := a.Audit(ctx, AuditEvent{Actor: "admin", Action: "user.delete"})
+	assert.EqualError(t, err, "audit event is missing the target")
+	assert.Equal(t, "", audit.String())
+}
+
+func TestAuditLogger_Audit_Disabled(t *testing.T) {
+	audit := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{Threshold: -1})
+	a := NewAuditLogger(l, audit)
+
+	err := a.Audit(ctx, AuditEvent{Actor: "admin", Action: "user.delete", Target: "bob"})
+	assert.Equal(t, ErrAuditDropped, err)
+}
+
+func TestAuditLogger_Audit_DisableTags(t *testing.T) {
+	audit := bytes.NewBuffer([]byte{})
+	options := Options{
+		Threshold:         LevelCritical,
+		DisableTimestamps: true,
+		DisableTags:       true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", 7)
+	a := NewAuditLogger(l, audit)
+
+	assert.Nil(t, a.Audit(ctx, AuditEvent{Actor: "admin", Action: "user.delete", Target: "bob"}))
+	assert.Equal(t, "INFO [action:user.delete, actor:admin, target:bob] audit\n", audit.String())
+}
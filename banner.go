@@ -0,0 +1,78 @@
+package loggy
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// BannerEnv lists the environment variables included in the startup banner,
+// when they're set. Append to it to include application settings.
+var BannerEnv = []string{"GOMAXPROCS", "GOGC", "GODEBUG", "GOTRACEBACK", "TZ"}
+
+// BannerRedactedEnv lists the case-insensitive substrings that mark an
+// environment variable as sensitive. The banner includes that variable as
+// Redacted, rather than its value.
+var BannerRedactedEnv = []string{"KEY", "SECRET", "TOKEN", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// Redacted replaces the values of sensitive environment variables in the banner.
+const Redacted = "<redacted>"
+
+// Banner sends a single standard message describing the application, its build,
+// the effective logging configuration, the host, and the environment variables
+// in BannerEnv. The message bypasses all filtering. Call it before logging
+// anything else, so that operators can rely on it appearing first.
+func (l *logger) Banner(ctx context.Context, appName, version string) error {
+	options := l.currentOptions()
+
+	fields := map[string]interface{}{
+		"app.name":      appName,
+		"app.version":   version,
+		"build.go":      runtime.Version(),
+		"host.os":       runtime.GOOS + "/" + runtime.GOARCH,
+		"host.cpus":     runtime.NumCPU(),
+		"host.pid":      os.Getpid(),
+		"log.threshold": describeLevel(options.Threshold),
+		"log.sampling":  options.Sampling != nil,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path != "" {
+		fields["build.path"] = info.Main.Path
+		fields["build.version"] = info.Main.Version
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		fields["host.name"] = hostname
+	}
+	if name := l.fullName(options); name != "" {
+		fields["log.name"] = name
+	}
+	if on, _ := l.IncidentMode(); on {
+		fields["log.incident"] = true
+	}
+	for _, name := range BannerEnv {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if isSensitiveEnv(name) {
+			value = Redacted
+		}
+		fields["env."+name] = value
+	}
+
+	_, err := l.emit(ctx, 2, LevelStd, "starting %s %s", appName, version, Bypass(), Fields(fields))
+	return err
+}
+
+// isSensitiveEnv reports whether the environment variable's name matches any of
+// BannerRedactedEnv.
+func isSensitiveEnv(name string) bool {
+	name = strings.ToUpper(name)
+	for _, marker := range BannerRedactedEnv {
+		if strings.Contains(name, strings.ToUpper(marker)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestLogger_Banner(t *testing.T) {
+	env := BannerEnv
+	BannerEnv = []string{"LOGGY_TEST_REGION", "LOGGY_TEST_API_TOKEN", "LOGGY_TEST_UNSET"}
+	assert.Nil(t, os.Setenv("LOGGY_TEST_REGION", "us-east-1"))
+	assert.Nil(t, os.Setenv("LOGGY_TEST_API_TOKEN", "hunter2"))
+	t.Cleanup(func() {
+		BannerEnv = env
+		_ = os.Unsetenv("LOGGY_TEST_REGION")
+		_ = os.Unsetenv("LOGGY_TEST_API_TOKEN")
+	})
+
+	var entry Entry
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Name:                "api",
+		Threshold:           LevelCritical,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		DisableTags:         true,
+		Processors: []Processor{
+			func(ctx context.Context, e *Entry) bool {
+				entry = *e
+				return true
+			},
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	assert.Nil(t, l.Banner(ctx, "shop", "1.2.3"))
+
+	// DisableTags doesn't hide the banner's fields.
+	assert.Contains(t, stdout.String(), "app.name:shop, app.version:1.2.3")
+	assert.Contains(t, stdout.String(), "] starting shop 1.2.3\n")
+	assert.Equal(t, "shop", entry.Fields["app.name"])
+	assert.Equal(t, "1.2.3", entry.Fields["app.version"])
+	assert.Equal(t, runtime.Version(), entry.Fields["build.go"])
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, entry.Fields["host.os"])
+	assert.Equal(t, os.Getpid(), entry.Fields["host.pid"])
+	assert.Equal(t, "CRIT", entry.Fields["log.threshold"])
+	assert.Equal(t, "api", entry.Fields["log.name"])
+	assert.Equal(t, "us-east-1", entry.Fields["env.LOGGY_TEST_REGION"])
+	assert.Equal(t, Redacted, entry.Fields["env.LOGGY_TEST_API_TOKEN"])
+	assert.NotContains(t, entry.Fields, "env.LOGGY_TEST_UNSET")
+}
+
+func TestIsSensitiveEnv(t *testing.T) {
+	assert.True(t, isSensitiveEnv("AWS_SECRET_ACCESS_KEY"))
+	assert.True(t, isSensitiveEnv("github_token"))
+	assert.True(t, isSensitiveEnv("DB_PASSWORD"))
+	assert.False(t, isSensitiveEnv("AWS_REGION"))
+	assert.False(t, isSensitiveEnv("GOMAXPROCS"))
+}
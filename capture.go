@@ -0,0 +1,98 @@
+package loggy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// CaptureStdLog redirects the output of the standard library's default logger,
+// as used by log.Print and friends, through l at the given severity, so that
+// messages from dependencies are timestamped and routed like any other. The
+// messages are tagged with "source:stdlog". The returned function restores the
+// default logger's previous output and flags.
+func CaptureStdLog(ctx context.Context, l Logger, severity Level) (restore func()) {
+	out := log.Writer()
+	flags := log.Flags()
+
+	// loggy adds its own timestamp.
+	log.SetFlags(0)
+	log.SetOutput(&captureWriter{
+		ctx:      ctx,
+		l:        l,
+		severity: severity,
+		source:   "stdlog",
+	})
+
+	return func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	}
+}
+
+// CaptureStderr replaces os.Stderr with a pipe, and logs each line written to it
+// through l at the given severity, tagged with "source:stderr". Only writes made
+// via the os.Stderr variable are captured; output written directly to file
+// descriptor 2, such as the runtime's panic messages, is not. The logger must
+// not be writing to the os.Stderr that's being replaced, which is the case for
+// loggers created before the call. The returned function restores os.Stderr,
+// once every captured line has been logged.
+func CaptureStderr(ctx context.Context, l Logger, severity Level) (restore func() error, err error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer := &captureWriter{
+			ctx:      ctx,
+			l:        l,
+			severity: severity,
+			source:   "stderr",
+		}
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			_, _ = writer.Write(scanner.Bytes())
+		}
+	}()
+
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			os.Stderr = stderr
+			err = w.Close()
+			<-done
+			if closeErr := r.Close(); err == nil {
+				err = closeErr
+			}
+		})
+		return err
+	}, nil
+}
+
+// captureWriter logs each write as a message.
+type captureWriter struct {
+	ctx      context.Context
+	l        Logger
+	severity Level
+	source   string
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	text := string(bytes.TrimRight(p, "\r\n"))
+	err := w.l.Logf(w.ctx, w.severity, "%s", text, SkipCaller(), Fields(map[string]interface{}{
+		"source": w.source,
+	}))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,50 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestCaptureStdLog(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+	flags := log.Flags()
+
+	restore := CaptureStdLog(ctx, l, LevelInfo)
+	log.Printf("dependency says %s", "hi")
+	restore()
+
+	assert.Equal(t, "INFO [source:stdlog] dependency says hi\n", stdout.String())
+	assert.Equal(t, flags, log.Flags())
+}
+
+func TestCaptureStderr(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+	stderr := os.Stderr
+
+	restore, err := CaptureStderr(ctx, l, LevelInfo)
+	assert.Nil(t, err)
+	fmt.Fprintln(os.Stderr, "stray print")
+	fmt.Fprint(os.Stderr, "unterminated")
+	assert.Nil(t, restore())
+	assert.Nil(t, restore())
+
+	assert.Same(t, stderr, os.Stderr)
+	assert.Equal(t, "INFO [source:stderr] stray print\nINFO [source:stderr] unterminated\n", stdout.String())
+}
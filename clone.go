@@ -0,0 +1,56 @@
+package loggy
+
+import (
+	"context"
+	"reflect"
+)
+
+// Clone creates an independent logger with the same options as this one,
+// except for those set in overrides, e.g. Options{Out: file, Prefix: "job"}.
+// Only the fields of overrides that aren't zero values are applied, so a field
+// can't be reset to its zero value this way; build a new Options for New
+// instead. The clone keeps this logger's name, permanent tags, delegation
+// policies, and hooks, but has its own options from then on, so changes such as
+// SetOutput or AddHook don't carry between the two. Named loggers keep their
+// names within the root's, so Options.Thresholds apply to the clone as they do
+// to this logger. While incident mode is on, the clone starts from the options
+// in effect before it was switched on.
+func (l *logger) Clone(overrides Options) Logger {
+	root := l.root()
+	root.optionsMux.RLock()
+	options := *root.options
+	if root.incidentBase != nil {
+		options = *root.incidentBase
+	}
+	root.optionsMux.RUnlock()
+
+	// The profile was already applied when this logger was created.
+	options.Profile = nil
+	value := reflect.ValueOf(&options).Elem()
+	override := reflect.ValueOf(overrides)
+	for i := 0; i < override.NumField(); i++ {
+		if !override.Field(i).IsZero() {
+			value.Field(i).Set(override.Field(i))
+		}
+	}
+
+	clone, _ := New(context.Background(), options)
+	clone.tags = root.tags
+	clone.hooks = root.hooks.copy()
+
+	// Derive the clone as this logger was derived from its root, keeping each
+	// name, delegation policy, and set of tags in its place.
+	var chain []*logger
+	for d := l; d != root; d = d.parent {
+		chain = append(chain, d)
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		clone = &logger{
+			parent:     clone,
+			name:       chain[i].name,
+			delegation: chain[i].delegation,
+			tags:       chain[i].tags,
+		}
+	}
+	return clone
+}
@@ -0,0 +1,71 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLogger_Clone(t *testing.T) {
+	original := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:                "app",
+		Out:                 original,
+		Threshold:           LevelDebug,
+		Prefix:              "~~~",
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	db := l.Named("db").With(map[string]interface{}{"component": "db"})
+
+	cloned := bytes.NewBuffer([]byte{})
+	clone := db.Clone(Options{Out: cloned, Prefix: "job"})
+	assert.Nil(t, clone.Debug(ctx, "cloned"))
+	assert.Nil(t, db.Debug(ctx, "original"))
+
+	clone.SetThreshold(LevelInfo)
+	assert.Nil(t, clone.Debug(ctx, "hidden"))
+	assert.Equal(t, LevelDebug, db.GetThreshold())
+
+	assert.Equal(t, "DEBUG app.db [component:db] job cloned\n", cloned.String())
+	assert.Equal(t, "DEBUG app.db [component:db] ~~~ original\n", original.String())
+}
+
+func TestLogger_Clone_Delegation(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 buf,
+		Err:                 buf,
+		Threshold:           LevelDebug,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	library := l.Delegate(Delegation{Threshold: LevelWarning})
+
+	clone := library.Clone(Options{Prefix: "lib"})
+	assert.Nil(t, clone.Info(ctx, "hidden"))
+	assert.Nil(t, clone.Warning(ctx, "shown"))
+	assert.Equal(t, "WARN lib shown\n", buf.String())
+}
+
+func TestLogger_Clone_Thresholds(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:                "app",
+		Out:                 buf,
+		Threshold:           LevelInfo,
+		Thresholds:          map[string]Level{"db.*": LevelDebug},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	db := l.Named("db").Named("pool")
+
+	clone := db.Clone(Options{Prefix: "job"})
+	assert.Equal(t, LevelDebug, clone.GetThreshold())
+	assert.Nil(t, clone.Debug(ctx, "shown"))
+	assert.Equal(t, "DEBUG app.db.pool job shown\n", buf.String())
+}
@@ -0,0 +1,101 @@
+package loggy
+
+import (
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// Flush writes any messages queued by Options.Async, then commits any data
+// buffered by the logger's streams, e.g. a bufio.Writer, CompressWriter, or
+// FileWriter, returning the first error encountered. Derived loggers share
+// their parent's streams, so this flushes the whole family of loggers.
+func (l *logger) Flush() error {
+	root := l.root()
+	if queue := root.async; queue != nil {
+		queue.flush()
+	}
+
+	var first error
+	for _, w := range root.sinks() {
+		if err := flushWriter(w); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close shuts down the logger and every logger related to it, for example at
+// the end of main. If Options.ShutdownSummary is set, the summary is logged
+// first. Then any queued messages are written, and the streams are flushed and
+// closed if they implement io.Closer, returning the first error encountered.
+// The process's stdout and stderr are never closed. Messages logged afterwards
+// are written synchronously, if their stream still accepts them. Calling Close
+// more than once has no effect.
+func (l *logger) Close() error {
+	root := l.root()
+	if !atomic.CompareAndSwapInt32(&root.closed, 0, 1) {
+		return nil
+	}
+
+	var first error
+	if root.currentOptions().ShutdownSummary {
+		first = root.summarize(context.Background())
+	}
+	if queue := root.async; queue != nil {
+		queue.close()
+	}
+	if err := root.Flush(); err != nil && first == nil {
+		first = err
+	}
+	for _, w := range root.sinks() {
+		if closer, ok := w.(io.Closer); ok {
+			if err := closer.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	if recent := root.recentErrors; recent != nil {
+		recent.release()
+	}
+	return first
+}
+
+// sinks returns each of the logger's streams once, excluding the process's
+// stdout and stderr, which are unbuffered.
+func (l *logger) sinks() []io.Writer {
+	options := l.currentOptions()
+	writers := []io.Writer{options.Out, options.Err}
+	names := make([]string, 0, len(options.Destinations))
+	for name := range options.Destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writers = append(writers, options.Destinations[name])
+	}
+	for level := LevelStd; level <= LevelTrace; level++ {
+		writers = append(writers, options.LevelOutputs[level])
+	}
+
+	seen := make(map[io.Writer]bool, len(writers))
+	sinks := make([]io.Writer, 0, len(writers))
+	for _, w := range writers {
+		if w == nil || w == os.Stdout || w == os.Stderr {
+			continue
+		}
+		// Writers that can't be compared, such as func adapters, can't be
+		// deduplicated.
+		if reflect.TypeOf(w).Comparable() {
+			if seen[w] {
+				continue
+			}
+			seen[w] = true
+		}
+		sinks = append(sinks, w)
+	}
+	return sinks
+}
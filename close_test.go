@@ -0,0 +1,92 @@
+package loggy
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogger_Flush(t *testing.T) {
+	sink := &closeRecorder{}
+	options := Options{
+		Out:                 bufio.NewWriter(sink),
+		Err:                 os.Stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Async:               &Async{},
+	}
+	l, ctx := New(context.Background(), options)
+	defer l.Close()
+
+	// Flushing a derived logger flushes the streams shared with its parent.
+	db := l.Named("db")
+	assert.Nil(t, db.Info(ctx, "buffered"))
+	assert.Equal(t, "", sink.String())
+
+	assert.Nil(t, db.Flush())
+	assert.Equal(t, "INFO db buffered\n", sink.String())
+	assert.Equal(t, 0, sink.closed)
+}
+
+func TestLogger_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := NewFileWriter(path, FileOptions{})
+	assert.Nil(t, err)
+	audit := &closeRecorder{}
+	options := Options{
+		Out:                 file,
+		Err:                 os.Stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		LevelOutputs:        map[Level]io.Writer{LevelCritical: audit},
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.With(map[string]interface{}{"id": 1}).Info(ctx, "started"))
+	assert.Nil(t, l.Named("api").Close())
+	assert.Equal(t, 1, audit.closed)
+	assert.Equal(t, "INFO [id:1] started\n", readFile(t, path))
+
+	// Closing again, via any related logger, has no effect.
+	assert.Nil(t, l.Close())
+	assert.Equal(t, 1, audit.closed)
+
+	// The file no longer accepts messages.
+	assert.ErrorIs(t, l.Info(ctx, "stopped"), ErrClosed)
+}
+
+// writerFunc is a writer whose type can't be compared.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestLogger_Flush_Uncomparable(t *testing.T) {
+	var lines []string
+	out := writerFunc(func(p []byte) (int, error) {
+		lines = append(lines, string(p))
+		return len(p), nil
+	})
+	options := Options{
+		Out:                 out,
+		Err:                 out,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "hello"))
+	assert.NotPanics(t, func() {
+		assert.Nil(t, l.Flush())
+		assert.Nil(t, l.Close())
+	})
+	assert.Equal(t, []string{"INFO hello\n"}, lines)
+}
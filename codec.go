@@ -0,0 +1,97 @@
+package loggy
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+)
+
+// Codec creates Compressors of a particular format, so that each destination can
+// pick its own trade-off between size and latency. Codecs for formats outside
+// the standard library, such as zstd or snappy, can be provided by wrapping
+// their encoders, which generally implement Compressor already.
+type Codec interface {
+	// Name identifies the format, e.g. "gzip", for use in HTTP Content-Encoding
+	// headers or file extensions.
+	Name() string
+	// NewCompressor creates a Compressor that writes compressed data to w.
+	NewCompressor(w io.Writer) (Compressor, error)
+}
+
+// GzipCodec compresses with gzip. The zero value uses gzip.DefaultCompression;
+// use NewGzipCodec for another level.
+type GzipCodec struct {
+	level int
+	// Whether level was set, since gzip.NoCompression is zero.
+	leveled bool
+}
+
+// NewGzipCodec creates a GzipCodec that compresses at the level, e.g.
+// gzip.BestSpeed, or gzip.NoCompression.
+func NewGzipCodec(level int) GzipCodec {
+	return GzipCodec{level: level, leveled: true}
+}
+
+// Name returns "gzip".
+func (c GzipCodec) Name() string {
+	return "gzip"
+}
+
+// NewCompressor creates a gzip.Writer for w.
+func (c GzipCodec) NewCompressor(w io.Writer) (Compressor, error) {
+	if !c.leveled {
+		return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+	}
+	return gzip.NewWriterLevel(w, c.level)
+}
+
+// IdentityCodec passes data through uncompressed, for low-latency destinations.
+type IdentityCodec struct{}
+
+// Name returns "identity".
+func (c IdentityCodec) Name() string {
+	return "identity"
+}
+
+// NewCompressor creates a Compressor that writes data to w unchanged.
+func (c IdentityCodec) NewCompressor(w io.Writer) (Compressor, error) {
+	return identityCompressor{w}, nil
+}
+
+type identityCompressor struct {
+	io.Writer
+}
+
+func (c identityCompressor) Flush() error {
+	return flushWriter(c.Writer)
+}
+
+func (c identityCompressor) Close() error {
+	return nil
+}
+
+// NewCodecWriter creates a CompressWriter that compresses everything written to
+// out with the codec, flushing every interval.
+func NewCodecWriter(out io.Writer, codec Codec, interval time.Duration) (*CompressWriter, error) {
+	compressor, err := codec.NewCompressor(out)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompressWriter(compressor, interval), nil
+}
+
+// codecExtension returns the extension for files compressed by the codec, or ""
+// if the codec doesn't compress.
+func codecExtension(codec Codec) string {
+	if codec == nil {
+		return ""
+	}
+	switch name := codec.Name(); name {
+	case "identity":
+		return ""
+	case "gzip":
+		return ".gz"
+	default:
+		return "." + name
+	}
+}
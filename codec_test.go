@@ -0,0 +1,89 @@
+package loggy
+
+import (
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var codecTestCases = []struct {
+	Name         string
+	Codec        Codec
+	ExpectedName string
+	Extension    string
+	Compressed   bool
+}{
+	{
+		Name:         "gzip",
+		Codec:        GzipCodec{},
+		ExpectedName: "gzip",
+		Extension:    ".gz",
+		Compressed:   true,
+	},
+	{
+		Name:         "gzip-best-speed",
+		Codec:        NewGzipCodec(gzip.BestSpeed),
+		ExpectedName: "gzip",
+		Extension:    ".gz",
+		Compressed:   true,
+	},
+	{
+		Name:         "gzip-no-compression",
+		Codec:        NewGzipCodec(gzip.NoCompression),
+		ExpectedName: "gzip",
+		Extension:    ".gz",
+		Compressed:   true,
+	},
+	{
+		Name:         "identity",
+		Codec:        IdentityCodec{},
+		ExpectedName: "identity",
+		Compressed:   false,
+	},
+}
+
+func TestCodec(t *testing.T) {
+	for _, testCase := range codecTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.ExpectedName, testCase.Codec.Name())
+			assert.Equal(t, testCase.Extension, codecExtension(testCase.Codec))
+
+			out := &lockedBuffer{}
+			w, err := NewCodecWriter(out, testCase.Codec, 0)
+			assert.Nil(t, err)
+			_, err = w.Write([]byte("INFO hello\n"))
+			assert.Nil(t, err)
+			assert.Nil(t, w.Flush())
+			assert.Nil(t, w.Close())
+
+			if testCase.Compressed {
+				assert.Equal(t, "INFO hello\n", gunzip(t, out.Bytes()))
+			} else {
+				assert.Equal(t, "INFO hello\n", out.String())
+			}
+		})
+	}
+}
+
+func TestGzipCodec_InvalidLevel(t *testing.T) {
+	_, err := NewCodecWriter(&lockedBuffer{}, NewGzipCodec(42), 0)
+	assert.NotNil(t, err)
+}
+
+func TestGzipCodec_NoCompression(t *testing.T) {
+	data := []byte(strings.Repeat("INFO hello\n", 100))
+	sizes := map[string]int{}
+	for name, codec := range map[string]Codec{"default": GzipCodec{}, "none": NewGzipCodec(gzip.NoCompression)} {
+		out := &lockedBuffer{}
+		w, err := NewCodecWriter(out, codec, 0)
+		assert.Nil(t, err)
+		_, err = w.Write(data)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+		assert.Equal(t, string(data), gunzip(t, out.Bytes()))
+		sizes[name] = out.Len()
+	}
+	assert.Greater(t, sizes["none"], len(data))
+	assert.Less(t, sizes["default"], len(data))
+}
@@ -0,0 +1,28 @@
+package loggy
+
+const colorReset = "\x1b[0m"
+
+// LevelColors are the ANSI escape codes used to color each Level* label, when
+// Options.Color is enabled.
+var LevelColors = map[Level]string{
+	LevelCritical: "\x1b[1;31m",
+	LevelDebug:    "\x1b[36m",
+	LevelError:    "\x1b[31m",
+	LevelInfo:     "\x1b[32m",
+	LevelWarning:  "\x1b[33m",
+	LevelStd:      "",
+	LevelTrace:    "\x1b[90m",
+}
+
+// levelLabel returns the label for the severity, colored if enabled.
+func levelLabel(options *Options, severity Level) string {
+	label := LevelNames[severity]
+	if !options.Color {
+		return label
+	}
+	color, ok := LevelColors[severity]
+	if !ok || color == "" {
+		return label
+	}
+	return coloredLabel(color, label)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package loggy
+
+import (
+	"io"
+)
+
+// enableColor reports whether ANSI escape codes can be written to w. Terminals
+// on non-Windows platforms process them natively.
+func enableColor(w io.Writer) bool {
+	return true
+}
@@ -0,0 +1,29 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOptions_Color(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Color:               true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Std(ctx, "plain"))
+	assert.Nil(t, l.Info(ctx, "green"))
+	assert.Nil(t, l.Critical(ctx, "red"))
+
+	assert.Equal(t, "OUT plain\n\x1b[32mINFO\x1b[0m green\n", stdout.String())
+	assert.Equal(t, "\x1b[1;31mCRIT\x1b[0m red\n", stderr.String())
+}
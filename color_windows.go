@@ -0,0 +1,33 @@
+package loggy
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableColor turns on ANSI escape code processing for Windows consoles, which
+// is off by default for cmd and PowerShell. It returns false if the writer is a
+// console that can't process escape codes, e.g. on Windows versions before 10.
+func enableColor(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return true
+	}
+	handle := syscall.Handle(f.Fd())
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(handle, &mode); err != nil {
+		// Not a console, e.g. redirected to a file or pipe.
+		return true
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(handle), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
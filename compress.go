@@ -0,0 +1,104 @@
+package loggy
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when writing to a writer that has been closed.
+var ErrClosed = errors.New("writer is closed")
+
+var _ io.WriteCloser = &CompressWriter{}
+
+// Compressor is a streaming compressor, such as *gzip.Writer. Compressors from
+// other packages (e.g. zstd encoders) generally implement the same methods.
+type Compressor interface {
+	io.Writer
+	// Flush writes any pending compressed data to the underlying writer.
+	Flush() error
+	// Close flushes any pending data and writes the stream's footer, without
+	// closing the underlying writer.
+	Close() error
+}
+
+// CompressWriter compresses everything written to it, flushing on an interval so
+// that compressed logs reach their destination while the program is running,
+// rather than only when the writer is closed. It is safe for concurrent use.
+type CompressWriter struct {
+	compressor Compressor
+	mux        sync.Mutex
+	closed     bool
+	done       chan struct{}
+}
+
+// NewCompressWriter wraps the compressor, flushing it every interval. An interval
+// <= 0 disables periodic flushing.
+func NewCompressWriter(compressor Compressor, interval time.Duration) *CompressWriter {
+	w := &CompressWriter{
+		compressor: compressor,
+		done:       make(chan struct{}),
+	}
+	if interval > 0 {
+		go w.flushEvery(interval)
+	}
+	return w
+}
+
+// NewGzipWriter creates a CompressWriter that gzips everything written to out.
+// See NewCodecWriter for other formats and compression levels.
+func NewGzipWriter(out io.Writer, interval time.Duration) *CompressWriter {
+	return NewCompressWriter(gzip.NewWriter(out), interval)
+}
+
+func (w *CompressWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	return w.compressor.Write(p)
+}
+
+// Flush writes any pending compressed data to the underlying writer.
+func (w *CompressWriter) Flush() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	return w.compressor.Flush()
+}
+
+// Close stops periodic flushing and completes the compressed stream. The
+// underlying writer is not closed. Calling Close more than once has no effect.
+func (w *CompressWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+
+	return w.compressor.Close()
+}
+
+func (w *CompressWriter) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Flush()
+		case <-w.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package loggy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mux sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Len() int {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Len()
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return append([]byte{}, b.buf.Bytes()...)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func gunzip(t *testing.T, p []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(p))
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	decompressed, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	return string(decompressed)
+}
+
+func TestCompressWriter(t *testing.T) {
+	out := &lockedBuffer{}
+	w := NewGzipWriter(out, 0)
+	options := Options{
+		Out:                 w,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "squeeze"))
+	assert.Nil(t, l.Info(ctx, "squash"))
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, "INFO squeeze\nINFO squash\n", gunzip(t, out.Bytes()))
+
+	_, err := w.Write([]byte("late"))
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, l.Info(ctx, "late"))
+}
+
+func TestCompressWriter_PeriodicFlush(t *testing.T) {
+	out := &lockedBuffer{}
+	w := NewGzipWriter(out, 10*time.Millisecond)
+	defer w.Close()
+
+	_, err := w.Write([]byte("flush me"))
+	assert.Nil(t, err)
+
+	// Nothing beyond the gzip header is written until the compressor flushes.
+	assert.Eventually(t, func() bool {
+		return out.Len() > 10
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package loggy
+
+import (
+	"io"
+	"sync"
+)
+
+// clearLine returns the cursor to the start of the line and erases the line.
+const clearLine = "\r\x1b[K"
+
+var _ io.Writer = &ConsoleWriter{}
+
+// StatusLine is implemented by interactive elements, such as progress bars and
+// spinners, that draw on the terminal's last line. See ConsoleWriter.
+type StatusLine interface {
+	// Clear erases the status line, leaving the cursor at the start of the line.
+	Clear(w io.Writer) error
+	// Draw draws the status line, without a trailing newline.
+	Draw(w io.Writer) error
+}
+
+// Status is a StatusLine that draws fixed text, e.g. "uploading 3/10".
+type Status string
+
+// Clear implements StatusLine.
+func (s Status) Clear(w io.Writer) error {
+	_, err := io.WriteString(w, clearLine)
+	return err
+}
+
+// Draw implements StatusLine.
+func (s Status) Draw(w io.Writer) error {
+	_, err := io.WriteString(w, string(s))
+	return err
+}
+
+// ConsoleWriter writes messages to a terminal that also shows a status line,
+// clearing the status line before each message and drawing it again after, so
+// that messages scroll by above it rather than clobbering it. It is safe for
+// concurrent use. Status lines are only drawn if the terminal supports ANSI
+// escape codes; otherwise messages are written as they are.
+type ConsoleWriter struct {
+	out     io.Writer
+	enabled bool
+	mux     sync.Mutex
+	status  StatusLine
+}
+
+// NewConsoleWriter wraps out, typically os.Stdout or os.Stderr.
+func NewConsoleWriter(out io.Writer) *ConsoleWriter {
+	return &ConsoleWriter{
+		out:     out,
+		enabled: enableColor(out),
+	}
+}
+
+// SetStatusLine replaces the status line, clearing the current one and drawing
+// the new one. Call it again to redraw an element that has changed, e.g. a
+// progress bar that has advanced. A nil status line removes it.
+func (w *ConsoleWriter) SetStatusLine(status StatusLine) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if !w.enabled {
+		return nil
+	}
+	if w.status != nil {
+		if err := w.status.Clear(w.out); err != nil {
+			return err
+		}
+	}
+	w.status = status
+	if status != nil {
+		return status.Draw(w.out)
+	}
+	return nil
+}
+
+// SetStatus replaces the status line with fixed text. An empty status removes
+// it.
+func (w *ConsoleWriter) SetStatus(status string) error {
+	if status == "" {
+		return w.SetStatusLine(nil)
+	}
+	return w.SetStatusLine(Status(status))
+}
+
+func (w *ConsoleWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.status == nil {
+		return w.out.Write(p)
+	}
+	if err := w.status.Clear(w.out); err != nil {
+		return 0, err
+	}
+	n, err := w.out.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.status.Draw(w.out)
+}
@@ -0,0 +1,64 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+// spinner is a StatusLine that advances each time it's drawn.
+type spinner struct {
+	frames string
+	next   int
+}
+
+func (s *spinner) Clear(w io.Writer) error {
+	_, err := io.WriteString(w, "\r")
+	return err
+}
+
+func (s *spinner) Draw(w io.Writer) error {
+	frame := s.frames[s.next%len(s.frames)]
+	s.next++
+	_, err := w.Write([]byte{frame})
+	return err
+}
+
+func TestConsoleWriter(t *testing.T) {
+	terminal := bytes.NewBuffer([]byte{})
+	console := NewConsoleWriter(terminal)
+	options := Options{
+		Out:                 console,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "before"))
+	assert.Nil(t, console.SetStatus("uploading 1/2"))
+	assert.Nil(t, l.Info(ctx, "uploaded a"))
+	assert.Nil(t, console.SetStatus("uploading 2/2"))
+	assert.Nil(t, console.SetStatus(""))
+	assert.Nil(t, l.Info(ctx, "after"))
+
+	assert.Equal(t, "INFO before\n"+
+		"uploading 1/2"+
+		clearLine+"INFO uploaded a\nuploading 1/2"+
+		clearLine+"uploading 2/2"+
+		clearLine+"INFO after\n", terminal.String())
+}
+
+func TestConsoleWriter_SetStatusLine(t *testing.T) {
+	terminal := bytes.NewBuffer([]byte{})
+	console := NewConsoleWriter(terminal)
+
+	assert.Nil(t, console.SetStatusLine(&spinner{frames: `|/-\`}))
+	_, err := console.Write([]byte("working\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, console.SetStatusLine(nil))
+
+	assert.Equal(t, "|\rworking\n/\r", terminal.String())
+}
@@ -0,0 +1,50 @@
+package loggy
+
+import (
+	"context"
+	"fmt"
+)
+
+// Consume runs handler for a single message consumed from a queue, with a
+// context tagged with the message's topic and ID, as "mq.topic" and "mq.id".
+// Once the handler returns, the outcome and processing duration are logged:
+// successes at LevelInfo, errors at LevelError, and panics at LevelCritical,
+// after which the panic resumes. The handler's error is returned.
+//
+//	for msg := range deliveries {
+//		err := loggy.Consume(ctx, l, msg.Topic, msg.ID, func(ctx context.Context) error {
+//			return process(ctx, msg)
+//		})
+//	}
+func Consume(ctx context.Context, l Logger, topic, id string, handler func(ctx context.Context) error) (err error) {
+	_, ctx = l.AddTag(ctx, "mq.topic", topic)
+	_, ctx = l.AddTag(ctx, "mq.id", id)
+
+	start := l.Options().TimestampFunc()
+	fields := func(outcome string) map[string]interface{} {
+		return map[string]interface{}{
+			"mq.outcome":  outcome,
+			"mq.duration": l.Options().TimestampFunc().Sub(start),
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			f := fields("panic")
+			f["panic"] = fmt.Sprint(r)
+			_ = l.Logf(ctx, LevelCritical, "message handler panicked", Fields(f))
+			panic(r)
+		}
+	}()
+
+	err = handler(ctx)
+	if err != nil {
+		f := fields("error")
+		f["error"] = err
+		_ = l.Logf(ctx, LevelError, "message failed", Fields(f))
+		return err
+	}
+	_ = l.Logf(ctx, LevelInfo, "message processed", Fields(fields("ok")))
+
+	return nil
+}
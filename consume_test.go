@@ -0,0 +1,75 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newConsumeTestLogger(now *time.Time) (*logger, context.Context, *bytes.Buffer) {
+	out := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 out,
+		Err:                 out,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		TimestampFunc: func() time.Time {
+			return *now
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	return l, ctx, out
+}
+
+func TestConsume(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+
+	err := Consume(ctx, l, "orders", "msg-1", func(ctx context.Context) error {
+		assert.Equal(t, "orders", l.Tag(ctx, "mq.topic"))
+		assert.Equal(t, "msg-1", l.Tag(ctx, "mq.id"))
+		assert.Nil(t, l.Info(ctx, "handling"))
+		now = now.Add(25 * time.Millisecond)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"INFO [mq.id:msg-1, mq.topic:orders] handling\n"+
+			"INFO [mq.duration:25ms, mq.id:msg-1, mq.outcome:ok, mq.topic:orders] message processed\n",
+		out.String(),
+	)
+}
+
+func TestConsume_Error(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+	failure := errors.New("out of stock")
+
+	err := Consume(ctx, l, "orders", "msg-2", func(ctx context.Context) error {
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t,
+		"ERROR [error:out of stock (*errors.errorString), mq.duration:0s, mq.id:msg-2, mq.outcome:error, mq.topic:orders] message failed\n",
+		out.String(),
+	)
+}
+
+func TestConsume_Panic(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = Consume(ctx, l, "orders", "msg-3", func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t,
+		"CRIT [mq.duration:0s, mq.id:msg-3, mq.outcome:panic, mq.topic:orders, panic:boom] message handler panicked\n",
+		out.String(),
+	)
+}
@@ -0,0 +1,141 @@
+package loggy
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	defaultMux    sync.RWMutex
+	defaultLogger Logger
+)
+
+// Default returns the logger used by the package-level logging functions, e.g.
+// loggy.Info. Unless replaced by SetDefault, it's created on first use from
+// DefaultOptions.
+func Default() Logger {
+	defaultMux.RLock()
+	l := defaultLogger
+	defaultMux.RUnlock()
+	if l != nil {
+		return l
+	}
+
+	defaultMux.Lock()
+	defer defaultMux.Unlock()
+
+	if defaultLogger == nil {
+		defaultLogger, _ = NewWith(context.Background())
+	}
+	return defaultLogger
+}
+
+// SetDefault replaces the logger used by the package-level logging functions,
+// e.g. with one writing to a buffer in tests. A nil logger restores a logger
+// created from DefaultOptions.
+func SetDefault(l Logger) {
+	defaultMux.Lock()
+	defer defaultMux.Unlock()
+
+	defaultLogger = l
+}
+
+// logDefault implements the package-level logging functions. The caller is
+// resolved to the function calling them, rather than to this package, unless
+// the default logger was replaced with another implementation of Logger.
+func logDefault(ctx context.Context, severity Level, format string, message ...interface{}) error {
+	l := Default()
+	if impl, ok := l.(*logger); ok {
+		_, err := impl.emit(ctx, 3, severity, format, message...)
+		return err
+	}
+	return l.Logf(ctx, severity, format, message...)
+}
+
+// Log sends a message with the provided severity via the Default logger.
+func Log(ctx context.Context, severity Level, message ...interface{}) error {
+	return logDefault(ctx, severity, "", message...)
+}
+
+// Logf sends a message with the provided severity, with a custom string
+// format, via the Default logger.
+func Logf(ctx context.Context, severity Level, format string, message ...interface{}) error {
+	return logDefault(ctx, severity, format, message...)
+}
+
+// Std sends a standard log message via the Default logger.
+func Std(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelStd, "", message...)
+}
+
+// Stdf sends a standard log message, with a custom string format, via the
+// Default logger.
+func Stdf(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelStd, format, message...)
+}
+
+// Critical sends a critical error message via the Default logger.
+func Critical(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelCritical, "", message...)
+}
+
+// Criticalf sends a critical error message, with a custom string format, via
+// the Default logger.
+func Criticalf(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelCritical, format, message...)
+}
+
+// Error sends an error message via the Default logger.
+func Error(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelError, "", message...)
+}
+
+// Errorf sends an error message, with a custom string format, via the Default
+// logger.
+func Errorf(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelError, format, message...)
+}
+
+// Warning sends a warning message via the Default logger.
+func Warning(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelWarning, "", message...)
+}
+
+// Warningf sends a warning message, with a custom string format, via the
+// Default logger.
+func Warningf(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelWarning, format, message...)
+}
+
+// Info sends an info log message via the Default logger.
+func Info(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelInfo, "", message...)
+}
+
+// Infof sends an info log message, with a custom string format, via the
+// Default logger.
+func Infof(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelInfo, format, message...)
+}
+
+// Debug sends a debug log message via the Default logger.
+func Debug(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelDebug, "", message...)
+}
+
+// Debugf sends a debug log message, with a custom string format, via the
+// Default logger.
+func Debugf(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelDebug, format, message...)
+}
+
+// Trace sends a trace log message via the Default logger.
+func Trace(ctx context.Context, message ...interface{}) error {
+	return logDefault(ctx, LevelTrace, "", message...)
+}
+
+// Tracef sends a trace log message, with a custom string format, via the
+// Default logger.
+func Tracef(ctx context.Context, format string, message ...interface{}) error {
+	return logDefault(ctx, LevelTrace, format, message...)
+}
@@ -0,0 +1,100 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var defaultTestCases = []struct {
+	Name     string
+	Log      func(ctx context.Context) error
+	Expected string
+}{
+	{
+		Name:     "std",
+		Log:      func(ctx context.Context) error { return Std(ctx, "hello") },
+		Expected: "OUT hello\n",
+	},
+	{
+		Name:     "criticalf",
+		Log:      func(ctx context.Context) error { return Criticalf(ctx, "%d failed", 3) },
+		Expected: "CRIT 3 failed\n",
+	},
+	{
+		Name:     "errorf",
+		Log:      func(ctx context.Context) error { return Errorf(ctx, "%s", "oops") },
+		Expected: "ERROR oops\n",
+	},
+	{
+		Name:     "warning",
+		Log:      func(ctx context.Context) error { return Warning(ctx, "careful") },
+		Expected: "WARN careful\n",
+	},
+	{
+		Name:     "info",
+		Log:      func(ctx context.Context) error { return Info(ctx, "hello", "world") },
+		Expected: "INFO hello world\n",
+	},
+	{
+		Name:     "debugf",
+		Log:      func(ctx context.Context) error { return Debugf(ctx, "x=%d", 1) },
+		Expected: "DEBUG x=1\n",
+	},
+	{
+		Name: "trace-hidden",
+		Log:  func(ctx context.Context) error { return Trace(ctx, "hidden") },
+	},
+	{
+		Name:     "logf",
+		Log:      func(ctx context.Context) error { return Logf(ctx, LevelInfo, "%s!", "hi") },
+		Expected: "INFO hi!\n",
+	},
+}
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	for _, tc := range defaultTestCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer([]byte{})
+			l, ctx := New(context.Background(), Options{
+				Out:                 buf,
+				Err:                 buf,
+				Threshold:           LevelDebug,
+				DisableFunctionName: true,
+				DisableTimestamps:   true,
+			})
+			SetDefault(l)
+
+			assert.Nil(t, tc.Log(ctx))
+			assert.Equal(t, tc.Expected, buf.String())
+		})
+	}
+}
+
+func TestDefault_Caller(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	buf := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{
+		Out:               buf,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	})
+	SetDefault(l)
+
+	assert.Nil(t, Info(ctx, "hello"))
+	assert.Equal(t, "INFO loggy.TestDefault_Caller hello\n", buf.String())
+}
+
+func TestDefault_Reset(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+	l, _ := New(context.Background(), Options{Name: "custom"})
+	SetDefault(l)
+	assert.Equal(t, Logger(l), Default())
+
+	SetDefault(nil)
+	assert.NotEqual(t, Logger(l), Default())
+	assert.Equal(t, LevelInfo, Default().GetThreshold())
+	assert.Equal(t, Default(), Default())
+}
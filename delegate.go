@@ -0,0 +1,103 @@
+package loggy
+
+// Delegation is the policy that a parent logger applies to the messages of a
+// delegated logger. See Logger.Delegate.
+type Delegation struct {
+	// The maximum verbosity of the delegated messages, applied on top of the
+	// parent's threshold. For example, LevelWarning drops a library's Info and
+	// Debug messages. As with Options.Threshold, standard messages are always
+	// shown, and a Level < 0 disables the delegated messages entirely.
+	Threshold Level
+	// The most severe level the delegated messages may have. Anything more severe
+	// is downgraded to this level, e.g. LevelWarning prevents a library's Critical
+	// messages from paging anyone. The zero value, LevelStd, applies no cap.
+	MaxSeverity Level
+	// Tags added to every delegated message, replacing any tags of the same name
+	// set by the delegate, e.g. {"component": "db"}.
+	Tags map[string]interface{}
+	// Renames tags set by the delegate, from the delegate's tag name to the name
+	// used by the parent, e.g. {"id": "db.id"}.
+	RenameTags map[string]string
+}
+
+// Delegate creates a logger to hand to a library, or any other code that the
+// caller doesn't control, with the provided policy applied to its messages. The
+// delegated logger shares the parent's options, so changes such as SetOutput
+// apply to both. Delegates may themselves be delegated, in which case every
+// policy in the chain is applied, innermost first.
+func (l *logger) Delegate(policy Delegation) Logger {
+	return &logger{
+		parent:     l,
+		delegation: &policy,
+	}
+}
+
+// applyDelegations applies the severity rules of every delegation policy from
+// this logger up to the root. It returns false if the message must be dropped.
+func (l *logger) applyDelegations(severity Level) (Level, bool) {
+	for d := l; d != nil; d = d.parent {
+		if d.delegation == nil {
+			continue
+		}
+		if d.delegation.Threshold < 0 {
+			return severity, false
+		}
+		if severity == LevelStd {
+			continue
+		}
+		if severity > d.delegation.Threshold {
+			return severity, false
+		}
+		if d.delegation.MaxSeverity > LevelStd && severity < d.delegation.MaxSeverity {
+			severity = d.delegation.MaxSeverity
+		}
+	}
+	return severity, true
+}
+
+// delegatedTags applies the tag rules of every delegation policy from this logger
+// up to the root. The provided tags are not modified.
+func (l *logger) delegatedTags(tags map[string]interface{}) map[string]interface{} {
+	for d := l; d != nil; d = d.parent {
+		if d.delegation == nil {
+			continue
+		}
+		if len(d.delegation.Tags) == 0 && len(d.delegation.RenameTags) == 0 {
+			continue
+		}
+
+		rewritten := make(map[string]interface{}, len(tags)+len(d.delegation.Tags))
+		for name, value := range tags {
+			if renamed, ok := d.delegation.RenameTags[name]; ok {
+				name = renamed
+			}
+			rewritten[name] = value
+		}
+		for name, value := range d.delegation.Tags {
+			rewritten[name] = value
+		}
+		tags = rewritten
+	}
+	return tags
+}
+
+// delegatedFields applies the tag renames of every delegation policy from this
+// logger up to the root to the fields of a single message. The provided fields
+// are not modified.
+func (l *logger) delegatedFields(fields map[string]interface{}) map[string]interface{} {
+	for d := l; d != nil && len(fields) > 0; d = d.parent {
+		if d.delegation == nil || len(d.delegation.RenameTags) == 0 {
+			continue
+		}
+
+		rewritten := make(map[string]interface{}, len(fields))
+		for name, value := range fields {
+			if renamed, ok := d.delegation.RenameTags[name]; ok {
+				name = renamed
+			}
+			rewritten[name] = value
+		}
+		fields = rewritten
+	}
+	return fields
+}
@@ -0,0 +1,110 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var delegateTestCases = []struct {
+	Name           string
+	Policy         Delegation
+	Severity       Level
+	ExpectedStdout string
+	ExpectedStderr string
+}{
+	{
+		Name:           "within-threshold",
+		Policy:         Delegation{Threshold: LevelInfo},
+		Severity:       LevelInfo,
+		ExpectedStdout: "INFO library message\n",
+	},
+	{
+		Name:     "above-delegated-threshold",
+		Policy:   Delegation{Threshold: LevelWarning},
+		Severity: LevelInfo,
+	},
+	{
+		Name:           "standard-always-shown",
+		Policy:         Delegation{Threshold: LevelCritical},
+		Severity:       LevelStd,
+		ExpectedStdout: "OUT library message\n",
+	},
+	{
+		Name:     "disabled",
+		Policy:   Delegation{Threshold: -1},
+		Severity: LevelStd,
+	},
+	{
+		Name:           "severity-capped",
+		Policy:         Delegation{Threshold: LevelDebug, MaxSeverity: LevelWarning},
+		Severity:       LevelCritical,
+		ExpectedStderr: "WARN library message\n",
+	},
+	{
+		Name: "tags-rewritten",
+		Policy: Delegation{
+			Threshold:  LevelDebug,
+			Tags:       map[string]interface{}{"component": "db", "request": "host"},
+			RenameTags: map[string]string{"id": "db.id"},
+		},
+		Severity:       LevelInfo,
+		ExpectedStdout: "INFO [component:db, db.id:7, request:host] library message\n",
+	},
+}
+
+func TestLogger_Delegate(t *testing.T) {
+	for _, testCase := range delegateTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			stdout := bytes.NewBuffer([]byte{})
+			stderr := bytes.NewBuffer([]byte{})
+			options := Options{
+				Out:                 stdout,
+				Err:                 stderr,
+				Threshold:           LevelDebug,
+				DisableFunctionName: true,
+				DisableTimestamps:   true,
+			}
+			l, ctx := New(context.Background(), options)
+			library := l.Delegate(testCase.Policy)
+			if len(testCase.Policy.Tags) > 0 {
+				_, ctx = library.AddTag(ctx, "id", 7)
+				_, ctx = library.AddTag(ctx, "request", "library")
+			}
+
+			assert.Nil(t, library.Log(ctx, testCase.Severity, "library message"))
+			assert.Equal(t, testCase.ExpectedStdout, stdout.String())
+			assert.Equal(t, testCase.ExpectedStderr, stderr.String())
+		})
+	}
+}
+
+func TestLogger_Delegate_SharesOptions(t *testing.T) {
+	options := Options{
+		Out:       bytes.NewBuffer([]byte{}),
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+	library := l.Delegate(Delegation{Threshold: LevelDebug})
+
+	// The parent's threshold still applies.
+	stdout := bytes.NewBuffer([]byte{})
+	l.SetOutput(stdout, nil)
+	assert.Nil(t, library.Debug(ctx, "hidden"))
+	assert.Nil(t, library.Info(ctx, "shown"))
+	assert.Regexp(t, "INFO loggy.TestLogger_Delegate_SharesOptions shown\n$", stdout.String())
+	assert.NotContains(t, stdout.String(), "hidden")
+
+	// Nested delegates apply every policy in the chain.
+	nested := library.Delegate(Delegation{Threshold: LevelDebug, MaxSeverity: LevelInfo})
+	stdout.Reset()
+	assert.Nil(t, nested.Critical(ctx, "downgraded"))
+	assert.Regexp(t, "INFO loggy.TestLogger_Delegate_SharesOptions downgraded\n$", stdout.String())
+
+	// The parent's threshold is applied to the downgraded severity.
+	quiet := library.Delegate(Delegation{Threshold: LevelDebug, MaxSeverity: LevelDebug})
+	stdout.Reset()
+	assert.Nil(t, quiet.Critical(ctx, "downgraded"))
+	assert.Equal(t, "", stdout.String())
+}
@@ -0,0 +1,163 @@
+package loggy
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Describe writes a summary of the logger's effective configuration to w, for
+// debugging why messages are or aren't showing up where expected.
+func (l *logger) Describe(w io.Writer) error {
+	options := l.currentOptions()
+
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	line := func(name string, format string, values ...interface{}) {
+		fmt.Fprintf(tw, "%s:\t"+format+"\n", append([]interface{}{name}, values...)...)
+	}
+
+	if name := l.fullName(options); name != "" {
+		line("name", "%s", name)
+	}
+	line("threshold", "%s", describeLevel(options.Threshold))
+	if len(options.Thresholds) > 0 {
+		rules := make([]string, 0, len(options.Thresholds))
+		for name, level := range options.Thresholds {
+			rules = append(rules, name+"="+describeLevel(level))
+		}
+		sort.Strings(rules)
+		line("thresholds", "%s", strings.Join(rules, " "))
+		if level := l.threshold(options); level != options.Threshold {
+			line("named threshold", "%s", describeLevel(level))
+		}
+	}
+	if on, since := l.IncidentMode(); on {
+		line("incident mode", "on since %s", since.Format(time.RFC3339))
+	}
+	if options.Encoder != nil {
+		line("encoder", "%T", options.Encoder)
+	}
+	line("out", "%T (%s)", options.Out, describeStreamLevels(options, true))
+	line("err", "%T (%s)", options.Err, describeStreamLevels(options, false))
+	for level := LevelStd; level <= LevelTrace; level++ {
+		if w := options.LevelOutputs[level]; w != nil {
+			line(strings.ToLower(level.String()), "%T", w)
+		}
+	}
+	if options.TeeCriticalStderr {
+		line("tee", "CRIT to *os.File (stderr)")
+	}
+	if len(options.Destinations) > 0 {
+		names := make([]string, 0, len(options.Destinations))
+		for name, w := range options.Destinations {
+			names = append(names, fmt.Sprintf("%s=%T", name, w))
+		}
+		sort.Strings(names)
+		line("destinations", "%s", strings.Join(names, " "))
+	}
+	if options.Sampling != nil {
+		line("sampling", "initial=%d thereafter=%d tick=%s",
+			options.Sampling.Initial, options.Sampling.Thereafter, options.Sampling.Tick)
+	} else {
+		line("sampling", "off")
+	}
+	line("skip canceled", "%t", options.SkipCanceled)
+	if queue := l.root().async; queue != nil {
+		line("async", "%d of %d queued, %d dropped, %d expired",
+			len(queue.entries), cap(queue.entries), l.AsyncDropped(), l.AsyncExpired())
+	}
+	if len(options.Processors) > 0 {
+		line("processors", "%d", len(options.Processors))
+	}
+	if options.DropTrace != nil {
+		line("drop trace", "%T", options.DropTrace)
+	}
+	if options.StacktraceLevel > LevelStd {
+		line("stack traces", "%s and above", describeLevel(options.StacktraceLevel))
+	} else {
+		line("stack traces", "off")
+	}
+	if options.DisableTimestamps {
+		line("timestamps", "off")
+	} else if options.TimestampEncoder != nil {
+		line("timestamps", "custom encoder")
+	} else {
+		line("timestamps", "%q", options.TimestampFormat)
+	}
+	line("function name", "%t", !options.DisableFunctionName)
+	line("tags", "%t (flatten=%t)", !options.DisableTags, options.FlattenTags)
+	if options.Prefix != "" {
+		line("prefix", "%q", options.Prefix)
+	}
+	line("color", "%t", options.Color)
+	hooks := l.root().hooks
+	hooks.mux.RLock()
+	if n := len(hooks.list); n > 0 {
+		line("hooks", "%d", n)
+	}
+	hooks.mux.RUnlock()
+	if running := l.Workers(); len(running) > 0 {
+		line("workers", "%d running", len(running))
+	}
+
+	depth := 0
+	for d := l; d != nil; d = d.parent {
+		if d.delegation == nil {
+			continue
+		}
+		depth++
+		line(fmt.Sprintf("delegation %d", depth), "%s", describeDelegation(d.delegation))
+	}
+
+	return tw.Flush()
+}
+
+// describeLevel names the level, noting when it disables logging.
+func describeLevel(level Level) string {
+	if level < 0 {
+		return "disabled"
+	}
+	if name, ok := LevelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", level)
+}
+
+// describeStreamLevels lists the labels of the levels written to the output
+// stream, or the error stream, that aren't sent elsewhere by LevelOutputs.
+func describeStreamLevels(options *Options, out bool) string {
+	order := []Level{LevelStd, LevelInfo, LevelDebug, LevelTrace, LevelCritical, LevelError, LevelWarning}
+	var labels []string
+	for _, level := range order {
+		if options.LevelOutputs[level] != nil || (level == LevelStd || level >= LevelInfo) != out {
+			continue
+		}
+		labels = append(labels, level.String())
+	}
+	if len(labels) == 0 {
+		return "none"
+	}
+	return strings.Join(labels, ", ")
+}
+
+func describeDelegation(policy *Delegation) string {
+	parts := []string{"threshold=" + describeLevel(policy.Threshold)}
+	if policy.MaxSeverity > LevelStd {
+		parts = append(parts, "max-severity="+describeLevel(policy.MaxSeverity))
+	}
+	if len(policy.Tags) > 0 {
+		parts = append(parts, "tags="+formatTags(&DefaultOptions, policy.Tags))
+	}
+	if len(policy.RenameTags) > 0 {
+		renames := make([]string, 0, len(policy.RenameTags))
+		for from, to := range policy.RenameTags {
+			renames = append(renames, from+"->"+to)
+		}
+		sort.Strings(renames)
+		parts = append(parts, "renames=["+strings.Join(renames, ", ")+"]")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,67 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLogger_Describe(t *testing.T) {
+	options := Options{
+		Name:      "api",
+		Out:       bytes.NewBuffer([]byte{}),
+		Threshold: LevelInfo,
+		Sampling: &Sampling{
+			Initial:    10,
+			Thereafter: 5,
+			Tick:       time.Second,
+		},
+		StacktraceLevel: LevelError,
+	}
+	l, _ := New(context.Background(), options)
+	library := l.Delegate(Delegation{
+		Threshold:   LevelWarning,
+		MaxSeverity: LevelError,
+		Tags:        map[string]interface{}{"component": "db"},
+		RenameTags:  map[string]string{"id": "db.id"},
+	})
+
+	out := bytes.NewBuffer([]byte{})
+	assert.Nil(t, library.Describe(out))
+	assert.Equal(t, `name:          api
+threshold:     INFO
+out:           *bytes.Buffer (OUT, INFO, DEBUG, TRACE)
+err:           *os.File (CRIT, ERROR, WARN)
+sampling:      initial=10 thereafter=5 tick=1s
+skip canceled: false
+stack traces:  ERROR and above
+timestamps:    "2006-01-02T15:04:05Z07:00"
+function name: true
+tags:          true (flatten=false)
+color:         false
+delegation 1:  threshold=WARN max-severity=ERROR tags=[component:db] renames=[id->db.id]
+`, out.String())
+}
+
+func TestLogger_Describe_LevelOutputs(t *testing.T) {
+	options := Options{
+		Out: bytes.NewBuffer([]byte{}),
+		LevelOutputs: map[Level]io.Writer{
+			LevelCritical: bytes.NewBuffer([]byte{}),
+			LevelWarning:  os.Stdout,
+		},
+	}
+	l, _ := New(context.Background(), options)
+
+	out := bytes.NewBuffer([]byte{})
+	assert.Nil(t, l.Describe(out))
+	assert.Contains(t, out.String(), `out:           *bytes.Buffer (OUT, INFO, DEBUG, TRACE)
+err:           *os.File (ERROR)
+crit:          *bytes.Buffer
+warn:          *os.File
+`)
+}
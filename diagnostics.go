@@ -0,0 +1,84 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+)
+
+// DiagnosticsMaxTags is the number of tags on a single message above which
+// Options.Diagnostics reports the tags as excessive.
+var DiagnosticsMaxTags = 100
+
+// misuse is a mistake detected by Options.Diagnostics.
+type misuse struct {
+	kind    string
+	message string
+	hint    string
+}
+
+var (
+	misuseAfterClose = misuse{
+		kind:    "after-close",
+		message: "message logged after its destination was closed",
+		hint:    "close writers only after every goroutine that logs to them has finished",
+	}
+	misuseForeignTags = misuse{
+		kind:    "foreign-tags",
+		message: "tag added to a context carrying another logger's tags",
+		hint:    "use the logger that created the context, or share a TagsContextKey between loggers",
+	}
+	misuseExcessiveTags = misuse{
+		kind:    "excessive-tags",
+		message: "message has an excessive number of tags",
+		hint:    "move bulk data into a single tag, or log it separately",
+	}
+)
+
+// diagnosis identifies a misuse at a single call site.
+type diagnosis struct {
+	pc   uintptr
+	kind string
+}
+
+// diagnose logs a warning about the misuse, once per call site, if diagnostics
+// are enabled. The skip argument is the number of stack frames to ascend, from
+// diagnose, to find the call site.
+func (l *logger) diagnose(skip int, m misuse) {
+	if !l.currentOptions().Diagnostics {
+		return
+	}
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return
+	}
+	if _, seen := l.root().diagnosed.LoadOrStore(diagnosis{pc: pc, kind: m.kind}, true); seen {
+		return
+	}
+
+	_, _ = l.emit(context.Background(), skip+1, LevelWarning, "%s", m.message, Bypass(), Fields(map[string]interface{}{
+		"loggy.misuse": m.kind,
+		"loggy.hint":   m.hint,
+	}))
+}
+
+// isClosedError reports whether err came from writing to a closed writer.
+func isClosedError(err error) bool {
+	return errors.Is(err, ErrClosed) || errors.Is(err, os.ErrClosed)
+}
+
+// hasForeignTags reports whether ctx carries tags for a logger that uses a
+// different TagsContextKey.
+func (l *logger) hasForeignTags(ctx context.Context) bool {
+	other, ok := ctx.Value(loggerKey).(*logger)
+	if !ok || other.root() == l.root() {
+		return false
+	}
+	key := other.currentOptions().TagsContextKey
+	if key == l.currentOptions().TagsContextKey {
+		return false
+	}
+	_, ok = ctx.Value(key).(map[string]interface{})
+	return ok
+}
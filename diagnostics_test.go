@@ -0,0 +1,77 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newDiagnosticsTestLogger(stderr *bytes.Buffer) (*logger, context.Context) {
+	options := Options{
+		Out:               stderr,
+		Err:               stderr,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+		Diagnostics:       true,
+	}
+	return New(context.Background(), options)
+}
+
+func TestDiagnostics_AfterClose(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	l, ctx := newDiagnosticsTestLogger(stderr)
+	w := NewGzipWriter(bytes.NewBuffer([]byte{}), time.Hour)
+	assert.Nil(t, w.Close())
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, ErrClosed, l.Info(ctx, "too late", ForceDestination(w)))
+	}
+	assert.Equal(t,
+		"WARN loggy.TestDiagnostics_AfterClose [loggy.hint:close writers only after every goroutine that logs to them has finished, loggy.misuse:after-close] message logged after its destination was closed\n",
+		stderr.String(),
+	)
+}
+
+func TestDiagnostics_ForeignTags(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	l, _ := newDiagnosticsTestLogger(stderr)
+	other, ctx := New(context.Background(), Options{TagsContextKey: NewTagsKey("other.tags")})
+	_, ctx = other.AddTag(ctx, "request", 1)
+
+	_, _ = l.AddTag(ctx, "user", "bob")
+	_, _ = l.AddTag(context.Background(), "user", "bob")
+	assert.Equal(t,
+		"WARN loggy.TestDiagnostics_ForeignTags [loggy.hint:use the logger that created the context, or share a TagsContextKey between loggers, loggy.misuse:foreign-tags] tag added to a context carrying another logger's tags\n",
+		stderr.String(),
+	)
+}
+
+func TestDiagnostics_ExcessiveTags(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	l, ctx := newDiagnosticsTestLogger(stderr)
+	l.options.Out = bytes.NewBuffer([]byte{})
+	fields := make(map[string]interface{}, DiagnosticsMaxTags+1)
+	for i := 0; i <= DiagnosticsMaxTags; i++ {
+		fields[fmt.Sprintf("tag%d", i)] = i
+	}
+
+	assert.Nil(t, l.Info(ctx, "bulk", Fields(fields)))
+	assert.Equal(t,
+		"WARN loggy.TestDiagnostics_ExcessiveTags [loggy.hint:move bulk data into a single tag, or log it separately, loggy.misuse:excessive-tags] message has an excessive number of tags\n",
+		stderr.String(),
+	)
+}
+
+func TestDiagnostics_Disabled(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	l, ctx := newDiagnosticsTestLogger(stderr)
+	l.options.Diagnostics = false
+	w := NewGzipWriter(bytes.NewBuffer([]byte{}), time.Hour)
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, ErrClosed, l.Info(ctx, "too late", ForceDestination(w)))
+	assert.Equal(t, "", stderr.String())
+}
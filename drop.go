@@ -0,0 +1,85 @@
+package loggy
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// DropReason explains why a message was not written.
+type DropReason string
+
+const (
+	// DropDisabled indicates that logging is disabled, via a Threshold < 0.
+	DropDisabled DropReason = "disabled"
+	// DropThreshold indicates that the message was less severe than the threshold.
+	DropThreshold DropReason = "threshold"
+	// DropDelegation indicates that the message was rejected by a Delegation policy.
+	DropDelegation DropReason = "delegation"
+	// DropCanceled indicates that the message's context was canceled, and
+	// Options.SkipCanceled is enabled.
+	DropCanceled DropReason = "canceled"
+	// DropSampled indicates that the message was dropped by Options.Sampling.
+	DropSampled DropReason = "sampled"
+	// DropProcessor indicates that the message was dropped by one of
+	// Options.Processors.
+	DropProcessor DropReason = "processor"
+	// DropQueueFull indicates that the message was dropped because the queue of
+	// Options.Async was full, either when it was logged, or afterwards, to make
+	// room for a newer message.
+	DropQueueFull DropReason = "queue-full"
+	// DropExpired indicates that the message was dropped because it waited in
+	// the queue of Options.Async for longer than Async.TTL.
+	DropExpired DropReason = "expired"
+)
+
+// filtering reports whether the reason is deliberate filtering, such as the
+// threshold, rather than the loss of a message that should have been written.
+func (r DropReason) filtering() bool {
+	switch r {
+	case DropDisabled, DropThreshold, DropDelegation, DropCanceled:
+		return true
+	}
+	return false
+}
+
+// drop records that a message was not written, tracing the reason if
+// Options.DropTrace is set. The skip argument is the number of stack frames to
+// ascend, from drop, to find the function that requested the log.
+func (l *logger) drop(options *Options, skip int, reason DropReason, severity Level, format string, message []interface{}) EmitResult {
+	if reason.filtering() {
+		atomic.AddInt64(&l.root().stats.filtered, 1)
+	} else {
+		atomic.AddInt64(&l.root().stats.dropped, 1)
+	}
+	result := EmitResult{
+		Filtered: true,
+		Sampled:  reason == DropSampled,
+		Reason:   reason,
+	}
+	if options.DropTrace == nil {
+		return result
+	}
+
+	caller := "unknown"
+	if pc, _, _, ok := runtime.Caller(skip + 1); ok {
+		caller = callerName(pc)
+	}
+	text := fmt.Sprint(message...)
+	if format != "" {
+		text = fmt.Sprintf(format, message...)
+	}
+	traceDrop(options, reason, severity, caller, text)
+
+	return result
+}
+
+// traceDrop writes a one-line trace of a dropped message to Options.DropTrace.
+func traceDrop(options *Options, reason DropReason, severity Level, caller, text string) {
+	label, ok := LevelNames[severity]
+	if !ok {
+		label = fmt.Sprintf("%d", severity)
+	}
+	trace := fmt.Sprintf("DROP %s %s %s %q\n", reason, label, caller, text)
+	_, _ = options.DropTrace.Write([]byte(maybePrefixTimestamp(options, trace)))
+}
@@ -0,0 +1,58 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_DropTrace(t *testing.T) {
+	trace := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               bytes.NewBuffer([]byte{}),
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+		SkipCanceled:      true,
+		DropTrace:         trace,
+		Sampling: &Sampling{
+			Initial: 1,
+			Tick:    time.Hour,
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	library := l.Delegate(Delegation{Threshold: LevelWarning})
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	result, err := l.Emit(ctx, LevelDebug, "too verbose")
+	assert.Nil(t, err)
+	assert.Equal(t, DropThreshold, result.Reason)
+
+	assert.Nil(t, library.Info(ctx, "library chatter"))
+	assert.Nil(t, l.Info(canceled, "abandoned"))
+	assert.Nil(t, l.Infof(ctx, "repeated %d", 1))
+	assert.Nil(t, l.Infof(ctx, "repeated %d", 2))
+
+	assert.Equal(t, []string{
+		`DROP threshold DEBUG loggy.TestOptions_DropTrace "too verbose"`,
+		`DROP delegation INFO loggy.TestOptions_DropTrace "library chatter"`,
+		`DROP canceled INFO loggy.TestOptions_DropTrace "abandoned"`,
+		`DROP sampled INFO loggy.TestOptions_DropTrace "repeated 2"`,
+	}, strings.Split(strings.TrimSpace(trace.String()), "\n"))
+}
+
+func TestOptions_DropTrace_Disabled(t *testing.T) {
+	trace := bytes.NewBuffer([]byte{})
+	options := Options{
+		Threshold:         -1,
+		DisableTimestamps: true,
+		DropTrace:         trace,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Std(ctx, "quiet"))
+	assert.Equal(t, "DROP disabled OUT loggy.TestOptions_DropTrace_Disabled \"quiet\"\n", trace.String())
+}
@@ -0,0 +1,81 @@
+package loggy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the version of the Elastic Common Schema output by ECSEncoder.
+const ECSVersion = "8.11.0"
+
+// ECSEncoder encodes each entry as a line of JSON with the field names of the
+// Elastic Common Schema, so that it can be indexed by Elasticsearch and
+// explored in Kibana without an ingest pipeline. For example:
+//
+//	{"@timestamp":"2006-01-02T15:04:05Z","log.level":"error","message":"oops","ecs.version":"8.11.0","log.origin.function":"main.main","labels":{"user":"bob"}}
+//
+// The level is the lowercase name of the entry's level, e.g. "warn". The
+// logger, error code, and stack trace are output as log.logger, error.code,
+// and error.stack_trace, and are omitted when empty, as is the timestamp. Tags
+// are output as labels, which ECS defines as keywords, so their values are
+// encoded as text, and dots in their names, which Elasticsearch would expand
+// into objects, are replaced with '_'. Error tags are expanded as they are by
+// ErrorFields.
+type ECSEncoder struct{}
+
+type ecsEntry struct {
+	Timestamp  string            `json:"@timestamp,omitempty"`
+	Level      string            `json:"log.level"`
+	Message    string            `json:"message"`
+	Version    string            `json:"ecs.version"`
+	Logger     string            `json:"log.logger,omitempty"`
+	Function   string            `json:"log.origin.function,omitempty"`
+	Code       string            `json:"error.code,omitempty"`
+	StackTrace string            `json:"error.stack_trace,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// Encode implements Encoder.
+func (e ECSEncoder) Encode(entry Entry) ([]byte, error) {
+	encoded := ecsEntry{
+		Level:      strings.ToLower(LevelNames[entry.Level]),
+		Message:    entry.Message,
+		Version:    ECSVersion,
+		Logger:     entry.Logger,
+		Function:   entry.Caller,
+		Code:       entry.Code,
+		StackTrace: entry.Stack,
+	}
+	if !entry.Time.IsZero() {
+		encoded.Timestamp = entry.Time.Format(time.RFC3339Nano)
+	}
+	if entry.Prefix != "" {
+		encoded.Message = entry.Prefix + " " + encoded.Message
+	}
+	if tags := entry.AllTags(); len(tags) > 0 {
+		encoded.Labels = make(map[string]string, len(tags))
+		label := func(name string, value interface{}) {
+			encoded.Labels[strings.ReplaceAll(name, ".", "_")] = fmt.Sprint(textValue(value))
+		}
+		for name, value := range tags {
+			if err, ok := value.(error); ok {
+				for key, field := range ErrorFields(name, err, false) {
+					label(key, field)
+				}
+				continue
+			}
+			label(name, value)
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 160+len(encoded.Message)))
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(encoded); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
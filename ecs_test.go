@@ -0,0 +1,54 @@
+package loggy
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var ecsEncoderTestCases = []struct {
+	Name     string
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: `{"log.level":"out","message":"","ecs.version":"8.11.0"}` + "\n",
+	},
+	{
+		Name: "every-field",
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelError,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "<oops>",
+			Tags:    map[string]interface{}{"user.id": "bob", "attempt": 3},
+			Stack:   "main.main\n\tmain.go:1\n",
+		},
+		Expected: `{"@timestamp":"2006-01-02T15:04:05.123456789Z","log.level":"error","message":"~~~ <oops>","ecs.version":"8.11.0",` +
+			`"log.logger":"app.db","log.origin.function":"main.main","error.code":"E4321","error.stack_trace":"main.main\n\tmain.go:1\n",` +
+			`"labels":{"attempt":"3","user_id":"bob"}}` + "\n",
+	},
+	{
+		Name: "error-labels",
+		Entry: Entry{
+			Level:  LevelWarning,
+			Fields: map[string]interface{}{"err": errors.New("timed out")},
+		},
+		Expected: `{"log.level":"warn","message":"","ecs.version":"8.11.0","labels":{"err":"timed out","errType":"*errors.errorString"}}` + "\n",
+	},
+}
+
+func TestECSEncoder_Encode(t *testing.T) {
+	for _, testCase := range ecsEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := ECSEncoder{}.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package loggy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoder formats entries for output, e.g. as CSV, protobuf, or custom text.
+// See Options.Encoder. The logger applies the options that concern every format
+// before encoding: an entry's Time is zero if timestamps are disabled, and its
+// Tags and Fields are nil if tags are disabled, or otherwise bounded by Sanitize
+// and, if Options.FlattenTags is set, flattened. Most encoders output AllTags.
+type Encoder interface {
+	// Encode returns the encoded entry, including any trailing newline.
+	Encode(entry Entry) ([]byte, error)
+}
+
+// TextEncoder encodes entries in the default text layout, used when
+// Options.Encoder is nil. For example:
+//
+//	2006-01-02T15:04:05Z ERROR app.db main.main [user:bob] [E1234] ~~~ oops
+//
+// Custom encoders can wrap it, e.g. to decorate each line.
+type TextEncoder struct {
+	options *Options
+}
+
+// NewTextEncoder creates a TextEncoder that formats timestamps and severities as
+// configured by the options.
+func NewTextEncoder(options Options) TextEncoder {
+	applyDefaults(&options)
+	return TextEncoder{options: &options}
+}
+
+// Encode implements Encoder.
+func (e TextEncoder) Encode(entry Entry) ([]byte, error) {
+	options := e.options
+	if options == nil {
+		options = &DefaultOptions
+	}
+
+	// Assemble the whole entry in a single buffer, so it can be written at once.
+	buf := make([]byte, 0, 128+len(entry.Message))
+	if !entry.Time.IsZero() {
+		buf = appendTimestamp(buf, options, entry.Time)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, levelLabel(options, entry.Level)...)
+	if entry.Logger != "" {
+		buf = append(append(buf, ' '), entry.Logger...)
+	}
+	if entry.Caller != "" {
+		buf = append(append(buf, ' '), entry.Caller...)
+	}
+	if tags := entry.AllTags(); len(tags) > 0 {
+		buf = appendPreparedTags(append(buf, ' '), tags)
+	}
+	if entry.Code != "" {
+		buf = append(append(buf, " ["...), entry.Code...)
+		buf = append(buf, ']')
+	}
+	if entry.Prefix != "" {
+		// Append prefix before the user-formatted message.
+		buf = append(append(buf, ' '), entry.Prefix...)
+	}
+	if entry.Message != "" {
+		buf = append(append(buf, ' '), entry.Message...)
+	}
+	if entry.Stack != "" {
+		buf = append(append(buf, '\n'), entry.Stack...)
+	}
+	return append(buf, '\n'), nil
+}
+
+// encoderEntry prepares a copy of the entry for an Encoder, applying the
+// options described by Encoder.
+func encoderEntry(options *Options, entry Entry) Entry {
+	if options.DisableTimestamps {
+		entry.Time = time.Time{}
+	}
+	if options.DisableTags {
+		entry.Tags = nil
+	} else if len(entry.Tags) > 0 {
+		entry.Tags = prepareTags(options, entry.Tags)
+	}
+	if len(entry.Fields) > 0 {
+		entry.Fields = prepareTags(options, entry.Fields)
+	}
+	return entry
+}
+
+// JSONEncoder encodes each entry as a single line of JSON, for log shippers
+// such as Logstash or Promtail to ingest without parsing. For example:
+//
+//	{"schema":1,"time":"2006-01-02T15:04:05Z","level":"ERROR","caller":"main.main","message":"oops","tags":{"user":"bob"}}
+//
+// The schema key holds SchemaVersion. The time, logger, caller, code, docs, prefix, tags, and stack keys are
+// omitted when empty. The docs key holds the DocsURL of a registered error
+// code. Error tag values are expanded as they are by ErrorFields, Stringer
+// values are encoded as their text, and any value that can't be encoded as JSON
+// is encoded with the %v verb instead.
+type JSONEncoder struct {
+	// The layout to format timestamps with, defaulting to time.RFC3339Nano.
+	// Options.TimestampFormat and Options.TimestampEncoder only apply to text.
+	TimestampFormat string
+	// Set to true to output the time as a number, the seconds since the Unix
+	// epoch with nanosecond precision, e.g. 1136214245.123456789, instead of
+	// formatting it with TimestampFormat.
+	EpochTimestamps bool
+	// Set to true to check each entry against JSONSchema after encoding it,
+	// returning an error wrapping ErrSchemaMismatch instead of an entry that
+	// doesn't match. It's slow, so it's intended for tests and CI, to catch
+	// changes to the output that consumers' parsers don't expect.
+	Validate bool
+	// Set to true to include the stack traces of error tags, as the name+"Stack"
+	// key added by ErrorFields.
+	ErrorStack bool
+}
+
+type jsonEntry struct {
+	Schema  int                        `json:"schema"`
+	Time    json.RawMessage            `json:"time,omitempty"`
+	Level   string                     `json:"level"`
+	Logger  string                     `json:"logger,omitempty"`
+	Caller  string                     `json:"caller,omitempty"`
+	Code    string                     `json:"code,omitempty"`
+	Docs    string                     `json:"docs,omitempty"`
+	Prefix  string                     `json:"prefix,omitempty"`
+	Message string                     `json:"message"`
+	Tags    map[string]json.RawMessage `json:"tags,omitempty"`
+	Stack   string                     `json:"stack,omitempty"`
+}
+
+// Encode implements Encoder.
+func (e JSONEncoder) Encode(entry Entry) ([]byte, error) {
+	encoded := jsonEntry{
+		Schema:  SchemaVersion,
+		Level:   LevelNames[entry.Level],
+		Logger:  entry.Logger,
+		Caller:  entry.Caller,
+		Code:    entry.Code,
+		Prefix:  entry.Prefix,
+		Message: entry.Message,
+		Stack:   entry.Stack,
+	}
+	if !entry.Time.IsZero() {
+		if e.EpochTimestamps {
+			encoded.Time = json.RawMessage(fmt.Sprintf("%d.%09d", entry.Time.Unix(), entry.Time.Nanosecond()))
+		} else {
+			format := e.TimestampFormat
+			if format == "" {
+				format = time.RFC3339Nano
+			}
+			encoded.Time = jsonValue(entry.Time.Format(format))
+		}
+	}
+	if code, ok := LookupErrorCode(entry.Code); ok {
+		encoded.Docs = code.DocsURL
+	}
+	if tags := entry.AllTags(); len(tags) > 0 {
+		encoded.Tags = make(map[string]json.RawMessage, len(tags))
+		for name, value := range tags {
+			if err, ok := value.(error); ok {
+				for key, field := range ErrorFields(name, err, e.ErrorStack) {
+					encoded.Tags[key] = jsonValue(field)
+				}
+				continue
+			}
+			encoded.Tags[name] = jsonValue(value)
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 128+len(entry.Message)))
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(encoded); err != nil {
+		return nil, err
+	}
+	if e.Validate {
+		if err := ValidateJSONEntry(buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonValue encodes a single tag value, falling back to its %v formatting if it
+// can't be encoded as JSON, e.g. a channel or a NaN float.
+func jsonValue(value interface{}) json.RawMessage {
+	switch value.(type) {
+	case json.Marshaler:
+	case fmt.Stringer:
+		value = textValue(value)
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return b
+	}
+	b, _ := json.Marshal(fmt.Sprint(value))
+	return b
+}
+
+// LogfmtEncoder encodes each entry as a line of space-separated key=value
+// pairs, as preferred by Heroku and Grafana Loki. For example:
+//
+//	time=2006-01-02T15:04:05Z level=ERROR caller=main.main msg="disk full" user=bob
+//
+// The keys match those of JSONEncoder, except that the message is under msg and
+// tags follow it as keys of their own, sorted by name. Values are quoted when
+// they're empty or contain spaces, quotes, equals signs, or control characters.
+// Characters in tag names that would break the pair are replaced with '_'.
+type LogfmtEncoder struct {
+	// The layout to format timestamps with, defaulting to time.RFC3339Nano.
+	// Options.TimestampFormat and Options.TimestampEncoder only apply to text.
+	TimestampFormat string
+	// Set to true to include the stack traces of error tags, as the name+"Stack"
+	// key added by ErrorFields.
+	ErrorStack bool
+}
+
+// Encode implements Encoder.
+func (e LogfmtEncoder) Encode(entry Entry) ([]byte, error) {
+	buf := make([]byte, 0, 128+len(entry.Message))
+	pair := func(key, value string) {
+		if len(buf) > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = appendLogfmtValue(append(append(buf, logfmtKey(key)...), '='), value)
+	}
+
+	if !entry.Time.IsZero() {
+		format := e.TimestampFormat
+		if format == "" {
+			format = time.RFC3339Nano
+		}
+		pair("time", entry.Time.Format(format))
+	}
+	pair("level", LevelNames[entry.Level])
+	var docs string
+	if code, ok := LookupErrorCode(entry.Code); ok {
+		docs = code.DocsURL
+	}
+	optional := [][2]string{
+		{"logger", entry.Logger},
+		{"caller", entry.Caller},
+		{"code", entry.Code},
+		{"docs", docs},
+		{"prefix", entry.Prefix},
+	}
+	for _, field := range optional {
+		if field[1] != "" {
+			pair(field[0], field[1])
+		}
+	}
+	pair("msg", entry.Message)
+
+	tags := entry.AllTags()
+	fields := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		if err, ok := value.(error); ok {
+			for key, field := range ErrorFields(name, err, e.ErrorStack) {
+				fields[key] = field
+			}
+			continue
+		}
+		fields[name] = value
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pair(name, fmt.Sprint(textValue(fields[name])))
+	}
+
+	if entry.Stack != "" {
+		pair("stack", entry.Stack)
+	}
+	return append(buf, '\n'), nil
+}
+
+// logfmtKey replaces the characters that can't appear in a logfmt key.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if isLogfmtSpecial(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// appendLogfmtValue appends the value, quoting it if needed.
+func appendLogfmtValue(buf []byte, value string) []byte {
+	if value == "" {
+		return append(buf, `""`...)
+	}
+	for _, r := range value {
+		if isLogfmtSpecial(r) {
+			return strconv.AppendQuote(buf, value)
+		}
+	}
+	return append(buf, value...)
+}
+
+// isLogfmtSpecial reports whether r would break a logfmt pair.
+func isLogfmtSpecial(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsControl(r)
+}
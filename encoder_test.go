@@ -0,0 +1,257 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+var jsonEncoderTestCases = []struct {
+	Name     string
+	Encoder  JSONEncoder
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: `{"schema":1,"level":"OUT","message":""}` + "\n",
+	},
+	{
+		Name: "every-field",
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelError,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "<oops>",
+			Tags:    map[string]interface{}{"user": "bob", "attempt": 3},
+			Stack:   "main.main\n\tmain.go:1\n",
+		},
+		Expected: `{"schema":1,"time":"2006-01-02T15:04:05.123456789Z","level":"ERROR","logger":"app.db","caller":"main.main",` +
+			`"code":"E4321","docs":"https://example.com/runbooks/E4321","prefix":"~~~","message":"<oops>",` +
+			`"tags":{"attempt":3,"user":"bob"},"stack":"main.main\n\tmain.go:1\n"}` + "\n",
+	},
+	{
+		Name:     "timestamp-format",
+		Encoder:  JSONEncoder{TimestampFormat: time.RFC3339},
+		Entry:    Entry{Time: loggyTestTime, Level: LevelInfo, Message: "hi"},
+		Expected: `{"schema":1,"time":"2006-01-02T15:04:05Z","level":"INFO","message":"hi"}` + "\n",
+	},
+	{
+		Name:     "epoch-timestamps",
+		Encoder:  JSONEncoder{EpochTimestamps: true},
+		Entry:    Entry{Time: loggyTestTime, Level: LevelInfo, Message: "hi"},
+		Expected: `{"schema":1,"time":1136214245.123456789,"level":"INFO","message":"hi"}` + "\n",
+	},
+	{
+		Name: "tag-values",
+		Entry: Entry{
+			Level: LevelWarning,
+			Tags: map[string]interface{}{
+				"err":     errors.New("timed out"),
+				"elapsed": 90 * time.Second,
+				"ratio":   math.NaN(),
+				"items":   []int{1, 2},
+			},
+		},
+		Expected: `{"schema":1,"level":"WARN","message":"","tags":{"elapsed":"1m30s","err":"timed out","errType":"*errors.errorString",` +
+			`"items":[1,2],"ratio":"NaN"}}` + "\n",
+	},
+	{
+		Name:    "error-stack",
+		Encoder: JSONEncoder{ErrorStack: true},
+		Entry: Entry{
+			Level: LevelError,
+			Tags:  map[string]interface{}{"err": stackError{msg: "oops"}},
+		},
+		Expected: `{"schema":1,"level":"ERROR","message":"","tags":{"err":"oops","errStack":"oops\nmain.main\n\t/app/main.go:12",` +
+			`"errType":"loggy.stackError"}}` + "\n",
+	},
+}
+
+func TestJSONEncoder_Encode(t *testing.T) {
+	code := ErrorCode{Code: "E4321", DocsURL: "https://example.com/runbooks/E4321"}
+	RegisterErrorCode(code)
+	defer func() {
+		errorCodes.mux.Lock()
+		delete(errorCodes.codes, code.Code)
+		errorCodes.mux.Unlock()
+	}()
+
+	for _, testCase := range jsonEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := testCase.Encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
+
+func TestJSONEncoder_Encode_Schema(t *testing.T) {
+	encoded, err := JSONEncoder{}.Encode(Entry{Message: "hi"})
+	assert.Nil(t, err)
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, float64(SchemaVersion), decoded["schema"])
+}
+
+func TestOptions_Encoder(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:                "app",
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		Prefix:              "~~~",
+		FlattenTags:         true,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Color:               true,
+		Encoder:             JSONEncoder{},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "user", struct{ ID int }{42})
+
+	assert.Nil(t, l.Infof(ctx, "%d items", 2))
+	assert.Nil(t, l.Debug(ctx, "ignored"))
+	assert.Equal(t, `{"schema":1,"level":"INFO","logger":"app","prefix":"~~~","message":"2 items","tags":{"user.ID":42}}`+"\n", stdout.String())
+}
+
+var logfmtEncoderTestCases = []struct {
+	Name     string
+	Encoder  LogfmtEncoder
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: `level=OUT msg=""` + "\n",
+	},
+	{
+		Name: "every-field",
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelError,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: `disk "full"`,
+			Tags:    map[string]interface{}{"user": "bob", "attempt": 3},
+			Stack:   "main.main\n\tmain.go:1\n",
+		},
+		Expected: `time=2006-01-02T15:04:05.123456789Z level=ERROR logger=app.db caller=main.main code=E4321 ` +
+			`docs=https://example.com/runbooks/E4321 prefix=~~~ msg="disk \"full\"" attempt=3 user=bob ` +
+			`stack="main.main\n\tmain.go:1\n"` + "\n",
+	},
+	{
+		Name:     "timestamp-format",
+		Encoder:  LogfmtEncoder{TimestampFormat: time.RFC3339},
+		Entry:    Entry{Time: loggyTestTime, Level: LevelInfo, Message: "hi"},
+		Expected: "time=2006-01-02T15:04:05Z level=INFO msg=hi\n",
+	},
+	{
+		Name: "tag-values",
+		Entry: Entry{
+			Level: LevelWarning,
+			Tags: map[string]interface{}{
+				"err":       errors.New("timed out"),
+				"elapsed":   90 * time.Second,
+				"empty":     "",
+				"key=value": "a=b",
+			},
+		},
+		Expected: `level=WARN msg="" elapsed=1m30s empty="" err="timed out" errType=*errors.errorString key_value="a=b"` + "\n",
+	},
+	{
+		Name:    "error-stack",
+		Encoder: LogfmtEncoder{ErrorStack: true},
+		Entry: Entry{
+			Level: LevelError,
+			Tags:  map[string]interface{}{"err": stackError{msg: "oops"}},
+		},
+		Expected: `level=ERROR msg="" err=oops errStack="oops\nmain.main\n\t/app/main.go:12" errType=loggy.stackError` + "\n",
+	},
+}
+
+func TestLogfmtEncoder_Encode(t *testing.T) {
+	code := ErrorCode{Code: "E4321", DocsURL: "https://example.com/runbooks/E4321"}
+	RegisterErrorCode(code)
+	defer func() {
+		errorCodes.mux.Lock()
+		delete(errorCodes.codes, code.Code)
+		errorCodes.mux.Unlock()
+	}()
+
+	for _, testCase := range logfmtEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := testCase.Encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
+
+func TestTextEncoder_Encode(t *testing.T) {
+	entry := Entry{
+		Time:    loggyTestTime,
+		Level:   LevelError,
+		Logger:  "app.db",
+		Caller:  "main.main",
+		Code:    "E4321",
+		Prefix:  "~~~",
+		Message: "oops",
+		Tags:    map[string]interface{}{"user": "bob", "err": errors.New("timed out")},
+	}
+
+	encoded, err := NewTextEncoder(Options{TimestampFormat: time.RFC3339}).Encode(entry)
+	assert.Nil(t, err)
+	assert.Equal(t, "2006-01-02T15:04:05Z ERROR app.db main.main [err:timed out (*errors.errorString), user:bob] [E4321] ~~~ oops\n", string(encoded))
+
+	encoded, err = TextEncoder{}.Encode(Entry{Level: LevelInfo, Message: "zero value"})
+	assert.Nil(t, err)
+	assert.Equal(t, "INFO zero value\n", string(encoded))
+}
+
+// csvEncoder is an example of a custom Encoder.
+type csvEncoder struct{}
+
+func (csvEncoder) Encode(entry Entry) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s,%s,%d\n", LevelNames[entry.Level], entry.Message, len(entry.Tags))), nil
+}
+
+// failingEncoder fails to encode every entry.
+type failingEncoder struct{}
+
+func (failingEncoder) Encode(entry Entry) ([]byte, error) {
+	return nil, errors.New("unsupported")
+}
+
+func TestOptions_Encoder_Custom(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Threshold: LevelInfo,
+		Encoder:   csvEncoder{},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "user", "bob")
+
+	assert.Nil(t, l.Info(ctx, "hello"))
+	assert.Equal(t, "INFO,hello,1\n", stdout.String())
+
+	l.Reload(Options{Out: stdout, Threshold: LevelInfo, Encoder: failingEncoder{}})
+	stdout.Reset()
+	assert.EqualError(t, l.Info(ctx, "hello"), "unsupported")
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,56 @@
+package loggy
+
+import "time"
+
+// Entry is a single message, as it passes through the logger on its way to
+// being formatted.
+type Entry struct {
+	// The time the message was logged.
+	Time time.Time
+	// The severity of the message.
+	Level Level
+	// The logger's full name, if it has one. See Options.Name and Manager.
+	Logger string
+	// The name of the function that logged the message, if enabled.
+	Caller string
+	// The tags from the context, including Options.ContextValues and
+	// Options.DynamicFields.
+	Tags map[string]interface{}
+	// The Fields passed with this message alone, which replace any tags of the
+	// same name. See AllTags.
+	Fields map[string]interface{}
+	// The error code the message was logged with, if any. See Code.
+	Code string
+	// The logger's Options.Prefix.
+	Prefix string
+	// The user-formatted message.
+	Message string
+	// The stack trace of the calling goroutine, if the message is at least as
+	// severe as Options.StacktraceLevel.
+	Stack string
+}
+
+// Severity returns the severity of the entry's level.
+func (e Entry) Severity() Severity {
+	return SeverityOf(e.Level)
+}
+
+// AllTags returns the tags merged with the fields, which take precedence, as
+// they're output. The entry isn't modified.
+func (e Entry) AllTags() map[string]interface{} {
+	if len(e.Fields) == 0 {
+		return e.Tags
+	}
+	if len(e.Tags) == 0 {
+		return e.Fields
+	}
+
+	merged := make(map[string]interface{}, len(e.Tags)+len(e.Fields))
+	for name, value := range e.Tags {
+		merged[name] = value
+	}
+	for name, value := range e.Fields {
+		merged[name] = value
+	}
+	return merged
+}
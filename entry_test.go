@@ -0,0 +1,87 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var allTagsTestCases = []struct {
+	Name     string
+	Entry    Entry
+	Expected map[string]interface{}
+}{
+	{
+		Name:     "empty",
+		Entry:    Entry{},
+		Expected: nil,
+	},
+	{
+		Name:     "tags",
+		Entry:    Entry{Tags: map[string]interface{}{"user": "bob"}},
+		Expected: map[string]interface{}{"user": "bob"},
+	},
+	{
+		Name:     "fields",
+		Entry:    Entry{Fields: map[string]interface{}{"attempt": 2}},
+		Expected: map[string]interface{}{"attempt": 2},
+	},
+	{
+		Name: "fields-replace-tags",
+		Entry: Entry{
+			Tags:   map[string]interface{}{"user": "bob", "attempt": 1},
+			Fields: map[string]interface{}{"attempt": 2},
+		},
+		Expected: map[string]interface{}{"user": "bob", "attempt": 2},
+	},
+}
+
+func TestEntry_AllTags(t *testing.T) {
+	for _, testCase := range allTagsTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, testCase.Entry.AllTags())
+		})
+	}
+}
+
+func TestEntry_Fields(t *testing.T) {
+	var entries []Entry
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Processors: []Processor{
+			func(ctx context.Context, entry *Entry) bool {
+				entries = append(entries, *entry)
+				return true
+			},
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "id", 1)
+	db := l.Delegate(Delegation{Threshold: LevelInfo, RenameTags: map[string]string{"id": "db.id"}})
+
+	assert.Nil(t, db.Info(ctx, "query", Fields(map[string]interface{}{"id": 2, "rows": 3})))
+	assert.Equal(t, map[string]interface{}{"db.id": 1}, entries[0].Tags)
+	assert.Equal(t, map[string]interface{}{"db.id": 2, "rows": 3}, entries[0].Fields)
+	assert.Equal(t, "INFO [db.id:2, rows:3] query\n", stdout.String())
+}
+
+func TestEntry_Fields_DisableTags(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		DisableTags:         true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "id", 1)
+
+	assert.Nil(t, l.Info(ctx, "query", Fields(map[string]interface{}{"rows": 3})))
+	assert.Equal(t, "INFO [rows:3] query\n", stdout.String())
+}
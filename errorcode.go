@@ -0,0 +1,52 @@
+package loggy
+
+import (
+	"sync"
+)
+
+// ErrorCode documents a known error, so that messages logged with its Code can
+// be linked to a runbook or other documentation.
+type ErrorCode struct {
+	// The identifier output with each message, e.g. "E1234".
+	Code string
+	// A short description of the error.
+	Description string
+	// An optional link to documentation on handling the error.
+	DocsURL string
+}
+
+var errorCodes = struct {
+	mux   sync.RWMutex
+	codes map[string]ErrorCode
+}{
+	codes: make(map[string]ErrorCode),
+}
+
+// RegisterErrorCode adds code to the registry of known error codes, replacing
+// any existing code with the same identifier. It's typically called from an
+// init function.
+func RegisterErrorCode(code ErrorCode) {
+	errorCodes.mux.Lock()
+	defer errorCodes.mux.Unlock()
+
+	errorCodes.codes[code.Code] = code
+}
+
+// LookupErrorCode returns the registered error code with the given identifier.
+func LookupErrorCode(code string) (ErrorCode, bool) {
+	errorCodes.mux.RLock()
+	defer errorCodes.mux.RUnlock()
+
+	registered, ok := errorCodes.codes[code]
+	return registered, ok
+}
+
+// Code labels the message with an error code, e.g. "E1234", which is output in
+// brackets before the message. The code does not need to be registered, but
+// registering it with RegisterErrorCode makes its documentation available to
+// structured output.
+func Code(code string) LogOption {
+	return func(o *logOptions) {
+		o.code = code
+	}
+}
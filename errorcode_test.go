@@ -0,0 +1,45 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegisterErrorCode(t *testing.T) {
+	code := ErrorCode{
+		Code:        "E1234",
+		Description: "payment provider unavailable",
+		DocsURL:     "https://example.com/runbooks/E1234",
+	}
+	RegisterErrorCode(code)
+	defer func() {
+		errorCodes.mux.Lock()
+		delete(errorCodes.codes, code.Code)
+		errorCodes.mux.Unlock()
+	}()
+
+	registered, ok := LookupErrorCode("E1234")
+	assert.True(t, ok)
+	assert.Equal(t, code, registered)
+
+	_, ok = LookupErrorCode("E0000")
+	assert.False(t, ok)
+}
+
+func TestCode(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:                 stderr,
+		Threshold:           LevelInfo,
+		Prefix:              "~~~",
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "order", 7)
+
+	assert.Nil(t, l.Critical(ctx, "payment failed", Code("E1234")))
+	assert.Equal(t, "CRIT [order:7] [E1234] ~~~ payment failed\n", stderr.String())
+}
@@ -0,0 +1,35 @@
+package loggy
+
+import (
+	"fmt"
+)
+
+// ErrorFields expands an error into structured fields: name holds the error
+// message and name+"Type" holds the concrete error type. When includeStack is
+// true, name+"Stack" holds the error's verbose "%+v" formatting, which error
+// packages conventionally use to print stack traces, if it adds anything to the
+// message. For example, a name of "error" yields the keys "error", "errorType",
+// and "errorStack".
+func ErrorFields(name string, err error, includeStack bool) map[string]interface{} {
+	if err == nil {
+		return map[string]interface{}{name: nil}
+	}
+
+	fields := map[string]interface{}{
+		name:          err.Error(),
+		name + "Type": fmt.Sprintf("%T", err),
+	}
+	if includeStack {
+		if verbose := fmt.Sprintf("%+v", err); verbose != err.Error() {
+			fields[name+"Stack"] = verbose
+		}
+	}
+
+	return fields
+}
+
+// formatError renders an error for text output, as its message followed by its
+// concrete type, e.g. "file not found (*fs.PathError)".
+func formatError(err error) string {
+	return fmt.Sprintf("%s (%T)", err.Error(), err)
+}
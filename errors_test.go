@@ -0,0 +1,93 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// stackError mimics errors that print a stack trace with the "%+v" verb.
+type stackError struct {
+	msg string
+}
+
+func (e stackError) Error() string {
+	return e.msg
+}
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\nmain.main\n\t/app/main.go:12", e.msg)
+		return
+	}
+	fmt.Fprint(s, e.msg)
+}
+
+var errorFieldsTestCases = []struct {
+	Name         string
+	Err          error
+	IncludeStack bool
+	Expected     map[string]interface{}
+}{
+	{
+		Name: "nil",
+		Err:  nil,
+		Expected: map[string]interface{}{
+			"error": nil,
+		},
+	},
+	{
+		Name:         "no-stack-available",
+		Err:          errors.New("oops"),
+		IncludeStack: true,
+		Expected: map[string]interface{}{
+			"error":     "oops",
+			"errorType": "*errors.errorString",
+		},
+	},
+	{
+		Name:         "stack-excluded",
+		Err:          stackError{msg: "oops"},
+		IncludeStack: false,
+		Expected: map[string]interface{}{
+			"error":     "oops",
+			"errorType": "loggy.stackError",
+		},
+	},
+	{
+		Name:         "stack-included",
+		Err:          stackError{msg: "oops"},
+		IncludeStack: true,
+		Expected: map[string]interface{}{
+			"error":      "oops",
+			"errorType":  "loggy.stackError",
+			"errorStack": "oops\nmain.main\n\t/app/main.go:12",
+		},
+	},
+}
+
+func TestErrorFields(t *testing.T) {
+	for _, testCase := range errorFieldsTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, ErrorFields("error", testCase.Err, testCase.IncludeStack))
+		})
+	}
+}
+
+func TestLogger_Log_ErrorTags(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:                 stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "error", fmt.Errorf("wrapped: %w", errors.New("oops")))
+
+	assert.Nil(t, l.Critical(ctx, "failed"))
+	assert.Equal(t, "CRIT [error:wrapped: oops (*fmt.wrapError)] failed\n", stderr.String())
+}
@@ -0,0 +1,32 @@
+package loggy
+
+import (
+	"errors"
+)
+
+// ErrNoEventLog is returned by NewEventLogWriter on platforms other than
+// Windows.
+var ErrNoEventLog = errors.New("Windows Event Log is not available")
+
+// EventType is the type of a Windows Event Log event, which Event Viewer
+// displays as its level.
+type EventType uint16
+
+const (
+	EventError       EventType = 0x0001
+	EventWarning     EventType = 0x0002
+	EventInformation EventType = 0x0004
+)
+
+// EventLogTypes maps each level to the type of the events written for it by
+// EventLogWriter. The Event Log has no critical or debug types, so Critical
+// messages are errors, and Std, Debug, and Trace messages are information.
+var EventLogTypes = map[Level]EventType{
+	LevelStd:      EventInformation,
+	LevelCritical: EventError,
+	LevelError:    EventError,
+	LevelWarning:  EventWarning,
+	LevelInfo:     EventInformation,
+	LevelDebug:    EventInformation,
+	LevelTrace:    EventInformation,
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package loggy
+
+import (
+	"io"
+)
+
+var _ io.WriteCloser = &EventLogWriter{}
+
+// EventLogWriter writes to the Windows Event Log, which is only available on
+// Windows.
+type EventLogWriter struct{}
+
+// NewEventLogWriter returns ErrNoEventLog, as the Windows Event Log is only
+// available on Windows.
+func NewEventLogWriter(source string, eventID uint32) (*EventLogWriter, error) {
+	return nil, ErrNoEventLog
+}
+
+// Write returns ErrNoEventLog.
+func (w *EventLogWriter) Write(p []byte) (int, error) {
+	return 0, ErrNoEventLog
+}
+
+// LevelOutputs returns nil.
+func (w *EventLogWriter) LevelOutputs() map[Level]io.Writer {
+	return nil
+}
+
+// Close has no effect.
+func (w *EventLogWriter) Close() error {
+	return nil
+}
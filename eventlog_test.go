@@ -0,0 +1,15 @@
+package loggy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventLogTypes(t *testing.T) {
+	for level := LevelStd; level <= LevelTrace; level++ {
+		assert.Contains(t, EventLogTypes, level, level.String())
+	}
+	assert.Equal(t, EventError, EventLogTypes[LevelCritical])
+	assert.Equal(t, EventWarning, EventLogTypes[LevelWarning])
+	assert.Equal(t, EventInformation, EventLogTypes[LevelDebug])
+}
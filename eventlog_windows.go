@@ -0,0 +1,130 @@
+package loggy
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// maxEventLength is the maximum length of an event's message, in characters.
+const maxEventLength = 31839
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSource   = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEvent           = advapi32.NewProc("ReportEventW")
+)
+
+var _ io.WriteCloser = &EventLogWriter{}
+
+// EventLogWriter writes each message to the Windows Event Log as a single event,
+// e.g. for a program running as a Windows service. Writes to the EventLogWriter
+// itself are information events; LevelOutputs returns writers for the event
+// type of each level. It is only available on Windows; on other platforms,
+// NewEventLogWriter returns ErrNoEventLog. It is safe for concurrent use.
+//
+// Event Viewer only displays the messages of sources that have been registered
+// with a message file, e.g. by running, as an administrator:
+//
+//	New-EventLog -LogName Application -Source MyService
+type EventLogWriter struct {
+	eventID uint32
+	mux     sync.RWMutex
+	handle  syscall.Handle
+	closed  bool
+}
+
+// NewEventLogWriter opens the Event Log for the source, e.g. the name of the
+// service, writing each event with the provided event ID. For example:
+//
+//	w, err := loggy.NewEventLogWriter("MyService", 1)
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, LevelOutputs: w.LevelOutputs(), DisableTimestamps: true})
+func NewEventLogWriter(source string, eventID uint32) (*EventLogWriter, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := procRegisterEventSource.Call(0, uintptr(unsafe.Pointer(name)))
+	if handle == 0 {
+		return nil, err
+	}
+	return &EventLogWriter{
+		eventID: eventID,
+		handle:  syscall.Handle(handle),
+	}, nil
+}
+
+// Write writes p as an information event.
+func (w *EventLogWriter) Write(p []byte) (int, error) {
+	return w.report(EventInformation, p)
+}
+
+// LevelOutputs returns a writer for each level, for Options.LevelOutputs, which
+// writes events of the type mapped by EventLogTypes.
+func (w *EventLogWriter) LevelOutputs() map[Level]io.Writer {
+	outputs := make(map[Level]io.Writer, len(EventLogTypes))
+	for level, eventType := range EventLogTypes {
+		outputs[level] = eventLogLevel{writer: w, eventType: eventType}
+	}
+	return outputs
+}
+
+// Close closes the Event Log. Calling Close more than once has no effect.
+func (w *EventLogWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if r, _, err := procDeregisterEventSource.Call(uintptr(w.handle)); r == 0 {
+		return err
+	}
+	return nil
+}
+
+// report writes p, without its trailing newline, as an event of the type.
+func (w *EventLogWriter) report(eventType EventType, p []byte) (int, error) {
+	w.mux.RLock()
+	defer w.mux.RUnlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	// The message can't contain NULs, which would end it early.
+	message := string(bytes.ReplaceAll(bytes.TrimSuffix(p, []byte{'\n'}), []byte{0}, []byte{' '}))
+	text := syscall.StringToUTF16(message)
+	if len(text) > maxEventLength+1 {
+		text = append(text[:maxEventLength], 0)
+	}
+	strings := []*uint16{&text[0]}
+	r, _, err := procReportEvent.Call(
+		uintptr(w.handle),
+		uintptr(eventType),
+		0,
+		uintptr(w.eventID),
+		0,
+		1,
+		0,
+		uintptr(unsafe.Pointer(&strings[0])),
+		0,
+	)
+	if r == 0 {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// eventLogLevel writes events of a single type.
+type eventLogLevel struct {
+	writer    *EventLogWriter
+	eventType EventType
+}
+
+func (l eventLogLevel) Write(p []byte) (int, error) {
+	return l.writer.report(l.eventType, p)
+}
@@ -0,0 +1,32 @@
+package loggy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventLogWriter(t *testing.T) {
+	w, err := NewEventLogWriter("loggy-test", 1)
+	assert.Nil(t, err)
+	outputs := w.LevelOutputs()
+	assert.Len(t, outputs, len(EventLogTypes))
+
+	l, ctx := New(context.Background(), Options{
+		Out:               w,
+		Err:               w,
+		LevelOutputs:      outputs,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	})
+	assert.Nil(t, l.Error(ctx, "oops"))
+	assert.Nil(t, l.Warning(ctx, "low disk"))
+	assert.Nil(t, l.Info(ctx, "started"))
+
+	assert.Nil(t, l.Close())
+	assert.Nil(t, w.Close())
+	_, err = w.Write([]byte("late\n"))
+	assert.Equal(t, ErrClosed, err)
+	_, err = outputs[LevelError].Write([]byte("late\n"))
+	assert.Equal(t, ErrClosed, err)
+}
@@ -0,0 +1,111 @@
+package loggy
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Flusher is anything with buffered data that can be committed on demand, e.g.
+// a Manager, CompressWriter, or bufio.Writer.
+type Flusher interface {
+	Flush() error
+}
+
+// osExit is replaced in tests.
+var osExit = os.Exit
+
+var exitFlushers = struct {
+	mux      sync.Mutex
+	next     int
+	ids      []int
+	flushers map[int]Flusher
+}{
+	flushers: make(map[int]Flusher),
+}
+
+// FlushOnExit registers f to be flushed before the process exits via Exit, or
+// via one of the signals handled by FlushOnSignals, so that the final buffered
+// messages aren't lost. Flushers run in the reverse order they were
+// registered, like deferred calls. The returned function unregisters f.
+func FlushOnExit(f Flusher) (unregister func()) {
+	exitFlushers.mux.Lock()
+	defer exitFlushers.mux.Unlock()
+
+	id := exitFlushers.next
+	exitFlushers.next++
+	exitFlushers.ids = append(exitFlushers.ids, id)
+	exitFlushers.flushers[id] = f
+
+	return func() {
+		exitFlushers.mux.Lock()
+		defer exitFlushers.mux.Unlock()
+
+		delete(exitFlushers.flushers, id)
+		for i, registered := range exitFlushers.ids {
+			if registered == id {
+				exitFlushers.ids = append(exitFlushers.ids[:i], exitFlushers.ids[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Exit flushes everything registered with FlushOnExit, then exits the process
+// with the given status code. Use it in place of os.Exit.
+func Exit(code int) {
+	flushAll()
+	osExit(code)
+}
+
+// FlushOnSignals handles the given signals, or SIGINT and SIGTERM if none are
+// provided, by flushing everything registered with FlushOnExit and exiting with
+// the conventional status code of 128 plus the signal number. The signals are
+// no longer handled while flushing, so sending one again, e.g. pressing Ctrl+C
+// twice, exits immediately if a flush hangs. The returned function stops
+// handling the signals.
+func FlushOnSignals(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+
+	go func() {
+		select {
+		case sig := <-c:
+			signal.Stop(c)
+			exitOnSignal(sig)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+func exitOnSignal(sig os.Signal) {
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	Exit(code)
+}
+
+// flushAll flushes every registered Flusher, most recently registered first.
+// Errors are ignored, since the process is about to exit regardless.
+func flushAll() {
+	exitFlushers.mux.Lock()
+	defer exitFlushers.mux.Unlock()
+
+	for i := len(exitFlushers.ids) - 1; i >= 0; i-- {
+		_ = exitFlushers.flushers[exitFlushers.ids[i]].Flush()
+	}
+}
@@ -0,0 +1,60 @@
+package loggy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"syscall"
+	"testing"
+)
+
+// flushRecorder records the order it was flushed in, by name.
+type flushRecorder struct {
+	name    string
+	flushed *[]string
+}
+
+func (f *flushRecorder) Flush() error {
+	*f.flushed = append(*f.flushed, f.name)
+	return nil
+}
+
+func stubExit(t *testing.T) *int {
+	code := -1
+	exit := osExit
+	osExit = func(c int) {
+		code = c
+	}
+	t.Cleanup(func() {
+		osExit = exit
+	})
+	return &code
+}
+
+func TestExit(t *testing.T) {
+	code := stubExit(t)
+	var flushed []string
+	defer FlushOnExit(&flushRecorder{name: "first", flushed: &flushed})()
+	unregister := FlushOnExit(&flushRecorder{name: "removed", flushed: &flushed})
+	defer FlushOnExit(&flushRecorder{name: "last", flushed: &flushed})()
+	unregister()
+
+	Exit(3)
+	assert.Equal(t, 3, *code)
+	assert.Equal(t, []string{"last", "first"}, flushed)
+}
+
+func TestExitOnSignal(t *testing.T) {
+	code := stubExit(t)
+	var flushed []string
+	defer FlushOnExit(&flushRecorder{name: "manager", flushed: &flushed})()
+
+	exitOnSignal(syscall.SIGTERM)
+	assert.Equal(t, 128+int(syscall.SIGTERM), *code)
+	assert.Equal(t, []string{"manager"}, flushed)
+}
+
+func TestFlushOnSignals(t *testing.T) {
+	stop := FlushOnSignals()
+	stop()
+	// Stopping more than once has no effect.
+	stop()
+}
@@ -0,0 +1,434 @@
+package loggy
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the layout of the timestamp in the names of rotated files,
+// chosen so that the names sort chronologically.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var _ io.WriteCloser = &FileWriter{}
+
+// Rotation is a schedule for rotating a FileWriter's file.
+type Rotation int
+
+const (
+	// RotateNever only rotates the file by size.
+	RotateNever Rotation = iota
+	// RotateHourly starts a new file at the start of each hour.
+	RotateHourly
+	// RotateDaily starts a new file at midnight, local time.
+	RotateDaily
+)
+
+// periodStart returns the start of the period containing t.
+func (r Rotation) periodStart(t time.Time) time.Time {
+	switch r {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// next returns the start of the period after the one starting at start.
+func (r Rotation) next(start time.Time) time.Time {
+	switch r {
+	case RotateHourly:
+		return start.Add(time.Hour)
+	case RotateDaily:
+		return start.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// FileOptions configures a FileWriter.
+type FileOptions struct {
+	// The size, in bytes, that the file may grow to before it's rotated. A write
+	// that would take the file past this size goes to a new file instead. A
+	// MaxSize <= 0 disables rotation by size.
+	MaxSize int64
+	// Optionally rotates the file on a schedule, as well as by size, e.g.
+	// RotateDaily for one file per day. A file rotated on schedule is named with
+	// the start of its period, e.g. app-2006-01-02T00-00-00.000.log.
+	Rotation Rotation
+	// The number of rotated files to keep, deleting the oldest beyond this. A
+	// MaxBackups <= 0 keeps every rotated file.
+	MaxBackups int
+	// The maximum age of rotated files, going by the time in their names,
+	// deleting any older. A MaxAge <= 0 keeps rotated files regardless of age.
+	MaxAge time.Duration
+	// Optionally compresses rotated files with the codec, e.g. GzipCodec{},
+	// adding its extension, e.g. ".gz". Files are compressed, then pruned, in the
+	// background, so that rotation doesn't hold up logging; Flush and Close wait
+	// for it to finish. If nil, or IdentityCodec, files aren't compressed.
+	Codec Codec
+	// The permissions of new files. If zero, 0644 is used.
+	Mode os.FileMode
+}
+
+// FileWriter appends to a log file, rotating it once it reaches a maximum size,
+// or on a schedule. The rotated file is renamed with the time of the rotation,
+// e.g. app.log becomes app-2006-01-02T15-04-05.000.log, and a new file is
+// started at the original path. It is safe for concurrent use.
+type FileWriter struct {
+	path    string
+	options FileOptions
+	// The extension added to compressed files, or "" if they aren't compressed.
+	ext  string
+	mux  sync.Mutex
+	file *os.File
+	// The current size of the file.
+	size int64
+	// The start of the scheduled period that the file covers, if scheduled.
+	period time.Time
+	closed bool
+	now    func() time.Time
+	// Tracks the compression of rotated files in the background, which is done
+	// one file at a time, recording the first error until it's reported.
+	background    sync.WaitGroup
+	backgroundMux sync.Mutex
+	backgroundErr error
+}
+
+// NewFileWriter opens the file at path for appending, creating it and its
+// directory if needed.
+func NewFileWriter(path string, options FileOptions) (*FileWriter, error) {
+	if options.Mode == 0 {
+		options.Mode = 0644
+	}
+	w := &FileWriter{
+		path:    path,
+		options: options,
+		ext:     codecExtension(options.Codec),
+		now:     time.Now,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the file, first rotating the file if its scheduled period
+// has ended, or if p would take it past the maximum size. A single write larger
+// than the maximum size is written to a file of its own. An empty file isn't
+// rotated.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.options.Rotation != RotateNever {
+		if now := w.now(); !now.Before(w.options.Rotation.next(w.period)) {
+			if w.size > 0 {
+				if err := w.rotate(w.period); err != nil {
+					return 0, err
+				}
+			}
+			w.period = w.options.Rotation.periodStart(now)
+		}
+	}
+	if w.options.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.options.MaxSize {
+		if err := w.rotate(w.now()); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new file, regardless of the size of the current one, e.g. in
+// response to SIGHUP.
+func (w *FileWriter) Rotate() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+	return w.rotate(w.now())
+}
+
+// Flush commits the file's contents to stable storage, then waits for any
+// rotated files to be compressed, returning the first error encountered by
+// either.
+func (w *FileWriter) Flush() error {
+	w.mux.Lock()
+	var err error
+	if !w.closed {
+		err = w.file.Sync()
+	}
+	w.mux.Unlock()
+
+	if backgroundErr := w.wait(); err == nil {
+		err = backgroundErr
+	}
+	return err
+}
+
+// Close closes the file, then waits for any rotated files to be compressed.
+// Calling Close more than once has no effect.
+func (w *FileWriter) Close() error {
+	w.mux.Lock()
+	if w.closed {
+		w.mux.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.file.Close()
+	w.mux.Unlock()
+
+	if backgroundErr := w.wait(); err == nil {
+		err = backgroundErr
+	}
+	return err
+}
+
+// wait waits for the background compression, returning, and clearing, the first
+// error it encountered.
+func (w *FileWriter) wait() error {
+	w.background.Wait()
+
+	w.backgroundMux.Lock()
+	defer w.backgroundMux.Unlock()
+
+	err := w.backgroundErr
+	w.backgroundErr = nil
+	return err
+}
+
+// open opens the file at the writer's path for appending.
+func (w *FileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.options.Mode)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	if w.options.Rotation != RotateNever {
+		// An existing file covers the period in which it was last written.
+		since := w.now()
+		if w.size > 0 {
+			since = info.ModTime().In(since.Location())
+		}
+		w.period = w.options.Rotation.periodStart(since)
+	}
+	return nil
+}
+
+// rotate closes the current file, renames it to a backup, named with the
+// provided time, opens a new file, then compresses and prunes the backups, as
+// configured. The file is closed before it's renamed, as Windows won't rename
+// an open file. If it can't be renamed, or the new file can't be opened, the
+// current one is reopened, under its original name.
+func (w *FileWriter) rotate(at time.Time) error {
+	backup := w.backupName(at)
+	err := w.file.Close()
+	if renameErr := os.Rename(w.path, backup); renameErr != nil {
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return renameErr
+	}
+	if openErr := w.open(); openErr != nil {
+		if os.Rename(backup, w.path) == nil {
+			_ = w.open()
+		}
+		return openErr
+	}
+
+	if w.ext != "" {
+		w.background.Add(1)
+		go func() {
+			defer w.background.Done()
+			w.backgroundMux.Lock()
+			defer w.backgroundMux.Unlock()
+
+			if compressErr := compressFile(backup, w.options.Codec, w.ext); compressErr != nil {
+				compressErr = fmt.Errorf("compressing %s: %w", backup, compressErr)
+				if w.backgroundErr == nil {
+					w.backgroundErr = compressErr
+				}
+				return
+			}
+			if pruneErr := w.prune(); pruneErr != nil && w.backgroundErr == nil {
+				w.backgroundErr = pruneErr
+			}
+		}()
+		return err
+	}
+	if pruneErr := w.prune(); err == nil {
+		err = pruneErr
+	}
+	return err
+}
+
+// backupName returns an unused name for a file rotated at the provided time. If
+// other files were rotated at the same time, it's numbered after the last of
+// them, rather than reusing a number freed by pruning, so that the backups sort
+// in the order they were rotated.
+func (w *FileWriter) backupName(at time.Time) string {
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext)
+	stamp := at.Format(backupTimeFormat)
+	i := 0
+	backups, _ := w.backups(at.Location())
+	for _, backup := range backups {
+		if backup.time.Format(backupTimeFormat) == stamp && backup.index >= i {
+			i = backup.index + 1
+		}
+	}
+	for ; ; i++ {
+		name := fmt.Sprintf("%s-%s%s", base, stamp, ext)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%s.%d%s", base, stamp, i, ext)
+		}
+		if !fileExists(name) && (w.ext == "" || !fileExists(name+w.ext)) {
+			return name
+		}
+	}
+}
+
+// backup is a rotated file.
+type backup struct {
+	path string
+	// The time in the file's name, and the number added to it if another file
+	// was rotated at the same time, or 0.
+	time  time.Time
+	index int
+}
+
+// backups lists the rotated files, oldest first, reading the times in their
+// names in the location.
+func (w *FileWriter) backups(location *time.Location) ([]backup, error) {
+	dir := filepath.Dir(w.path)
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backup
+	for _, file := range files {
+		name := file.Name()
+		if w.ext != "" {
+			name = strings.TrimSuffix(name, w.ext)
+		}
+		if file.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		at, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], location)
+		if err != nil {
+			continue
+		}
+		var index int
+		if suffix := stamp[len(backupTimeFormat):]; suffix != "" {
+			if index, err = strconv.Atoi(strings.TrimPrefix(suffix, ".")); err != nil || index <= 0 || suffix[0] != '.' {
+				continue
+			}
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, file.Name()), time: at, index: index})
+	}
+	// Sorting by path would put app-T.10.log before app-T.2.log.
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.Before(backups[j].time)
+		}
+		return backups[i].index < backups[j].index
+	})
+	return backups, nil
+}
+
+// prune deletes the oldest backups beyond MaxBackups, and those older than
+// MaxAge.
+func (w *FileWriter) prune() error {
+	if w.options.MaxBackups <= 0 && w.options.MaxAge <= 0 {
+		return nil
+	}
+	now := w.now()
+	backups, err := w.backups(now.Location())
+	if err != nil {
+		return err
+	}
+	cutoff := now.Add(-w.options.MaxAge)
+	for len(backups) > 0 {
+		tooMany := w.options.MaxBackups > 0 && len(backups) > w.options.MaxBackups
+		tooOld := w.options.MaxAge > 0 && backups[0].time.Before(cutoff)
+		if !tooMany && !tooOld {
+			break
+		}
+		if err := os.Remove(backups[0].path); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// compressFile replaces the file at path with a copy compressed by the codec,
+// adding ext to its name.
+func compressFile(path string, codec Codec, ext string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path+ext, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	compressor, err := codec.NewCompressor(out)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	if _, err := io.Copy(compressor, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := compressor.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,228 @@
+package loggy
+
+import (
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestFileWriter creates a FileWriter in a temporary directory, whose clock
+// advances by a second with each rotation.
+func newTestFileWriter(t *testing.T, options FileOptions) (*FileWriter, string) {
+	dir := t.TempDir()
+	w, err := NewFileWriter(filepath.Join(dir, "app.log"), options)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	now := loggyTestTime
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return w, dir
+}
+
+// listDir returns the names of the files in dir, sorted.
+func listDir(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func TestFileWriter_MaxSize(t *testing.T) {
+	w, dir := newTestFileWriter(t, FileOptions{MaxSize: 10})
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "a very long line\n"} {
+		n, err := w.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Equal(t, len(line), n)
+	}
+
+	stamp := func(seconds int) string {
+		return loggyTestTime.Add(time.Duration(seconds) * time.Second).Format(backupTimeFormat)
+	}
+	assert.Equal(t, []string{
+		"app-" + stamp(1) + ".log",
+		"app-" + stamp(2) + ".log",
+		"app-" + stamp(3) + ".log",
+		"app.log",
+	}, listDir(t, dir))
+	assert.Equal(t, "first\n", readFile(t, filepath.Join(dir, "app-"+stamp(1)+".log")))
+	assert.Equal(t, "third\n", readFile(t, filepath.Join(dir, "app-"+stamp(3)+".log")))
+	assert.Equal(t, "a very long line\n", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestFileWriter_MaxBackups(t *testing.T) {
+	w, dir := newTestFileWriter(t, FileOptions{MaxBackups: 2})
+
+	for _, line := range []string{"1\n", "2\n", "3\n", "4\n"} {
+		_, err := w.Write([]byte(line))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Rotate())
+	}
+
+	names := listDir(t, dir)
+	assert.Len(t, names, 3)
+	assert.Equal(t, "3\n", readFile(t, filepath.Join(dir, names[0])))
+	assert.Equal(t, "4\n", readFile(t, filepath.Join(dir, names[1])))
+	assert.Equal(t, "app.log", names[2])
+}
+
+func TestFileWriter_Compress(t *testing.T) {
+	w, dir := newTestFileWriter(t, FileOptions{Codec: GzipCodec{}})
+
+	_, err := w.Write([]byte("compressed\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Rotate())
+	_, err = w.Write([]byte("kept\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Flush())
+
+	backup := filepath.Join(dir, "app-"+loggyTestTime.Add(time.Second).Format(backupTimeFormat)+".log.gz")
+	assert.Equal(t, []string{filepath.Base(backup), "app.log"}, listDir(t, dir))
+
+	file, err := os.Open(backup)
+	assert.Nil(t, err)
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "compressed\n", string(data))
+	assert.Equal(t, "kept\n", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestFileWriter_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	w, err := NewFileWriter(path, FileOptions{MaxSize: 10})
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("before\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+
+	_, err = w.Write([]byte("closed\n"))
+	assert.Equal(t, ErrClosed, err)
+
+	// The size of the existing file counts towards the maximum.
+	w, err = NewFileWriter(path, FileOptions{MaxSize: 10})
+	assert.Nil(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("after\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "after\n", readFile(t, path))
+	assert.Len(t, listDir(t, filepath.Dir(path)), 2)
+}
+
+func TestFileWriter_RotateDaily(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewFileWriter(filepath.Join(dir, "app.log"), FileOptions{Rotation: RotateDaily})
+	assert.Nil(t, err)
+	defer w.Close()
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.period = RotateDaily.periodStart(now)
+
+	write := func(line string) {
+		_, err := w.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+	write("morning\n")
+	now = now.Add(14 * time.Hour)
+	write("night\n")
+	now = now.Add(time.Hour)
+	write("tomorrow\n")
+	// Nothing is written on the 17th, so no file is rotated for it.
+	now = now.Add(48 * time.Hour)
+	write("later\n")
+
+	assert.Equal(t, []string{
+		"app-2026-10-15T00-00-00.000.log",
+		"app-2026-10-16T00-00-00.000.log",
+		"app.log",
+	}, listDir(t, dir))
+	assert.Equal(t, "morning\nnight\n", readFile(t, filepath.Join(dir, "app-2026-10-15T00-00-00.000.log")))
+	assert.Equal(t, "tomorrow\n", readFile(t, filepath.Join(dir, "app-2026-10-16T00-00-00.000.log")))
+	assert.Equal(t, "later\n", readFile(t, filepath.Join(dir, "app.log")))
+}
+
+func TestRotation_Period(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 30, 15, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC), RotateHourly.periodStart(at))
+	assert.Equal(t, time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC), RotateHourly.next(RotateHourly.periodStart(at)))
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), RotateDaily.periodStart(at))
+	assert.Equal(t, time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), RotateDaily.next(RotateDaily.periodStart(at)))
+}
+
+func TestFileWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app-2026-10-01T00-00-00.000.log", "app-2026-10-14T00-00-00.000.log.gz", "other.log"} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0644))
+	}
+	w, err := NewFileWriter(filepath.Join(dir, "app.log"), FileOptions{MaxAge: 7 * 24 * time.Hour})
+	assert.Nil(t, err)
+	defer w.Close()
+	w.now = func() time.Time { return time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC) }
+
+	_, err = w.Write([]byte("current\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Rotate())
+
+	assert.Equal(t, []string{
+		"app-2026-10-14T00-00-00.000.log.gz",
+		"app-2026-10-15T09-30-00.000.log",
+		"app.log",
+		"other.log",
+	}, listDir(t, dir))
+}
+
+func TestFileWriter_BackupOrder(t *testing.T) {
+	w, dir := newTestFileWriter(t, FileOptions{MaxBackups: 2})
+	w.now = func() time.Time {
+		return loggyTestTime
+	}
+
+	// Every rotation collides, so the backups are numbered .1 to .11.
+	for i := 0; i < 12; i++ {
+		_, err := w.Write([]byte(strconv.Itoa(i) + "\n"))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Rotate())
+	}
+
+	stamp := loggyTestTime.Format(backupTimeFormat)
+	assert.Equal(t, []string{"app-" + stamp + ".10.log", "app-" + stamp + ".11.log", "app.log"}, listDir(t, dir))
+	assert.Equal(t, "11\n", readFile(t, filepath.Join(dir, "app-"+stamp+".11.log")))
+}
+
+func TestFileWriter_RotateFailure(t *testing.T) {
+	w, dir := newTestFileWriter(t, FileOptions{})
+	_, err := w.Write([]byte("lost\n"))
+	assert.Nil(t, err)
+
+	// The file can't be renamed once it's gone, so it's reopened instead.
+	assert.Nil(t, os.Remove(filepath.Join(dir, "app.log")))
+	assert.NotNil(t, w.Rotate())
+	_, err = w.Write([]byte("kept\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Equal(t, []string{"app.log"}, listDir(t, dir))
+	assert.Equal(t, "kept\n", readFile(t, filepath.Join(dir, "app.log")))
+}
@@ -0,0 +1,124 @@
+package loggy
+
+import (
+	"io"
+)
+
+// FieldFilter limits the tags and fields written to a stream, e.g. to strip
+// personal data before messages reach a third-party service. See FilterFields.
+type FieldFilter struct {
+	// If set, only the tags and fields named here are written.
+	Allow []string
+	// The tags and fields named here are never written, even if allowed.
+	Deny []string
+}
+
+// FilterFields wraps the stream so that messages written to it by a logger only
+// include the tags and fields allowed by the filter. The filter is applied to
+// each Entry before it's encoded, so other streams still receive every tag. For
+// example, to keep user_email in the local file, but not send it to a SaaS
+// service:
+//
+//	logger, ctx := loggy.New(ctx, loggy.Options{
+//		Out: file,
+//		Destinations: map[string]io.Writer{
+//			"saas": loggy.FilterFields(saas, loggy.FieldFilter{Deny: []string{"user_email"}}),
+//		},
+//	})
+//
+// It can be used for Out, Err, Destinations, and LevelOutputs, but not within
+// another writer, such as io.MultiWriter, which would hide it from the logger.
+// Data written to the returned writer directly, rather than by a logger, is
+// passed through unchanged. Flush and Close are passed through to the stream.
+func FilterFields(w io.Writer, filter FieldFilter) io.Writer {
+	f := &filteredWriter{out: w}
+	if len(filter.Allow) > 0 {
+		f.allow = make(map[string]bool, len(filter.Allow))
+		for _, name := range filter.Allow {
+			f.allow[name] = true
+		}
+	}
+	f.deny = make(map[string]bool, len(filter.Deny))
+	for _, name := range filter.Deny {
+		f.deny[name] = true
+	}
+	return f
+}
+
+// filteredWriter is a stream with a FieldFilter.
+type filteredWriter struct {
+	out   io.Writer
+	allow map[string]bool
+	deny  map[string]bool
+}
+
+func (w *filteredWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+// Flush flushes the stream, if it supports flushing or syncing.
+func (w *filteredWriter) Flush() error {
+	return flushWriter(w.out)
+}
+
+// Close closes the stream, if it's an io.Closer.
+func (w *filteredWriter) Close() error {
+	if closer, ok := w.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// apply returns a copy of the entry with only the allowed tags and fields.
+func (w *filteredWriter) apply(entry Entry) Entry {
+	entry.Tags = w.filterTags(entry.Tags)
+	entry.Fields = w.filterTags(entry.Fields)
+	return entry
+}
+
+func (w *filteredWriter) filterTags(tags map[string]interface{}) map[string]interface{} {
+	if len(tags) == 0 {
+		return tags
+	}
+	filtered := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		if (w.allow == nil || w.allow[name]) && !w.deny[name] {
+			filtered[name] = value
+		}
+	}
+	return filtered
+}
+
+// destinations is the set of streams a message is routed to by TagDestination.
+// Each stream receives the message as encoded for it, applying its filter, if
+// any.
+type destinations []io.Writer
+
+// Write writes p to every stream, as io.MultiWriter.
+func (d destinations) Write(p []byte) (int, error) {
+	return io.MultiWriter(d...).Write(p)
+}
+
+// writeEntry writes the entry, encoded as buf, to the stream. Streams wrapped by
+// FilterFields receive the entry encoded again, without the tags they filter
+// out. It returns the output written, which is buf unless it was filtered.
+func writeEntry(w io.Writer, encode func(Entry) ([]byte, error), entry Entry, buf []byte) ([]byte, int, error) {
+	switch w := w.(type) {
+	case *filteredWriter:
+		filtered, err := encode(w.apply(entry))
+		if err != nil {
+			return nil, 0, err
+		}
+		n, err := w.out.Write(filtered)
+		return filtered, n, err
+	case destinations:
+		for _, d := range w {
+			if _, n, err := writeEntry(d, encode, entry, buf); err != nil {
+				return buf, n, err
+			}
+		}
+		return buf, len(buf), nil
+	}
+	n, err := w.Write(buf)
+	return buf, n, err
+}
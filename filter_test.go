@@ -0,0 +1,87 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+var filterFieldsTestCases = []struct {
+	Name     string
+	Filter   FieldFilter
+	Expected string
+}{
+	{
+		Name:     "none",
+		Filter:   FieldFilter{},
+		Expected: "CRIT [attempt:2, user:bob, user_email:bob@example.com] login failed\n",
+	},
+	{
+		Name:     "deny",
+		Filter:   FieldFilter{Deny: []string{"user_email"}},
+		Expected: "CRIT [attempt:2, user:bob] login failed\n",
+	},
+	{
+		Name:     "allow",
+		Filter:   FieldFilter{Allow: []string{"user", "attempt"}},
+		Expected: "CRIT [attempt:2, user:bob] login failed\n",
+	},
+	{
+		Name:     "allow-and-deny",
+		Filter:   FieldFilter{Allow: []string{"user", "user_email"}, Deny: []string{"user_email"}},
+		Expected: "CRIT [user:bob] login failed\n",
+	},
+}
+
+func TestFilterFields(t *testing.T) {
+	for _, testCase := range filterFieldsTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			local := bytes.NewBuffer([]byte{})
+			saas := bytes.NewBuffer([]byte{})
+			options := Options{
+				Err:                 local,
+				Threshold:           LevelInfo,
+				DisableFunctionName: true,
+				DisableTimestamps:   true,
+				Destinations: map[string]io.Writer{
+					"local": local,
+					"saas":  FilterFields(saas, testCase.Filter),
+				},
+			}
+			l, ctx := New(context.Background(), options)
+			_, ctx = l.AddTag(ctx, "user", "bob")
+			_, ctx = l.AddTag(ctx, "user_email", "bob@example.com")
+
+			result, err := l.Emit(ctx, LevelCritical, "login failed", Fields(map[string]interface{}{
+				"attempt":      2,
+				TagDestination: "local,saas",
+			}))
+			assert.Nil(t, err)
+			assert.Equal(t, "CRIT [attempt:2, user:bob, user_email:bob@example.com] login failed\n", local.String())
+			assert.Equal(t, testCase.Expected, saas.String())
+			assert.Equal(t, local.String(), string(result.Output))
+		})
+	}
+}
+
+func TestFilterFields_Stream(t *testing.T) {
+	stderr := &closeRecorder{}
+	options := Options{
+		Err:                 FilterFields(stderr, FieldFilter{Deny: []string{"token"}}),
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	result, err := l.Emit(ctx, LevelError, "denied", Fields(map[string]interface{}{"token": "hunter2", "user": "bob"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "ERROR [user:bob] denied\n", stderr.String())
+	assert.Equal(t, stderr.String(), string(result.Output))
+
+	// Flush and Close reach the wrapped stream.
+	assert.Nil(t, l.Close())
+	assert.Equal(t, 1, stderr.closed)
+}
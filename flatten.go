@@ -0,0 +1,70 @@
+package loggy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Flatten expands struct and map values into a flat map of dotted keys, each
+// prefixed by name. For example, a tag named "user" with the value
+// struct{ID int; Name string}{1, "bob"} is flattened to "user.ID" => 1 and
+// "user.Name" => "bob". Any other value is returned as the only entry, under
+// name. Unexported struct fields are omitted.
+//
+// Values should be passed through Sanitize first, so that cyclic values are
+// not walked forever.
+func Flatten(name string, value interface{}) map[string]interface{} {
+	flattened := make(map[string]interface{})
+	flatten(flattened, name, reflect.ValueOf(value))
+
+	return flattened
+}
+
+func flatten(flattened map[string]interface{}, name string, v reflect.Value) {
+	if !v.IsValid() || isSelfFormatting(v) {
+		flattened[name] = interfaceOf(v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			flattened[name] = nil
+			return
+		}
+		flatten(flattened, name, v.Elem())
+	case reflect.Struct:
+		count := 0
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				// Unexported.
+				continue
+			}
+			flatten(flattened, name+"."+field.Name, v.Field(i))
+			count++
+		}
+		if count == 0 {
+			flattened[name] = interfaceOf(v)
+		}
+	case reflect.Map:
+		if v.Len() == 0 {
+			flattened[name] = interfaceOf(v)
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			flatten(flattened, fmt.Sprintf("%s.%v", name, interfaceOf(iter.Key())), iter.Value())
+		}
+	default:
+		flattened[name] = interfaceOf(v)
+	}
+}
+
+// interfaceOf returns the value held by v, or nil when v is the zero Value.
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,87 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type flattenUser struct {
+	ID      int
+	Name    string
+	Address *flattenAddress
+	secret  string
+}
+
+type flattenAddress struct {
+	City string
+}
+
+var flattenTestCases = []struct {
+	Name     string
+	Value    interface{}
+	Expected map[string]interface{}
+}{
+	{
+		Name:     "primitive",
+		Value:    1,
+		Expected: map[string]interface{}{"tag": 1},
+	},
+	{
+		Name:  "struct",
+		Value: flattenUser{ID: 1, Name: "bob", Address: &flattenAddress{City: "Paris"}, secret: "hidden"},
+		Expected: map[string]interface{}{
+			"tag.ID":           1,
+			"tag.Name":         "bob",
+			"tag.Address.City": "Paris",
+		},
+	},
+	{
+		Name:  "nil-pointer-field",
+		Value: flattenUser{ID: 2},
+		Expected: map[string]interface{}{
+			"tag.ID":      2,
+			"tag.Name":    "",
+			"tag.Address": nil,
+		},
+	},
+	{
+		Name:  "map",
+		Value: map[string]interface{}{"a": 1, "b": map[int]string{2: "c"}},
+		Expected: map[string]interface{}{
+			"tag.a":   1,
+			"tag.b.2": "c",
+		},
+	},
+	{
+		Name:     "slice",
+		Value:    []int{1, 2},
+		Expected: map[string]interface{}{"tag": []int{1, 2}},
+	},
+}
+
+func TestFlatten(t *testing.T) {
+	for _, testCase := range flattenTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, Flatten("tag", testCase.Value))
+		})
+	}
+}
+
+func TestLogger_Log_FlattenTags(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		FlattenTags:         true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "user", flattenUser{ID: 1, Name: "bob"})
+	_, ctx = l.AddTag(ctx, "request", 7)
+
+	assert.Nil(t, l.Info(ctx, "flat"))
+	assert.Equal(t, "INFO [request:7, user.Address:<nil>, user.ID:1, user.Name:bob] flat\n", stdout.String())
+}
@@ -0,0 +1,94 @@
+package loggy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GCPSeverities maps each level to its Google Cloud Logging severity. Std
+// messages have the DEFAULT severity, and Trace messages are DEBUG, as Cloud
+// Logging has nothing finer.
+var GCPSeverities = map[Level]string{
+	LevelStd:      "DEFAULT",
+	LevelCritical: "CRITICAL",
+	LevelError:    "ERROR",
+	LevelWarning:  "WARNING",
+	LevelInfo:     "INFO",
+	LevelDebug:    "DEBUG",
+	LevelTrace:    "DEBUG",
+}
+
+// GCPEncoder encodes each entry as a line of JSON in the structured logging
+// format of Google Cloud Logging, which the logging agents of GKE, Cloud Run,
+// and App Engine parse from stdout and stderr. For example:
+//
+//	{"severity":"ERROR","time":"2006-01-02T15:04:05Z","message":"oops","logging.googleapis.com/sourceLocation":{"function":"main.main"},"logging.googleapis.com/labels":{"user":"bob"}}
+//
+// The severity is mapped by GCPSeverities. The message includes the entry's
+// prefix and, so that Error Reporting picks it up, its stack trace. Tags are
+// output as labels, which Cloud Logging indexes, so their values are encoded as
+// text; error tags are expanded as they are by ErrorFields. The logger and code
+// keys are omitted when empty.
+type GCPEncoder struct{}
+
+type gcpEntry struct {
+	Severity       string             `json:"severity"`
+	Time           string             `json:"time,omitempty"`
+	Message        string             `json:"message"`
+	Logger         string             `json:"logger,omitempty"`
+	Code           string             `json:"code,omitempty"`
+	SourceLocation *gcpSourceLocation `json:"logging.googleapis.com/sourceLocation,omitempty"`
+	Labels         map[string]string  `json:"logging.googleapis.com/labels,omitempty"`
+}
+
+type gcpSourceLocation struct {
+	Function string `json:"function"`
+}
+
+// Encode implements Encoder.
+func (e GCPEncoder) Encode(entry Entry) ([]byte, error) {
+	severity, ok := GCPSeverities[entry.Level]
+	if !ok {
+		severity = GCPSeverities[LevelStd]
+	}
+	encoded := gcpEntry{
+		Severity: severity,
+		Message:  entry.Message,
+		Logger:   entry.Logger,
+		Code:     entry.Code,
+	}
+	if !entry.Time.IsZero() {
+		encoded.Time = entry.Time.Format(time.RFC3339Nano)
+	}
+	if entry.Prefix != "" {
+		encoded.Message = entry.Prefix + " " + encoded.Message
+	}
+	if entry.Stack != "" {
+		encoded.Message += "\n" + entry.Stack
+	}
+	if entry.Caller != "" {
+		encoded.SourceLocation = &gcpSourceLocation{Function: entry.Caller}
+	}
+	if tags := entry.AllTags(); len(tags) > 0 {
+		encoded.Labels = make(map[string]string, len(tags))
+		for name, value := range tags {
+			if err, ok := value.(error); ok {
+				for key, field := range ErrorFields(name, err, false) {
+					encoded.Labels[key] = fmt.Sprint(textValue(field))
+				}
+				continue
+			}
+			encoded.Labels[name] = fmt.Sprint(textValue(value))
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 128+len(encoded.Message)))
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(encoded); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
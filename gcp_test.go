@@ -0,0 +1,71 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var gcpEncoderTestCases = []struct {
+	Name     string
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: `{"severity":"DEFAULT","message":""}` + "\n",
+	},
+	{
+		Name: "every-field",
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelCritical,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "<oops>",
+			Tags:    map[string]interface{}{"user": "bob", "attempt": 3},
+			Stack:   "main.main\n\tmain.go:1\n",
+		},
+		Expected: `{"severity":"CRITICAL","time":"2006-01-02T15:04:05.123456789Z","message":"~~~ <oops>\nmain.main\n\tmain.go:1\n",` +
+			`"logger":"app.db","code":"E4321","logging.googleapis.com/sourceLocation":{"function":"main.main"},` +
+			`"logging.googleapis.com/labels":{"attempt":"3","user":"bob"}}` + "\n",
+	},
+	{
+		Name: "error-labels",
+		Entry: Entry{
+			Level:  LevelTrace,
+			Fields: map[string]interface{}{"err": errors.New("timed out")},
+		},
+		Expected: `{"severity":"DEBUG","message":"","logging.googleapis.com/labels":{"err":"timed out","errType":"*errors.errorString"}}` + "\n",
+	},
+}
+
+func TestGCPEncoder_Encode(t *testing.T) {
+	for _, testCase := range gcpEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := GCPEncoder{}.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
+
+func TestGCPEncoder_Logger(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{
+		Out:               stdout,
+		Err:               stdout,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+		Encoder:           GCPEncoder{},
+	})
+	_, ctx = l.AddTag(ctx, "request", "r-1")
+	assert.Nil(t, l.Warning(ctx, "slow"))
+	assert.Regexp(t, `^\{"severity":"WARNING","message":"slow","logging.googleapis.com/sourceLocation":\{"function":"[^"]+"\},`+
+		`"logging.googleapis.com/labels":\{"request":"r-1"\}\}\n$`, stdout.String())
+}
@@ -0,0 +1,240 @@
+package loggy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// DefaultGELFChunkSize is the size of the UDP datagrams sent by GELFWriter when
+// GELFOptions.ChunkSize is zero, small enough to cross most networks without
+// fragmentation.
+const DefaultGELFChunkSize = 1420
+
+// gelfMaxChunks is the maximum number of chunks a GELF message can be split into.
+const gelfMaxChunks = 128
+
+// GELFEncoder encodes each entry as a GELF 1.1 message, for Graylog. For
+// example:
+//
+//	{"_caller":"main.main","_user":"bob","host":"web-1","level":3,"short_message":"oops","timestamp":1136214245.123,"version":"1.1"}
+//
+// The level is the entry's syslog severity, as mapped by SyslogSeverities. The
+// message, with its prefix, is the short_message, and the stack trace, if any,
+// is the full_message. The logger, caller, error code, and tags are additional
+// fields, prefixed with '_', e.g. "_user". Characters other than letters,
+// digits, '_', '.', and '-' are replaced with '_' in their names, and a tag
+// named "id", which Graylog reserves, is output as "_id_". Numbers are output
+// as numbers, and everything else as text. Error tags are expanded as they are
+// by ErrorFields. Each message ends with a newline, which GELFWriter strips.
+type GELFEncoder struct {
+	// The host field, identifying the machine. See NewGELFEncoder.
+	Host string
+}
+
+// NewGELFEncoder creates a GELFEncoder that identifies messages by the
+// machine's hostname.
+func NewGELFEncoder() GELFEncoder {
+	hostname, _ := os.Hostname()
+	return GELFEncoder{Host: hostname}
+}
+
+// Encode implements Encoder.
+func (e GELFEncoder) Encode(entry Entry) ([]byte, error) {
+	severity, ok := SyslogSeverities[entry.Level]
+	if !ok {
+		severity = SyslogSeverities[LevelStd]
+	}
+	host := e.Host
+	if host == "" {
+		// The host field is required.
+		host = "unknown"
+	}
+	message := entry.Message
+	if entry.Prefix != "" {
+		message = entry.Prefix + " " + message
+	}
+
+	fields := map[string]interface{}{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": message,
+		"level":         severity,
+	}
+	if !entry.Time.IsZero() {
+		nanos := entry.Time.UnixNano()
+		fields["timestamp"] = json.Number(fmt.Sprintf("%d.%03d", nanos/1e9, nanos%1e9/1e6))
+	}
+	if entry.Stack != "" {
+		fields["full_message"] = entry.Stack
+	}
+	for name, value := range entry.AllTags() {
+		if err, ok := value.(error); ok {
+			for key, field := range ErrorFields(name, err, false) {
+				fields[gelfName(key)] = gelfValue(field)
+			}
+			continue
+		}
+		fields[gelfName(name)] = gelfValue(value)
+	}
+	optional := [][2]string{
+		{"_logger", entry.Logger},
+		{"_caller", entry.Caller},
+		{"_code", entry.Code},
+	}
+	for _, field := range optional {
+		if field[1] != "" {
+			fields[field[0]] = field[1]
+		}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 160+len(message)))
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gelfName returns the name of the additional field for a tag.
+func gelfName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			b[i] = '_'
+		}
+	}
+	if string(b) == "id" {
+		return "_id_"
+	}
+	return "_" + string(b)
+}
+
+// gelfValue returns numbers as they are, and anything else as text, as GELF
+// only allows strings and numbers.
+func gelfValue(value interface{}) interface{} {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := value.(fmt.Stringer); !ok {
+			if b, err := json.Marshal(value); err == nil {
+				return json.RawMessage(b)
+			}
+		}
+	}
+	return fmt.Sprint(textValue(value))
+}
+
+// GELFOptions configures a GELFWriter.
+type GELFOptions struct {
+	// Configures the connection to Graylog.
+	Net NetOptions
+	// The maximum size of each UDP datagram; larger messages are split into up
+	// to 128 chunks. If zero, DefaultGELFChunkSize is used.
+	ChunkSize int
+}
+
+var _ io.WriteCloser = &GELFWriter{}
+
+// GELFWriter sends each write, such as a message encoded by GELFEncoder, to a
+// Graylog GELF input as a single message. Over UDP, messages larger than the
+// chunk size are split into chunks; over TCP, each message is terminated by a
+// null byte. The connection is managed by a NetWriter, so messages are held
+// while Graylog is unavailable. It is safe for concurrent use.
+type GELFWriter struct {
+	out       *NetWriter
+	udp       bool
+	chunkSize int
+}
+
+// NewGELFWriter creates a GELFWriter for the Graylog input at the address,
+// where network is "udp" or "tcp". For example:
+//
+//	w := loggy.NewGELFWriter("udp", "graylog:12201", loggy.GELFOptions{})
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, Encoder: loggy.NewGELFEncoder()})
+func NewGELFWriter(network, address string, options GELFOptions) *GELFWriter {
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultGELFChunkSize
+	}
+	return &GELFWriter{
+		out:       NewNetWriter(network, address, options.Net),
+		udp:       network == "udp" || network == "udp4" || network == "udp6",
+		chunkSize: options.ChunkSize,
+	}
+}
+
+// Write sends p, without its trailing newline, as a single message. Over UDP,
+// it returns ErrLineTooLarge if the message needs more than 128 chunks.
+func (w *GELFWriter) Write(p []byte) (int, error) {
+	message := bytes.TrimSuffix(p, []byte{'\n'})
+	if !w.udp {
+		framed := append(append(make([]byte, 0, len(message)+1), message...), 0)
+		if _, err := w.out.Write(framed); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	chunks, err := w.chunks(message)
+	if err != nil {
+		return 0, err
+	}
+	for _, chunk := range chunks {
+		if _, err := w.out.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush attempts to deliver any messages held while Graylog was unavailable.
+func (w *GELFWriter) Flush() error {
+	return w.out.Flush()
+}
+
+// Close closes the connection, as NetWriter.Close.
+func (w *GELFWriter) Close() error {
+	return w.out.Close()
+}
+
+// chunks splits a message into datagrams of at most the chunk size, each with
+// the GELF chunk header: magic bytes, the message's ID, the chunk's sequence
+// number, and the number of chunks.
+func (w *GELFWriter) chunks(message []byte) ([][]byte, error) {
+	if len(message) <= w.chunkSize {
+		return [][]byte{message}, nil
+	}
+	const headerSize = 12
+	size := w.chunkSize - headerSize
+	if size <= 0 {
+		return nil, ErrLineTooLarge
+	}
+	count := (len(message) + size - 1) / size
+	if count > gelfMaxChunks {
+		return nil, ErrLineTooLarge
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(message) {
+			end = len(message)
+		}
+		chunk := make([]byte, 0, headerSize+end-i*size)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunks = append(chunks, append(chunk, message[i*size:end]...))
+	}
+	return chunks, nil
+}
@@ -0,0 +1,132 @@
+package loggy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+var gelfEncoderTestCases = []struct {
+	Name     string
+	Encoder  GELFEncoder
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: `{"host":"unknown","level":5,"short_message":"","version":"1.1"}` + "\n",
+	},
+	{
+		Name:    "every-field",
+		Encoder: GELFEncoder{Host: "web-1"},
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelError,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "<oops>",
+			Tags:    map[string]interface{}{"user": "bob", "attempt": 3, "ratio": 0.5, "elapsed": time.Second},
+			Stack:   "main.main\n\tmain.go:1\n",
+		},
+		Expected: `{"_attempt":3,"_caller":"main.main","_code":"E4321","_elapsed":"1s","_logger":"app.db","_ratio":0.5,"_user":"bob",` +
+			`"full_message":"main.main\n\tmain.go:1\n","host":"web-1","level":3,"short_message":"~~~ <oops>",` +
+			`"timestamp":1136214245.123,"version":"1.1"}` + "\n",
+	},
+	{
+		Name:    "field-names",
+		Encoder: GELFEncoder{Host: "web-1"},
+		Entry: Entry{
+			Level: LevelWarning,
+			Tags:  map[string]interface{}{"id": 7, "user id": "bob", "ok": true},
+			Fields: map[string]interface{}{
+				"err": errors.New("timed out"),
+			},
+		},
+		Expected: `{"_err":"timed out","_errType":"*errors.errorString","_id_":7,"_ok":"true","_user_id":"bob",` +
+			`"host":"web-1","level":4,"short_message":"","version":"1.1"}` + "\n",
+	},
+}
+
+func TestGELFEncoder_Encode(t *testing.T) {
+	for _, testCase := range gelfEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := testCase.Encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
+
+func TestGELFWriter_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	w := NewGELFWriter("udp", server.LocalAddr().String(), GELFOptions{ChunkSize: 20})
+	defer w.Close()
+	read := func() []byte {
+		buf := make([]byte, 1024)
+		assert.Nil(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := server.ReadFrom(buf)
+		assert.Nil(t, err)
+		return buf[:n]
+	}
+
+	_, err = w.Write([]byte(`{"short":"hi"}` + "\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"short":"hi"}`, string(read()))
+
+	// Split into chunks of 8 bytes, after the 12 byte header.
+	message := []byte(`{"short_message":"hello"}`)
+	n, err := w.Write(append(message, '\n'))
+	assert.Nil(t, err)
+	assert.Equal(t, len(message)+1, n)
+	var reassembled []byte
+	var id []byte
+	for i := 0; i < 4; i++ {
+		chunk := read()
+		assert.Equal(t, []byte{0x1e, 0x0f}, chunk[:2])
+		if id == nil {
+			id = chunk[2:10]
+		}
+		assert.Equal(t, id, chunk[2:10])
+		assert.Equal(t, []byte{byte(i), 4}, chunk[10:12])
+		reassembled = append(reassembled, chunk[12:]...)
+	}
+	assert.Equal(t, message, reassembled)
+
+	_, err = w.Write(bytes.Repeat([]byte("a"), 8*128+1))
+	assert.Equal(t, ErrLineTooLarge, err)
+}
+
+func TestGELFWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	w := NewGELFWriter("tcp", listener.Addr().String(), GELFOptions{})
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	_, err = w.Write([]byte(`{"short_message":"first"}` + "\n"))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte(`{"short_message":"second"}` + "\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	reader := bufio.NewReader(conn)
+	first, err := reader.ReadString(0)
+	assert.Nil(t, err)
+	second, err := reader.ReadString(0)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"short_message":"first"}` + "\x00", `{"short_message":"second"}` + "\x00"}, []string{first, second})
+}
@@ -0,0 +1,78 @@
+package loggy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// hooks are the functions registered by AddHook on a logger, and shared by
+// every logger derived from it.
+type hooks struct {
+	mux  sync.RWMutex
+	list []hook
+}
+
+type hook struct {
+	// The levels the hook is called for, or nil for every level.
+	levels map[Level]bool
+	fn     func(entry Entry) error
+}
+
+// AddHook registers fn to be called with every entry at one of the levels that
+// is written by this logger, or any logger related to it, e.g. to count entries
+// for metrics, or to raise an alert on Critical entries, without wrapping the
+// output streams. No levels means every level. Hooks are called in the order
+// they were added, after the entry has been written, on the logging goroutine,
+// so they should be quick. The entry's tags and fields are shared, and must not
+// be modified. If a hook fails, the remaining hooks are still called, and the
+// first error is returned by the logging method, unless the write itself failed.
+func (l *logger) AddHook(levels []Level, fn func(entry Entry) error) {
+	h := hook{fn: fn}
+	if len(levels) > 0 {
+		h.levels = make(map[Level]bool, len(levels))
+		for _, level := range levels {
+			h.levels[level] = true
+		}
+	}
+
+	registry := l.root().hooks
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+
+	registry.list = append(registry.list, h)
+}
+
+// any reports whether any hooks are registered.
+func (h *hooks) any() bool {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return len(h.list) > 0
+}
+
+// copy returns a copy of the registered hooks.
+func (h *hooks) copy() *hooks {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return &hooks{list: append([]hook(nil), h.list...)}
+}
+
+// fire calls every hook registered for the entry's level, returning the first
+// error.
+func (h *hooks) fire(entry Entry) error {
+	h.mux.RLock()
+	list := h.list
+	h.mux.RUnlock()
+
+	var first error
+	for _, hook := range list {
+		if hook.levels != nil && !hook.levels[entry.Level] {
+			continue
+		}
+		if err := hook.fn(entry); err != nil && first == nil {
+			first = fmt.Errorf("loggy: hook failed: %w", err)
+		}
+	}
+	return first
+}
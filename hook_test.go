@@ -0,0 +1,72 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLogger_AddHook(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 buf,
+		Err:                 buf,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		DisableTags:         true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", 1)
+
+	var all, critical []Entry
+	l.AddHook(nil, func(entry Entry) error {
+		all = append(all, entry)
+		return nil
+	})
+	db := l.Named("db")
+	db.AddHook([]Level{LevelCritical}, func(entry Entry) error {
+		critical = append(critical, entry)
+		return nil
+	})
+
+	assert.Nil(t, l.Info(ctx, "hello"))
+	assert.Nil(t, db.Critical(ctx, "paging"))
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, "hello", all[0].Message)
+		assert.Equal(t, map[string]interface{}{"request": 1}, all[0].Tags)
+		assert.Equal(t, "db", all[1].Logger)
+	}
+	if assert.Len(t, critical, 1) {
+		assert.Equal(t, "paging", critical[0].Message)
+	}
+	assert.Equal(t, "INFO hello\nCRIT db paging\n", buf.String())
+}
+
+func TestLogger_AddHook_Error(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{Out: buf, Threshold: LevelInfo})
+	failure := errors.New("alert unavailable")
+	called := 0
+	l.AddHook(nil, func(entry Entry) error {
+		return failure
+	})
+	l.AddHook(nil, func(entry Entry) error {
+		called++
+		return nil
+	})
+
+	err := l.Info(ctx, "hello")
+	assert.True(t, errors.Is(err, failure))
+	assert.EqualError(t, err, "loggy: hook failed: alert unavailable")
+	assert.Equal(t, 1, called)
+	assert.Contains(t, buf.String(), "hello")
+
+	l.SetOutput(failingWriter{}, nil)
+	assert.EqualError(t, l.Info(ctx, "hello"), "disk full")
+	assert.Equal(t, 2, called)
+}
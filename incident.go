@@ -0,0 +1,112 @@
+package loggy
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Incident is the verbose configuration a logger switches to while incident
+// mode is on. See Logger.SetIncidentMode.
+type Incident struct {
+	// The threshold while incident mode is on. It applies to every named
+	// logger, regardless of Options.Thresholds. If zero, LevelDebug is used.
+	Threshold Level
+	// An optional stream that receives a copy of every message written while
+	// incident mode is on, e.g. a file to attach to the incident report,
+	// whichever stream or destination the message is written to. The tee is
+	// best effort: its errors aren't returned, and it isn't flushed or closed
+	// by the logger.
+	Tee io.Writer
+}
+
+// DefaultIncident is used by loggers created without Options.Incident.
+var DefaultIncident = Incident{
+	Threshold: LevelDebug,
+}
+
+// SetIncidentMode switches the logger, along with every logger derived from it,
+// to the verbose configuration in Options.Incident, or back again. While
+// incident mode is on, sampling is disabled. Each change is logged as a
+// standard message, so that it's recorded alongside the logs it affects.
+// Switching to the mode that's already in effect has no effect.
+func (l *logger) SetIncidentMode(on bool) {
+	root := l.root()
+
+	root.optionsMux.Lock()
+	if (root.incidentBase != nil) == on {
+		root.optionsMux.Unlock()
+		return
+	}
+	if !on {
+		root.optionsMux.Unlock()
+		// Log before switching off, so that the tee receives it.
+		_, _ = l.emit(context.Background(), 2, LevelStd, "incident mode off, after %s", root.incidentDuration())
+
+		root.optionsMux.Lock()
+		if root.incidentBase != nil {
+			root.options = root.incidentBase
+			root.incidentBase = nil
+		}
+		root.optionsMux.Unlock()
+		return
+	}
+	root.incidentBase = root.options
+	root.incidentSince = root.options.TimestampFunc()
+	root.options = incidentOptions(root.options)
+	root.optionsMux.Unlock()
+
+	_, _ = l.emit(context.Background(), 2, LevelStd, "incident mode on")
+}
+
+// IncidentMode reports whether incident mode is on, and if so, when it was
+// switched on.
+func (l *logger) IncidentMode() (bool, time.Time) {
+	root := l.root()
+
+	root.optionsMux.RLock()
+	defer root.optionsMux.RUnlock()
+
+	if root.incidentBase == nil {
+		return false, time.Time{}
+	}
+	return true, root.incidentSince
+}
+
+func (l *logger) incidentDuration() time.Duration {
+	_, since := l.IncidentMode()
+	return l.currentOptions().TimestampFunc().Sub(since)
+}
+
+// incidentOptions applies the incident configuration to a copy of base.
+func incidentOptions(base *Options) *Options {
+	incident := DefaultIncident
+	if base.Incident != nil {
+		incident = *base.Incident
+	}
+
+	options := *base
+	options.Threshold = incident.Threshold
+	if options.Threshold == LevelStd {
+		options.Threshold = LevelDebug
+	}
+	options.Thresholds = nil
+	options.Sampling = nil
+	return &options
+}
+
+// incidentTee returns the Incident.Tee in effect, if incident mode is on.
+func (l *logger) incidentTee() io.Writer {
+	root := l.root()
+
+	root.optionsMux.RLock()
+	defer root.optionsMux.RUnlock()
+
+	if root.incidentBase == nil {
+		return nil
+	}
+	if root.incidentBase.Incident != nil {
+		return root.incidentBase.Incident.Tee
+	}
+	return DefaultIncident.Tee
+}
@@ -0,0 +1,115 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetIncidentMode(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	incident := bytes.NewBuffer([]byte{})
+	now := loggyTestTime
+	options := Options{
+		Out:                 stdout,
+		Err:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Sampling:            &Sampling{Initial: 1, Thereafter: 0, Tick: time.Hour},
+		Incident:            &Incident{Threshold: LevelDebug, Tee: incident},
+		TimestampFunc: func() time.Time {
+			return now
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	delegate := l.Delegate(Delegation{Threshold: LevelDebug})
+
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+	assert.Nil(t, l.Info(ctx, "sampled"))
+	assert.Nil(t, l.Info(ctx, "sampled"))
+
+	delegate.SetIncidentMode(true)
+	on, since := l.IncidentMode()
+	assert.True(t, on)
+	assert.Equal(t, loggyTestTime, since)
+	assert.Nil(t, delegate.Debug(ctx, "verbose"))
+	assert.Nil(t, l.Info(ctx, "sampled"))
+	now = now.Add(time.Minute)
+	l.SetIncidentMode(false)
+
+	on, _ = l.IncidentMode()
+	assert.False(t, on)
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+
+	assert.Equal(t,
+		"INFO sampled\nOUT incident mode on\nDEBUG verbose\nINFO sampled\nOUT incident mode off, after 1m0s\n",
+		stdout.String(),
+	)
+	assert.Equal(t,
+		"OUT incident mode on\nDEBUG verbose\nINFO sampled\nOUT incident mode off, after 1m0s\n",
+		incident.String(),
+	)
+}
+
+func TestLogger_SetIncidentMode_SetOutput(t *testing.T) {
+	incident := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 bytes.NewBuffer([]byte{}),
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Incident:            &Incident{Threshold: LevelDebug, Tee: incident},
+		TimestampFunc: func() time.Time {
+			return loggyTestTime
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	l.SetIncidentMode(true)
+	l.SetIncidentMode(true)
+
+	stdout := bytes.NewBuffer([]byte{})
+	l.SetOutput(stdout, stdout)
+	assert.Nil(t, l.Debug(ctx, "moved"))
+	l.SetIncidentMode(false)
+	assert.Nil(t, l.Info(ctx, "kept"))
+
+	assert.Equal(t, "DEBUG moved\nOUT incident mode off, after 0s\nINFO kept\n", stdout.String())
+	assert.Equal(t, "OUT incident mode on\nDEBUG moved\nOUT incident mode off, after 0s\n", incident.String())
+}
+
+func TestLogger_SetIncidentMode_Outputs(t *testing.T) {
+	stdout := &closeRecorder{}
+	warnings := bytes.NewBuffer([]byte{})
+	audit := bytes.NewBuffer([]byte{})
+	incident := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stdout,
+		LevelOutputs:        map[Level]io.Writer{LevelWarning: warnings},
+		Destinations:        map[string]io.Writer{"audit": audit},
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Incident:            &Incident{Tee: incident},
+		TimestampFunc: func() time.Time {
+			return loggyTestTime
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	l.SetIncidentMode(true)
+
+	assert.Nil(t, l.Debug(ctx, "verbose"))
+	assert.Nil(t, l.Warning(ctx, "slow"))
+	assert.Nil(t, l.Info(ctx, "login", Fields(map[string]interface{}{TagDestination: "audit"})))
+	assert.Nil(t, l.Close())
+
+	assert.Equal(t, "OUT incident mode on\nDEBUG verbose\n", stdout.String())
+	assert.Equal(t, 1, stdout.closed)
+	assert.Equal(t, "WARN slow\n", warnings.String())
+	assert.Equal(t, "INFO login\n", audit.String())
+	assert.Equal(t, "OUT incident mode on\nDEBUG verbose\nWARN slow\nINFO login\n", incident.String())
+}
@@ -0,0 +1,118 @@
+package loggy
+
+import (
+	"expvar"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// Instrumentation is the cost of the messages logged while instrumentation was
+// enabled, see EnableInstrumentation.
+type Instrumentation struct {
+	// The number of messages passed to a logger, including those that were
+	// dropped.
+	Entries int64
+	// The total time spent handling the messages.
+	Nanoseconds int64
+	// The total number of heap allocations, and their size in bytes, made while
+	// handling the messages. These are read from process-wide counters, which the
+	// runtime updates in batches, and which include allocations made concurrently
+	// by other goroutines, so they're only meaningful averaged over many
+	// messages.
+	Allocs int64
+	Bytes  int64
+}
+
+// instrumented is 1 while instrumentation is enabled.
+var instrumented int32
+
+var instrumentation Instrumentation
+
+// The expvar counters are published under "loggy", e.g. at /debug/vars.
+var instrumentationVars = expvar.NewMap("loggy")
+
+func init() {
+	instrumentationVars.Set("entries", expvar.Func(func() interface{} {
+		return atomic.LoadInt64(&instrumentation.Entries)
+	}))
+	instrumentationVars.Set("ns_per_entry", expvar.Func(func() interface{} {
+		stats := InstrumentationStats()
+		return perEntry(stats.Nanoseconds, stats.Entries)
+	}))
+	instrumentationVars.Set("allocs_per_entry", expvar.Func(func() interface{} {
+		stats := InstrumentationStats()
+		return perEntry(stats.Allocs, stats.Entries)
+	}))
+	instrumentationVars.Set("bytes_per_entry", expvar.Func(func() interface{} {
+		stats := InstrumentationStats()
+		return perEntry(stats.Bytes, stats.Entries)
+	}))
+}
+
+// EnableInstrumentation turns on, or off, recording how long each message takes
+// to handle and how much it allocates, so that regressions in formatting can be
+// seen in production. The totals are available from InstrumentationStats, and
+// the per-entry averages are published via expvar under "loggy". It is off by
+// default, since recording has a cost of its own.
+func EnableInstrumentation(enabled bool) {
+	var flag int32
+	if enabled {
+		flag = 1
+	}
+	atomic.StoreInt32(&instrumented, flag)
+}
+
+// InstrumentationStats returns the totals recorded while instrumentation was
+// enabled.
+func InstrumentationStats() Instrumentation {
+	return Instrumentation{
+		Entries:     atomic.LoadInt64(&instrumentation.Entries),
+		Nanoseconds: atomic.LoadInt64(&instrumentation.Nanoseconds),
+		Allocs:      atomic.LoadInt64(&instrumentation.Allocs),
+		Bytes:       atomic.LoadInt64(&instrumentation.Bytes),
+	}
+}
+
+// instrumentSample measures a single message.
+type instrumentSample struct {
+	start   time.Time
+	samples [2]metrics.Sample
+}
+
+func startInstrumentSample() *instrumentSample {
+	s := &instrumentSample{}
+	s.samples[0].Name = "/gc/heap/allocs:objects"
+	s.samples[1].Name = "/gc/heap/allocs:bytes"
+	metrics.Read(s.samples[:])
+	s.start = time.Now()
+
+	return s
+}
+
+func (s *instrumentSample) finish() {
+	elapsed := time.Since(s.start)
+	before := s.samples
+	metrics.Read(s.samples[:])
+
+	atomic.AddInt64(&instrumentation.Entries, 1)
+	atomic.AddInt64(&instrumentation.Nanoseconds, int64(elapsed))
+	atomic.AddInt64(&instrumentation.Allocs, metricDelta(before[0], s.samples[0]))
+	atomic.AddInt64(&instrumentation.Bytes, metricDelta(before[1], s.samples[1]))
+}
+
+// metricDelta returns the increase in a cumulative counter, or 0 if the counter
+// isn't supported by the runtime.
+func metricDelta(before, after metrics.Sample) int64 {
+	if before.Value.Kind() != metrics.KindUint64 || after.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(after.Value.Uint64() - before.Value.Uint64())
+}
+
+func perEntry(total, entries int64) float64 {
+	if entries == 0 {
+		return 0
+	}
+	return float64(total) / float64(entries)
+}
@@ -0,0 +1,47 @@
+package loggy
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestEnableInstrumentation(t *testing.T) {
+	options := Options{
+		Out:       ioutil.Discard,
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "waffles", 1)
+
+	before := InstrumentationStats()
+	assert.Nil(t, l.Info(ctx, "not recorded"))
+	assert.Equal(t, before, InstrumentationStats())
+
+	EnableInstrumentation(true)
+	defer EnableInstrumentation(false)
+	// The runtime counts small allocations in batches, as each goroutine's cache
+	// is refilled, but counts large ones as they're made, so log large messages,
+	// which are copied as they're formatted and encoded.
+	message := strings.Repeat("a", 64<<10)
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, l.Info(ctx, message))
+	}
+
+	after := InstrumentationStats()
+	assert.Equal(t, before.Entries+10, after.Entries)
+	assert.Greater(t, after.Nanoseconds, before.Nanoseconds)
+	assert.Greater(t, after.Allocs, before.Allocs)
+	assert.Greater(t, after.Bytes, before.Bytes+10*int64(len(message)))
+
+	var published map[string]float64
+	assert.Nil(t, json.Unmarshal([]byte(expvar.Get("loggy").String()), &published))
+	assert.Equal(t, float64(after.Entries), published["entries"])
+	assert.Greater(t, published["ns_per_entry"], float64(0))
+	assert.Greater(t, published["allocs_per_entry"], float64(0))
+	assert.Greater(t, published["bytes_per_entry"], float64(len(message)))
+}
@@ -0,0 +1,64 @@
+package loggy
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// The strings below are repeated across many messages, so they're built once
+// and reused, rather than allocated for every message. Both caches are bounded
+// by the number of distinct call sites and levels in the program.
+
+var callerNames = struct {
+	mux   sync.RWMutex
+	names map[uintptr]string
+}{
+	names: make(map[uintptr]string),
+}
+
+// callerName returns the package-qualified name of the function containing pc,
+// e.g. "loggy.(*logger).Logf".
+func callerName(pc uintptr) string {
+	callerNames.mux.RLock()
+	name, ok := callerNames.names[pc]
+	callerNames.mux.RUnlock()
+	if ok {
+		return name
+	}
+
+	name = runtime.FuncForPC(pc).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	callerNames.mux.Lock()
+	callerNames.names[pc] = name
+	callerNames.mux.Unlock()
+
+	return name
+}
+
+var coloredLabels = struct {
+	mux    sync.RWMutex
+	labels map[[2]string]string
+}{
+	labels: make(map[[2]string]string),
+}
+
+// coloredLabel returns the label wrapped in the color's escape codes.
+func coloredLabel(color, label string) string {
+	key := [2]string{color, label}
+	coloredLabels.mux.RLock()
+	colored, ok := coloredLabels.labels[key]
+	coloredLabels.mux.RUnlock()
+	if ok {
+		return colored
+	}
+
+	colored = color + label + colorReset
+
+	coloredLabels.mux.Lock()
+	coloredLabels.labels[key] = colored
+	coloredLabels.mux.Unlock()
+
+	return colored
+}
@@ -0,0 +1,26 @@
+package loggy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"testing"
+)
+
+func TestCallerName(t *testing.T) {
+	pc, _, _, ok := runtime.Caller(0)
+	assert.True(t, ok)
+
+	assert.Equal(t, "loggy.TestCallerName", callerName(pc))
+	assert.Equal(t, "loggy.TestCallerName", callerName(pc))
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() {
+		callerName(pc)
+	}))
+}
+
+func TestColoredLabel(t *testing.T) {
+	assert.Equal(t, "\x1b[32mINFO\x1b[0m", coloredLabel("\x1b[32m", "INFO"))
+	assert.Equal(t, "\x1b[33mINFO\x1b[0m", coloredLabel("\x1b[33m", "INFO"))
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() {
+		coloredLabel("\x1b[32m", "INFO")
+	}))
+}
@@ -0,0 +1,58 @@
+package loggy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// Job runs fn as a single run of the named job, e.g. a cron task, with a context
+// tagged with the job name and a unique run ID, as "job.name" and "job.run".
+// The start of the run is logged at LevelInfo. When fn returns, a summary is
+// logged with the outcome and duration, as "job.outcome" and "job.duration":
+// at LevelInfo on success, or LevelError on failure. Summaries bypass the
+// threshold and sampling, so that alerts on missed or failed runs can rely on
+// them. Panics are logged at LevelCritical, after which the panic resumes.
+func Job(ctx context.Context, l Logger, name string, fn func(ctx context.Context) error) (err error) {
+	_, ctx = l.AddTag(ctx, "job.name", name)
+	_, ctx = l.AddTag(ctx, "job.run", newRunID())
+
+	start := l.Options().TimestampFunc()
+	summary := func(outcome string) map[string]interface{} {
+		return map[string]interface{}{
+			"job.outcome":  outcome,
+			"job.duration": l.Options().TimestampFunc().Sub(start),
+		}
+	}
+
+	_ = l.Logf(ctx, LevelInfo, "job started")
+	defer func() {
+		if r := recover(); r != nil {
+			fields := summary("panic")
+			fields["panic"] = fmt.Sprint(r)
+			_ = l.Logf(ctx, LevelCritical, "job panicked", Bypass(), Fields(fields))
+			panic(r)
+		}
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		fields := summary("error")
+		fields["error"] = err
+		_ = l.Logf(ctx, LevelError, "job failed", Bypass(), Fields(fields))
+		return err
+	}
+	_ = l.Logf(ctx, LevelInfo, "job finished", Bypass(), Fields(summary("ok")))
+
+	return nil
+}
+
+// newRunID returns a random identifier for a single job run.
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,60 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestJob(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+	l.options.Threshold = LevelWarning
+
+	var run interface{}
+	err := Job(ctx, l, "nightly-report", func(ctx context.Context) error {
+		run = l.Tag(ctx, "job.run")
+		now = now.Add(time.Minute)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{16}$`), run)
+
+	// The start is filtered by the threshold, but the summary is not.
+	assert.Equal(t,
+		"INFO [job.duration:1m0s, job.name:nightly-report, job.outcome:ok, job.run:"+run.(string)+"] job finished\n",
+		out.String(),
+	)
+}
+
+func TestJob_Error(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+	failure := errors.New("no data")
+
+	err := Job(ctx, l, "nightly-report", func(ctx context.Context) error {
+		return failure
+	})
+	assert.Equal(t, failure, err)
+	assert.Regexp(t, regexp.MustCompile(
+		`^INFO \[job.name:nightly-report, job.run:[0-9a-f]{16}\] job started\n`+
+			`ERROR \[error:no data \(\*errors.errorString\), job.duration:0s, job.name:nightly-report, job.outcome:error, job.run:[0-9a-f]{16}\] job failed\n$`,
+	), out.String())
+}
+
+func TestJob_Panic(t *testing.T) {
+	now := loggyTestTime
+	l, ctx, out := newConsumeTestLogger(&now)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = Job(ctx, l, "nightly-report", func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.Regexp(t, regexp.MustCompile(
+		`CRIT \[job.duration:0s, job.name:nightly-report, job.outcome:panic, job.run:[0-9a-f]{16}, panic:boom\] job panicked\n$`,
+	), out.String())
+}
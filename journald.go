@@ -0,0 +1,152 @@
+package loggy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoJournal is returned by NewJournalWriter when the systemd journal isn't
+// available, e.g. on platforms other than Linux.
+var ErrNoJournal = errors.New("systemd journal is not available")
+
+// JournalEncoder encodes each entry for the systemd journal's native protocol,
+// as a list of fields. For example:
+//
+//	MESSAGE=oops
+//	PRIORITY=3
+//	SYSLOG_IDENTIFIER=app
+//	CODE_FUNC=main.main
+//	USER_ID=bob
+//
+// The PRIORITY is the entry's syslog severity, as mapped by SyslogSeverities,
+// so that journalctl's --priority filter works natively. The message includes
+// the entry's prefix. The logger, error code, and stack trace are output as
+// LOGGER, ERROR_CODE, and STACK. Tags are output as fields of their own, with
+// their names converted to the journal's format, e.g. "user.id" becomes
+// USER_ID; names that would otherwise start with an underscore or a digit, which
+// the journal reserves or rejects, or that would collide with the fields above,
+// or the journal's other well-known fields, such as MESSAGE_ID, are prefixed
+// with TAG_. Entries are written with a JournalWriter.
+type JournalEncoder struct {
+	// The SYSLOG_IDENTIFIER field, used by journalctl --identifier. See
+	// NewJournalEncoder.
+	Identifier string
+}
+
+// NewJournalEncoder creates a JournalEncoder that identifies entries by the
+// program's name.
+func NewJournalEncoder() JournalEncoder {
+	return JournalEncoder{Identifier: filepath.Base(os.Args[0])}
+}
+
+// Encode implements Encoder.
+func (e JournalEncoder) Encode(entry Entry) ([]byte, error) {
+	severity, ok := SyslogSeverities[entry.Level]
+	if !ok {
+		severity = SyslogSeverities[LevelStd]
+	}
+	message := entry.Message
+	if entry.Prefix != "" {
+		message = strings.TrimSuffix(entry.Prefix+" "+entry.Message, " ")
+	}
+
+	buf := make([]byte, 0, 128+len(message))
+	buf = appendJournalField(buf, "MESSAGE", message)
+	buf = appendJournalField(buf, "PRIORITY", strconv.Itoa(severity))
+	optional := [][2]string{
+		{"SYSLOG_IDENTIFIER", e.Identifier},
+		{"LOGGER", entry.Logger},
+		{"CODE_FUNC", entry.Caller},
+		{"ERROR_CODE", entry.Code},
+		{"STACK", entry.Stack},
+	}
+	for _, field := range optional {
+		if field[1] != "" {
+			buf = appendJournalField(buf, field[0], field[1])
+		}
+	}
+
+	tags := entry.AllTags()
+	fields := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		if err, ok := value.(error); ok {
+			for key, field := range ErrorFields(name, err, false) {
+				fields[journalName(key)] = field
+			}
+			continue
+		}
+		fields[journalName(name)] = value
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buf = appendJournalField(buf, name, fmt.Sprint(textValue(fields[name])))
+	}
+	return buf, nil
+}
+
+// appendJournalField appends a field in the journal's native format: NAME=value
+// and a newline, or, if the value contains a newline, the name and a newline,
+// followed by the value's length as a little-endian uint64, the value, and a
+// newline.
+func appendJournalField(buf []byte, name, value string) []byte {
+	if !strings.Contains(value, "\n") {
+		buf = append(append(append(buf, name...), '='), value...)
+		return append(buf, '\n')
+	}
+	buf = append(append(buf, name...), '\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf = append(append(buf, size[:]...), value...)
+	return append(buf, '\n')
+}
+
+// journalReserved are the fields written by JournalEncoder, and the journal's
+// other well-known fields, which tags must not overwrite.
+var journalReserved = map[string]bool{
+	"MESSAGE":           true,
+	"MESSAGE_ID":        true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"SYSLOG_FACILITY":   true,
+	"SYSLOG_PID":        true,
+	"SYSLOG_TIMESTAMP":  true,
+	"SYSLOG_RAW":        true,
+	"LOGGER":            true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"ERROR_CODE":        true,
+	"ERRNO":             true,
+	"STACK":             true,
+	"DOCUMENTATION":     true,
+	"TID":               true,
+}
+
+// journalName converts a tag name to a valid journal field name: at most 64
+// uppercase ASCII letters, digits, and underscores, starting with a letter, and
+// not one of journalReserved.
+func journalName(name string) string {
+	b := []byte(strings.ToUpper(name))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || b[0] < 'A' || b[0] > 'Z' || journalReserved[string(b)] {
+		b = append([]byte("TAG_"), b...)
+	}
+	if len(b) > 64 {
+		b = b[:64]
+	}
+	return string(b)
+}
@@ -0,0 +1,102 @@
+package loggy
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// journalSocket is the socket that journald receives native messages on.
+var journalSocket = "/run/systemd/journal/socket"
+
+var _ io.WriteCloser = &JournalWriter{}
+
+// JournalWriter sends each write, such as an entry encoded by JournalEncoder, to
+// the systemd journal as a single message. Messages too large for a datagram are
+// passed to journald as a file instead. It is only available on Linux; on other
+// platforms, NewJournalWriter returns ErrNoJournal. It is safe for concurrent
+// use. For example:
+//
+//	w, err := loggy.NewJournalWriter()
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, Encoder: loggy.NewJournalEncoder()})
+type JournalWriter struct {
+	mux sync.Mutex
+	// An unconnected socket, as descriptors can only be passed by WriteMsgUnix
+	// to an explicit address.
+	conn   *net.UnixConn
+	addr   *net.UnixAddr
+	closed bool
+}
+
+// NewJournalWriter opens a socket for sending to the local journald, returning
+// ErrNoJournal if journald isn't listening, e.g. in a container without systemd.
+func NewJournalWriter() (*JournalWriter, error) {
+	if _, err := os.Stat(journalSocket); err != nil {
+		return nil, ErrNoJournal
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournalWriter{
+		conn: conn,
+		addr: &net.UnixAddr{Name: journalSocket, Net: "unixgram"},
+	}, nil
+}
+
+// Write sends p as a single message.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	_, err := w.conn.WriteToUnix(p, w.addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		err = w.writeFile(p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection. Calling Close more than once has no effect.
+func (w *JournalWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.conn.Close()
+}
+
+// writeFile writes a message that's too large for a datagram to an unlinked
+// temporary file, then passes the file's descriptor to journald, which reads
+// the message from it.
+func (w *JournalWriter) writeFile(p []byte) error {
+	dir := "/dev/shm"
+	if _, err := os.Stat(dir); err != nil {
+		dir = ""
+	}
+	file, err := ioutil.TempFile(dir, "loggy-journal-")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	if _, err := file.Write(p); err != nil {
+		return err
+	}
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), w.addr)
+	return err
+}
@@ -0,0 +1,86 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// listenJournal replaces the journald socket with one in a temporary directory.
+func listenJournal(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "socket")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	assert.Nil(t, err)
+
+	socket := journalSocket
+	journalSocket = path
+	t.Cleanup(func() {
+		journalSocket = socket
+		_ = server.Close()
+	})
+	return server
+}
+
+func TestJournalWriter(t *testing.T) {
+	server := listenJournal(t)
+	w, err := NewJournalWriter()
+	assert.Nil(t, err)
+	l, ctx := New(context.Background(), Options{
+		Out:                 w,
+		Err:                 w,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		Encoder:             JournalEncoder{Identifier: "app"},
+	})
+
+	assert.Nil(t, l.Error(ctx, "oops"))
+	buf := make([]byte, 1024)
+	n, err := server.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "MESSAGE=oops\nPRIORITY=3\nSYSLOG_IDENTIFIER=app\n", string(buf[:n]))
+
+	assert.Nil(t, l.Close())
+	_, err = w.Write([]byte("MESSAGE=late\n"))
+	assert.Equal(t, ErrClosed, err)
+
+	journalSocket = filepath.Join(t.TempDir(), "missing")
+	_, err = NewJournalWriter()
+	assert.Equal(t, ErrNoJournal, err)
+}
+
+func TestJournalWriter_Large(t *testing.T) {
+	server := listenJournal(t)
+	w, err := NewJournalWriter()
+	assert.Nil(t, err)
+	defer w.Close()
+
+	// Too large for a datagram, so it's passed as a file.
+	message := append([]byte("MESSAGE="), bytes.Repeat([]byte("a"), 4<<20)...)
+	n, err := w.Write(message)
+	assert.Nil(t, err)
+	assert.Equal(t, len(message), n)
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := server.ReadMsgUnix(nil, oob)
+	assert.Nil(t, err)
+	messages, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	assert.Nil(t, err)
+	assert.Len(t, messages, 1)
+	fds, err := syscall.ParseUnixRights(&messages[0])
+	assert.Nil(t, err)
+	assert.Len(t, fds, 1)
+
+	file := os.NewFile(uintptr(fds[0]), "journal")
+	defer file.Close()
+	_, err = file.Seek(0, 0)
+	assert.Nil(t, err)
+	received, err := ioutil.ReadAll(file)
+	assert.Nil(t, err)
+	assert.Equal(t, message, received)
+}
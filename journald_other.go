@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+package loggy
+
+import (
+	"io"
+)
+
+var _ io.WriteCloser = &JournalWriter{}
+
+// JournalWriter sends entries to the systemd journal, which is only available
+// on Linux.
+type JournalWriter struct{}
+
+// NewJournalWriter returns ErrNoJournal, as the systemd journal is only
+// available on Linux.
+func NewJournalWriter() (*JournalWriter, error) {
+	return nil, ErrNoJournal
+}
+
+// Write returns ErrNoJournal.
+func (w *JournalWriter) Write(p []byte) (int, error) {
+	return 0, ErrNoJournal
+}
+
+// Close has no effect.
+func (w *JournalWriter) Close() error {
+	return nil
+}
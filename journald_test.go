@@ -0,0 +1,72 @@
+package loggy
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var journalEncoderTestCases = []struct {
+	Name     string
+	Encoder  JournalEncoder
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: "MESSAGE=\nPRIORITY=5\n",
+	},
+	{
+		Name:    "every-field",
+		Encoder: JournalEncoder{Identifier: "app"},
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelWarning,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "low disk",
+			Tags:    map[string]interface{}{"user.id": "bob", "free": 3},
+			Fields:  map[string]interface{}{"err": errors.New("timed out")},
+		},
+		Expected: "MESSAGE=~~~ low disk\nPRIORITY=4\nSYSLOG_IDENTIFIER=app\nLOGGER=app.db\nCODE_FUNC=main.main\nERROR_CODE=E4321\n" +
+			"ERR=timed out\nERRTYPE=*errors.errorString\nFREE=3\nUSER_ID=bob\n",
+	},
+	{
+		Name:     "multi-line-values",
+		Entry:    Entry{Level: LevelCritical, Message: "first\nsecond"},
+		Expected: "MESSAGE\n\x0c\x00\x00\x00\x00\x00\x00\x00first\nsecond\nPRIORITY=2\n",
+	},
+	{
+		Name: "reserved-names",
+		Entry: Entry{
+			Level: LevelTrace,
+			Tags:  map[string]interface{}{"_pid": 1, "2fa": true, "": "x", strings.Repeat("a", 70): 1},
+		},
+		Expected: "MESSAGE=\nPRIORITY=7\n" + strings.Repeat("A", 64) + "=1\nTAG_=x\nTAG_2FA=true\nTAG__PID=1\n",
+	},
+	{
+		Name:    "colliding-names",
+		Encoder: JournalEncoder{Identifier: "app"},
+		Entry: Entry{
+			Level:   LevelInfo,
+			Message: "hi",
+			Tags:    map[string]interface{}{"priority": "high", "message": "spoofed", "syslog.identifier": "other", "stack": "deep"},
+		},
+		Expected: "MESSAGE=hi\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\n" +
+			"TAG_MESSAGE=spoofed\nTAG_PRIORITY=high\nTAG_STACK=deep\nTAG_SYSLOG_IDENTIFIER=other\n",
+	},
+}
+
+func TestJournalEncoder_Encode(t *testing.T) {
+	for _, testCase := range journalEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := testCase.Encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
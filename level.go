@@ -1,6 +1,14 @@
 package loggy
 
-type Level = int
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level is the severity of a message, where a lower level is more severe, and
+// the threshold of a logger. A Level < 0 disables logging.
+type Level int
 
 const (
 	// LevelStd indicates standard log output. Always shown.
@@ -15,6 +23,9 @@ const (
 	LevelInfo
 	// LevelDebug indicates debug output.
 	LevelDebug
+	// LevelTrace indicates output more detailed than debug output, e.g. for
+	// chatty code paths that are only of interest in deep-dive debugging.
+	LevelTrace
 )
 
 // LevelNames describe the alphabetical types to label each Level* with in stdout/stderr.
@@ -25,4 +36,103 @@ var LevelNames = map[Level]string{
 	LevelInfo:     "INFO",
 	LevelWarning:  "WARN",
 	LevelStd:      "OUT",
+	LevelTrace:    "TRACE",
+}
+
+// levelAliases are the alternative names accepted by ParseLevel, in addition to
+// LevelNames.
+var levelAliases = map[string]Level{
+	"CRITICAL": LevelCritical,
+	"WARNING":  LevelWarning,
+	"STD":      LevelStd,
+	"OFF":      -1,
+	"DISABLED": -1,
+}
+
+// ParseLevel parses a level from its label in LevelNames, e.g. "WARN", ignoring
+// case, for configuring thresholds from flags, environment variables, and
+// configuration files. The aliases "critical", "warning", and "std" are also
+// accepted, as are "off" and "disabled", which return -1, and integers.
+func ParseLevel(text string) (Level, error) {
+	name := strings.ToUpper(strings.TrimSpace(text))
+	for level, label := range LevelNames {
+		if name == label {
+			return level, nil
+		}
+	}
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		return Level(n), nil
+	}
+	return 0, fmt.Errorf("unknown level %q", text)
+}
+
+// String returns the level's label in LevelNames, e.g. "WARN", or "disabled"
+// for a Level < 0.
+func (l Level) String() string {
+	return describeLevel(l)
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the level as its
+// String.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding the level with
+// ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Severity orders the levels intuitively, from least to most severe, for
+// comparing levels without the inverted Level numbering, where a lower number
+// is more severe:
+//
+//	SeverityTrace < SeverityDebug < SeverityInfo < SeverityWarning < SeverityError < SeverityCritical
+//
+// SeverityStd sorts above SeverityCritical, since standard messages are always
+// shown. Use SeverityOf and Severity.Level to convert between the two schemes,
+// e.g. Options{Threshold: loggy.SeverityWarning.Level()} shows Warning and more
+// severe messages.
+type Severity int
+
+const (
+	// SeverityTrace is the severity of LevelTrace.
+	SeverityTrace Severity = iota - 1
+	// SeverityDebug is the severity of LevelDebug.
+	SeverityDebug
+	// SeverityInfo is the severity of LevelInfo.
+	SeverityInfo
+	// SeverityWarning is the severity of LevelWarning.
+	SeverityWarning
+	// SeverityError is the severity of LevelError.
+	SeverityError
+	// SeverityCritical is the severity of LevelCritical.
+	SeverityCritical
+	// SeverityStd is the severity of LevelStd.
+	SeverityStd
+)
+
+// SeverityOf returns the severity of the level. Levels beyond LevelTrace map
+// below SeverityTrace, preserving their order.
+func SeverityOf(level Level) Severity {
+	return Severity(LevelDebug - level)
+}
+
+// Level returns the level with this severity.
+func (s Severity) Level() Level {
+	return LevelDebug - Level(s)
+}
+
+// String returns the label of the level with this severity, e.g. "WARN".
+func (s Severity) String() string {
+	return describeLevel(s.Level())
 }
@@ -0,0 +1,112 @@
+package loggy
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var severityTestCases = []struct {
+	Level    Level
+	Severity Severity
+	String   string
+}{
+	{Level: LevelDebug, Severity: SeverityDebug, String: "DEBUG"},
+	{Level: LevelInfo, Severity: SeverityInfo, String: "INFO"},
+	{Level: LevelWarning, Severity: SeverityWarning, String: "WARN"},
+	{Level: LevelError, Severity: SeverityError, String: "ERROR"},
+	{Level: LevelCritical, Severity: SeverityCritical, String: "CRIT"},
+	{Level: LevelStd, Severity: SeverityStd, String: "OUT"},
+	{Level: LevelTrace, Severity: SeverityTrace, String: "TRACE"},
+	{Level: LevelTrace + 1, Severity: SeverityTrace - 1, String: "7"},
+}
+
+func TestSeverityOf(t *testing.T) {
+	for _, testCase := range severityTestCases {
+		t.Run(testCase.String, func(t *testing.T) {
+			assert.Equal(t, testCase.Severity, SeverityOf(testCase.Level))
+			assert.Equal(t, testCase.Level, testCase.Severity.Level())
+			assert.Equal(t, testCase.String, testCase.Severity.String())
+		})
+	}
+}
+
+func TestSeverity_Order(t *testing.T) {
+	assert.True(t, SeverityTrace < SeverityDebug)
+	assert.True(t, SeverityDebug < SeverityInfo)
+	assert.True(t, SeverityInfo < SeverityWarning)
+	assert.True(t, SeverityWarning < SeverityError)
+	assert.True(t, SeverityError < SeverityCritical)
+	assert.True(t, SeverityCritical < SeverityStd)
+	assert.True(t, Entry{Level: LevelError}.Severity() >= SeverityWarning)
+	assert.False(t, Entry{Level: LevelInfo}.Severity() >= SeverityWarning)
+}
+
+func TestSeverity_Threshold(t *testing.T) {
+	for _, testCase := range severityTestCases[:7] {
+		t.Run(testCase.String, func(t *testing.T) {
+			threshold := SeverityWarning.Level()
+			shown := testCase.Level <= threshold
+			assert.Equal(t, testCase.Severity >= SeverityWarning, shown)
+		})
+	}
+}
+
+var parseLevelTestCases = []struct {
+	Text     string
+	Expected Level
+	Error    string
+}{
+	{Text: "WARN", Expected: LevelWarning},
+	{Text: "debug", Expected: LevelDebug},
+	{Text: " Trace ", Expected: LevelTrace},
+	{Text: "critical", Expected: LevelCritical},
+	{Text: "warning", Expected: LevelWarning},
+	{Text: "OUT", Expected: LevelStd},
+	{Text: "std", Expected: LevelStd},
+	{Text: "off", Expected: -1},
+	{Text: "disabled", Expected: -1},
+	{Text: "4", Expected: LevelInfo},
+	{Text: "loud", Error: `unknown level "loud"`},
+	{Text: "", Error: `unknown level ""`},
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range parseLevelTestCases {
+		t.Run(tc.Text, func(t *testing.T) {
+			level, err := ParseLevel(tc.Text)
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Expected, level)
+		})
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	for level := Level(-1); level <= LevelTrace+1; level++ {
+		parsed, err := ParseLevel(level.String())
+		assert.Nil(t, err)
+		assert.Equal(t, level, parsed)
+	}
+	assert.Equal(t, "WARN", LevelWarning.String())
+	assert.Equal(t, "disabled", Level(-1).String())
+	assert.Equal(t, "7", Level(7).String())
+}
+
+func TestLevel_MarshalText(t *testing.T) {
+	type config struct {
+		Threshold Level `json:"threshold"`
+	}
+
+	encoded, err := json.Marshal(config{Threshold: LevelDebug})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"threshold":"DEBUG"}`, string(encoded))
+
+	var decoded config
+	assert.Nil(t, json.Unmarshal([]byte(`{"threshold":"warning"}`), &decoded))
+	assert.Equal(t, LevelWarning, decoded.Threshold)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"threshold":"loud"}`), &decoded), `unknown level "loud"`)
+}
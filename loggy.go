@@ -7,18 +7,51 @@ package loggy
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-const (
-	// ContextKeyLogger is the context.Context key where loggy logger references are stored.
-	ContextKeyLogger = "loggy.Logger"
-	// ContextKeyTags is the context.Context key where loggy tags are stored.
-	ContextKeyTags = "loggy.Tags"
-)
+// contextKey is the type of the context.Context keys owned by this package,
+// which can't collide with keys defined by other packages.
+type contextKey int
+
+// loggerKey is the context.Context key where loggy logger references are stored.
+const loggerKey contextKey = iota
+
+// TagsKey is an opaque context.Context key where a logger stores its tags, see
+// Options.TagsContextKey. Create one with NewTagsKey.
+type TagsKey struct {
+	name string
+}
+
+// NewTagsKey creates a key for storing tags in a context. Loggers whose keys
+// have the same name share their tags.
+func NewTagsKey(name string) TagsKey {
+	return TagsKey{name: name}
+}
+
+// String returns the key's name.
+func (k TagsKey) String() string {
+	return k.name
+}
+
+// DefaultTagsKey is the context.Context key where loggy tags are stored, unless
+// Options.TagsContextKey is set.
+var DefaultTagsKey = NewTagsKey("loggy.Tags")
+
+// SchemaVersion identifies the layout of loggy's structured output, and is
+// written as the "schema" key of each JSONEncoder entry. It changes whenever
+// fields are renamed, removed, or change type, so that consumers can tell which
+// parser to use.
+const SchemaVersion = 1
 
 // Must implement interface.
 var _ Logger = &logger{}
@@ -26,25 +59,86 @@ var _ Logger = &logger{}
 type Logger interface {
 	Log(ctx context.Context, severity Level, message ...interface{}) error
 	Logf(ctx context.Context, severity Level, format string, message ...interface{}) error
+	Emit(ctx context.Context, severity Level, format string, message ...interface{}) (EmitResult, error)
 	Std(ctx context.Context, message ...interface{}) error
 	Stdf(ctx context.Context, format string, message ...interface{}) error
 	Critical(ctx context.Context, message ...interface{}) error
 	Criticalf(ctx context.Context, format string, message ...interface{}) error
+	Panic(ctx context.Context, message ...interface{})
+	Panicf(ctx context.Context, format string, message ...interface{})
+	Fatal(ctx context.Context, message ...interface{}) error
+	Fatalf(ctx context.Context, format string, message ...interface{}) error
+	Error(ctx context.Context, message ...interface{}) error
+	Errorf(ctx context.Context, format string, message ...interface{}) error
 	Warning(ctx context.Context, message ...interface{}) error
 	Warningf(ctx context.Context, format string, message ...interface{}) error
 	Info(ctx context.Context, message ...interface{}) error
 	Infof(ctx context.Context, format string, message ...interface{}) error
 	Debug(ctx context.Context, message ...interface{}) error
 	Debugf(ctx context.Context, format string, message ...interface{}) error
+	Trace(ctx context.Context, message ...interface{}) error
+	Tracef(ctx context.Context, format string, message ...interface{}) error
+	Security(ctx context.Context, event SecurityEvent) error
+	Banner(ctx context.Context, appName, version string) error
 	Tags(ctx context.Context) map[string]interface{}
 	Tag(ctx context.Context, name string) interface{}
 	AddTag(ctx context.Context, name string, value interface{}) (map[string]interface{}, context.Context)
 	RemoveTag(ctx context.Context, name string) (map[string]interface{}, context.Context)
+	SetOutput(out, err io.Writer)
+	SetThreshold(threshold Level)
+	GetThreshold() Level
+	Options() Options
+	Delegate(policy Delegation) Logger
+	Named(name string) Logger
+	Clone(overrides Options) Logger
+	With(tags map[string]interface{}) Logger
+	Describe(w io.Writer) error
+	RecentErrors(n int) []Entry
+	Go(ctx context.Context, name string, fn func(ctx context.Context) error)
+	Workers() []Worker
+	AsyncDropped() int64
+	AsyncExpired() int64
+	Flush() error
+	Close() error
+	AddHook(levels []Level, fn func(entry Entry) error)
+	Reload(options Options)
+	SetIncidentMode(on bool)
+	IncidentMode() (bool, time.Time)
 }
 
 type logger struct {
-	options *Options
-	mux     sync.Mutex
+	options    *Options
+	optionsMux sync.RWMutex
+
+	// The logger this one was derived from, if any. Derived loggers share their
+	// parent's options.
+	parent *logger
+	// The name of this logger, within its parent, if created by a Manager.
+	name string
+	// The policy applied to messages sent through this logger, if delegated.
+	delegation *Delegation
+	// Tracks repetitive messages, when sampling is enabled.
+	sampler *sampler
+	// The options to restore when incident mode is switched off, while it's on.
+	incidentBase *Options
+	// When incident mode was switched on.
+	incidentSince time.Time
+	// The most recent Warning and above entries, if enabled.
+	recentErrors *recentEntries
+	// The call sites already warned about by Options.Diagnostics.
+	diagnosed sync.Map
+	// Tags added to every message, set by With.
+	tags map[string]interface{}
+	// Set to 1 once Close has been called.
+	closed int32
+	// Writes entries in the background, when Options.Async is set.
+	async *asyncQueue
+	// Functions called with each entry written, set by AddHook.
+	hooks *hooks
+	// Counts for the summary logged at shutdown.
+	stats *logStats
+	// The goroutines started by Go that are still running.
+	workers *workers
 
 	Ctx context.Context
 }
@@ -52,26 +146,79 @@ type logger struct {
 // New creates a new wrapper for the log.Logger standard package. The provided
 // threshold determines what level of verbosity the provided stream will receive.
 func New(ctx context.Context, options Options) (*logger, context.Context) {
+	applyDefaults(&options)
 	l := &logger{
 		options: &options,
+		stats:   &logStats{started: options.TimestampFunc()},
+		workers: &workers{running: make(map[string]Worker)},
+		hooks:   &hooks{},
 	}
-	if l.options.Out == nil {
-		l.options.Out = DefaultOptions.Out
-	}
-	if l.options.Err == nil {
-		l.options.Err = DefaultOptions.Err
+	if options.Sampling != nil {
+		l.sampler = newSampler(*options.Sampling)
 	}
-	if l.options.TimestampFormat == "" {
-		l.options.TimestampFormat = DefaultOptions.TimestampFormat
+	if options.RecentErrors > 0 {
+		l.recentErrors = newRecentEntries(options.RecentErrors)
 	}
-	if l.options.TimestampFunc == nil {
-		l.options.TimestampFunc = DefaultOptions.TimestampFunc
+	if options.Async != nil {
+		l.async = newAsyncQueue(*options.Async)
 	}
-	if l.options.TagsContextKey == "" {
-		l.options.TagsContextKey = DefaultOptions.TagsContextKey
+
+	return l, NewContext(ctx, l)
+}
+
+// NewContext returns a copy of the context that carries the logger, for
+// retrieval with FromContext. New already does this for the logger it creates.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in the context by New or NewContext, and whether
+// there was one.
+func FromContext(ctx context.Context) (Logger, bool) {
+	l, ok := ctx.Value(loggerKey).(Logger)
+	return l, ok
+}
+
+// MustFromContext returns the logger stored in the context by New, and panics
+// if there isn't one.
+func MustFromContext(ctx context.Context) Logger {
+	l, ok := FromContext(ctx)
+	if !ok {
+		panic("loggy: no logger in context")
 	}
+	return l
+}
 
-	return l, context.WithValue(ctx, ContextKeyLogger, l)
+// applyDefaults applies the options' Profile, then fills in any missing values
+// from DefaultOptions.
+func applyDefaults(options *Options) {
+	if options.Profile != nil {
+		options.Profile(options)
+	}
+	if options.Out == nil {
+		options.Out = DefaultOptions.Out
+	}
+	if options.Err == nil {
+		options.Err = DefaultOptions.Err
+	}
+	if options.TimestampFormat == "" {
+		options.TimestampFormat = DefaultOptions.TimestampFormat
+	}
+	if options.TimestampFunc == nil {
+		options.TimestampFunc = DefaultOptions.TimestampFunc
+	}
+	if options.TagsContextKey == (TagsKey{}) {
+		options.TagsContextKey = DefaultOptions.TagsContextKey
+	}
+	if options.Color && (!enableColor(options.Out) || !enableColor(options.Err)) {
+		options.Color = false
+	}
+	if options.MaxValueDepth == 0 {
+		options.MaxValueDepth = DefaultOptions.MaxValueDepth
+	}
+	if options.MaxValueItems == 0 {
+		options.MaxValueItems = DefaultOptions.MaxValueItems
+	}
 }
 
 // Log is a wrapper for Logf without the format string.
@@ -83,23 +230,70 @@ func (l *logger) Log(ctx context.Context, severity Level, message ...interface{}
 // the configured output or error stream, depending on severity. By default, log
 // messages are prefixed with: a timestamp, log severity, log function name, and
 // any tags assigned to the context via the *Tag* helper methods. All of these
-// features can be figured via loggy.Options, when using loggy.New().
+// features can be figured via loggy.Options, when using loggy.New(), or for a
+// single message by passing LogOption values along with the message arguments.
 func (l *logger) Logf(ctx context.Context, severity Level, format string, message ...interface{}) error {
-	if l.options.Threshold < 0 {
+	_, err := l.emit(ctx, 3, severity, format, message...)
+	return err
+}
+
+// Emit behaves like Logf, but also reports what happened to the message, e.g.
+// where it was written and how many bytes were written.
+func (l *logger) Emit(ctx context.Context, severity Level, format string, message ...interface{}) (EmitResult, error) {
+	return l.emit(ctx, 2, severity, format, message...)
+}
+
+// emit implements Logf and Emit. The skip argument is the number of stack
+// frames to ascend, from emit, to find the function that requested the log.
+func (l *logger) emit(ctx context.Context, skip int, severity Level, format string, message ...interface{}) (EmitResult, error) {
+	if atomic.LoadInt32(&instrumented) == 1 {
+		defer startInstrumentSample().finish()
+	}
+	options := l.currentOptions()
+	message, overrides := extractLogOptions(message)
+	threshold := l.threshold(options)
+	if options.Threshold < 0 || threshold < 0 {
 		// Logging is disabled.
-		return nil
+		return l.drop(options, skip, DropDisabled, severity, format, message), nil
 	}
-	if severity < 0 || severity+1 > len(LevelNames) {
+	if severity < 0 || int(severity)+1 > len(LevelNames) {
 		severity = LevelStd
 	}
-	if severity != LevelStd && severity > l.options.Threshold {
-		return nil
+	severity, ok := l.applyDelegations(severity)
+	if !ok && !overrides.bypass {
+		return l.drop(options, skip, DropDelegation, severity, format, message), nil
 	}
-	var msg = fmt.Sprintf("%s", LevelNames[severity])
+	if !overrides.bypass {
+		if severity != LevelStd && severity > threshold {
+			return l.drop(options, skip, DropThreshold, severity, format, message), nil
+		}
+		if options.SkipCanceled && severity >= LevelInfo && ctx.Err() != nil {
+			// The work being logged was abandoned.
+			return l.drop(options, skip, DropCanceled, severity, format, message), nil
+		}
+		if sampler := l.root().currentSampler(); sampler != nil && options.Sampling != nil && severity != LevelStd &&
+			!sampler.sample(options.TimestampFunc(), severity, format, message) {
 
-	if !l.options.DisableFunctionName {
+			return l.drop(options, skip, DropSampled, severity, format, message), nil
+		}
+	}
+
+	entry := Entry{
+		Time:   options.TimestampFunc(),
+		Level:  severity,
+		Logger: l.fullName(options),
+		Code:   overrides.code,
+		Prefix: options.Prefix,
+	}
+	if overrides.stack != "" {
+		entry.Stack = overrides.stack
+	} else if options.StacktraceLevel > LevelStd && severity != LevelStd && severity <= options.StacktraceLevel {
+		entry.Stack = stacktrace(skip)
+	}
+
+	if !options.DisableFunctionName && !overrides.skipCaller {
 		// Get calling function name.
-		pc, _, _, ok := runtime.Caller(2)
+		pc, _, _, ok := runtime.Caller(skip)
 		if !ok {
 			lookupErr := fmt.Sprintf(
 				"%s %s %s",
@@ -107,75 +301,169 @@ func (l *logger) Logf(ctx context.Context, severity Level, format string, messag
 				"loggy.logger.Logf",
 				"failed to dynamically lookup function name",
 			)
-			_, err := l.options.Err.Write([]byte(l.maybePrefixTimestamp(lookupErr)))
+			_, err := options.Err.Write([]byte(maybePrefixTimestamp(options, lookupErr)))
 			if err != nil {
-				if l.options.LogFatal {
+				if options.LogFatal {
 					log.Fatal(lookupErr)
 				} else {
-					return err
+					return EmitResult{}, err
 				}
 			}
 		} else {
-			fullName := strings.Split(runtime.FuncForPC(pc).Name(), "/")
-
-			msg = fmt.Sprintf("%s %s", msg, fullName[len(fullName)-1])
+			entry.Caller = callerName(pc)
+			if options.CallerFunc != nil {
+				entry.Caller = options.CallerFunc(entry.Caller)
+			}
 		}
 	}
 
-	if !l.options.DisableTags {
+	root := l.root()
+	if !options.DisableTags || len(options.Destinations) > 0 || len(options.Processors) > 0 || root.hooks.any() {
 		// Compile tags from context.
 		tags := l.Tags(ctx)
-		count := 0
-		if tags != nil && len(tags) > 0 {
-			tagBytes := []byte("[")
+		permanent := l.permanentTags()
+		if len(options.ContextValues) > 0 || len(options.DynamicFields) > 0 || len(permanent) > 0 {
+			merged := make(map[string]interface{}, len(tags)+len(options.ContextValues)+len(options.DynamicFields)+len(permanent))
 			for name, value := range tags {
-				var delim string
-				if count+1 < len(tags) {
-					delim = ", "
+				merged[name] = value
+			}
+			for name, key := range options.ContextValues {
+				if value := ctx.Value(key); value != nil {
+					merged[name] = value
 				}
-				tagBytes = append(tagBytes, []byte(fmt.Sprintf("%s:%v%s", name, value, delim))...)
-				count++
 			}
-			tagBytes = append(tagBytes, []byte("]")...)
-
-			msg = fmt.Sprintf("%s %s", msg, string(tagBytes))
+			for name, fn := range options.DynamicFields {
+				merged[name] = fn(ctx)
+			}
+			for name, value := range permanent {
+				merged[name] = value
+			}
+			tags = merged
 		}
+		entry.Tags = l.delegatedTags(tags)
+	}
+	// Fields are part of the message, so they're kept even if DisableTags hides
+	// the context tags.
+	entry.Fields = l.delegatedFields(overrides.fields)
+	if len(entry.Tags)+len(entry.Fields) > DiagnosticsMaxTags {
+		l.diagnose(skip+1, misuseExcessiveTags)
 	}
 
-	if l.options.Prefix != "" {
-		// Append prefix before the user-formatted message.
-		msg = fmt.Sprintf("%s %s", msg, l.options.Prefix)
+	entry.Message = formatMessage(format, message)
+
+	if len(options.Processors) > 0 && !process(ctx, options.Processors, &entry) && !overrides.bypass {
+		return l.drop(options, skip, DropProcessor, severity, format, message), nil
 	}
 
-	// Append user-formatted message.
-	if format == "" && len(message) > 0 {
-		for i := 0; i < len(message); i++ {
-			format = format + " %v"
+	var routed io.Writer
+	if len(options.Destinations) > 0 {
+		entry.Tags, routed = routeByTag(options, entry.Tags)
+		var routedField io.Writer
+		if entry.Fields, routedField = routeByTag(options, entry.Fields); routedField != nil {
+			routed = routedField
 		}
 	}
-	message = append([]interface{}{msg}, message...)
-	msg = fmt.Sprintf("%s"+format+"\n", message...)
-	if severity == LevelStd || severity >= LevelInfo {
-		_, err := l.options.Out.Write([]byte(l.maybePrefixTimestamp(msg)))
-		if err != nil {
-			if l.options.LogFatal {
-				log.Fatal(msg)
-			} else {
-				return err
-			}
+	if entry.Level > LevelStd && entry.Level <= LevelWarning {
+		if recent := root.recentErrors; recent != nil {
+			recent.add(entry)
 		}
-	} else {
-		_, err := l.options.Err.Write([]byte(l.maybePrefixTimestamp(msg)))
-		if err != nil {
-			if l.options.LogFatal {
-				log.Fatal(msg)
-			} else {
-				return err
-			}
+	}
+
+	if queue := root.async; queue != nil {
+		switch queue.enqueue(asyncEntry{
+			logger:    l,
+			options:   options,
+			entry:     entry,
+			overrides: overrides,
+			routed:    routed,
+		}) {
+		case enqueued:
+			return EmitResult{Queued: true}, nil
+		case rejected:
+			return l.drop(options, skip, DropQueueFull, severity, format, message), nil
 		}
 	}
+	return l.write(options, entry, overrides, routed, skip+1)
+}
+
+// write encodes the entry and writes it to its destination. The skip argument
+// is the number of stack frames to ascend, from write, to find the function
+// that requested the log, or < 0 if the entry is written by the async queue,
+// away from that function.
+func (l *logger) write(options *Options, entry Entry, overrides logOptions, routed io.Writer, skip int) (EmitResult, error) {
+	root := l.root()
+	encoder := options.Encoder
+	if encoder == nil {
+		encoder = TextEncoder{options: options}
+	}
+	encode := func(entry Entry) ([]byte, error) {
+		return encoder.Encode(encoderEntry(options, entry))
+	}
+	buf, err := encode(entry)
+	if err != nil {
+		return EmitResult{}, err
+	}
 
-	return nil
+	result := EmitResult{
+		Destination: options.Err,
+		Output:      buf,
+	}
+	if overrides.destination != nil {
+		result.Destination = overrides.destination
+	} else if overrides.stream == streamOut {
+		result.Destination = options.Out
+	} else if overrides.stream == streamErr {
+		result.Destination = options.Err
+	} else if routed != nil {
+		result.Destination = routed
+	} else if w := options.LevelOutputs[entry.Level]; w != nil {
+		result.Destination = w
+	} else if entry.Level == LevelStd || entry.Level >= LevelInfo {
+		result.Destination = options.Out
+	}
+	stats := root.stats
+	stats.written(entry.Level)
+	output, n, err := writeEntry(result.Destination, encode, entry, buf)
+	if output != nil {
+		result.Output = output
+	}
+	result.Written = n
+	if err != nil {
+		atomic.AddInt64(&stats.writeErrors, 1)
+	}
+	if tee := root.incidentTee(); tee != nil {
+		// The tee is best effort, as below.
+		_, _ = tee.Write(result.Output)
+	}
+	if options.TeeCriticalStderr && entry.Level == LevelCritical && result.Destination != os.Stderr {
+		// The tee is best effort; only the main destination's error is returned.
+		_, _ = os.Stderr.Write(result.Output)
+	}
+	if err != nil && isClosedError(err) && skip >= 0 {
+		l.diagnose(skip+1, misuseAfterClose)
+	}
+	hookErr := root.hooks.fire(entry)
+	if err != nil {
+		if options.LogFatal {
+			log.Fatal(string(buf))
+		} else {
+			return result, err
+		}
+	}
+
+	return result, hookErr
+}
+
+// formatMessage compiles the user-formatted message. Without a format, the
+// arguments are separated by spaces.
+func formatMessage(format string, message []interface{}) string {
+	if format == "" && len(message) > 0 {
+		format = strings.Repeat(" %v", len(message))[1:]
+	}
+	if format == "" {
+		return ""
+	}
+	return fmt.Sprintf(format, message...)
 }
 
 // Std sends a standard log message.
@@ -198,6 +486,62 @@ func (l *logger) Criticalf(ctx context.Context, format string, message ...interf
 	return l.Logf(ctx, LevelCritical, format, message...)
 }
 
+// Panic sends a critical error message, regardless of the threshold, then
+// panics with the message, as log.Panic does.
+func (l *logger) Panic(ctx context.Context, message ...interface{}) {
+	_ = l.Logf(ctx, LevelCritical, "", append(message, Bypass())...)
+	_ = l.Flush()
+	message, _ = extractLogOptions(message)
+	panic(formatMessage("", message))
+}
+
+// Panicf sends a critical error message, with a custom string format, then
+// panics with the message, as log.Panicf does.
+func (l *logger) Panicf(ctx context.Context, format string, message ...interface{}) {
+	_ = l.Logf(ctx, LevelCritical, format, append(message, Bypass())...)
+	_ = l.Flush()
+	message, _ = extractLogOptions(message)
+	panic(formatMessage(format, message))
+}
+
+// Fatal sends a critical error message, regardless of the threshold, then exits
+// with status 1 via Options.ExitFunc. An error is only returned if ExitFunc
+// returns.
+func (l *logger) Fatal(ctx context.Context, message ...interface{}) error {
+	err := l.Logf(ctx, LevelCritical, "", append(message, Bypass())...)
+	l.exit(1)
+	return err
+}
+
+// Fatalf sends a critical error message, with a custom string format, then
+// exits as with Fatal.
+func (l *logger) Fatalf(ctx context.Context, format string, message ...interface{}) error {
+	err := l.Logf(ctx, LevelCritical, format, append(message, Bypass())...)
+	l.exit(1)
+	return err
+}
+
+// exit calls Options.ExitFunc, or Exit if it's nil, once any queued messages
+// have been written.
+func (l *logger) exit(code int) {
+	_ = l.Flush()
+	if exit := l.currentOptions().ExitFunc; exit != nil {
+		exit(code)
+		return
+	}
+	Exit(code)
+}
+
+// Error sends an error message.
+func (l *logger) Error(ctx context.Context, message ...interface{}) error {
+	return l.Logf(ctx, LevelError, "", message...)
+}
+
+// Errorf sends an error message, with a custom string format.
+func (l *logger) Errorf(ctx context.Context, format string, message ...interface{}) error {
+	return l.Logf(ctx, LevelError, format, message...)
+}
+
 // Warning sends a warning error message.
 func (l *logger) Warning(ctx context.Context, message ...interface{}) error {
 	return l.Logf(ctx, LevelWarning, "", message...)
@@ -228,12 +572,20 @@ func (l *logger) Debugf(ctx context.Context, format string, message ...interface
 	return l.Logf(ctx, LevelDebug, format, message...)
 }
 
-// Tags returns all tags associated with the provided context.
-func (l *logger) Tags(ctx context.Context) map[string]interface{} {
-	l.mux.Lock()
-	defer l.mux.Unlock()
+// Trace sends a trace log message, more detailed than a debug message.
+func (l *logger) Trace(ctx context.Context, message ...interface{}) error {
+	return l.Logf(ctx, LevelTrace, "", message...)
+}
+
+// Tracef sends a trace log message, with a custom string format.
+func (l *logger) Tracef(ctx context.Context, format string, message ...interface{}) error {
+	return l.Logf(ctx, LevelTrace, format, message...)
+}
 
-	tags, ok := ctx.Value(l.options.TagsContextKey).(map[string]interface{})
+// Tags returns all tags associated with the provided context. The returned map
+// is shared with the context, and must not be modified.
+func (l *logger) Tags(ctx context.Context) map[string]interface{} {
+	tags, ok := ctx.Value(l.currentOptions().TagsContextKey).(map[string]interface{})
 	if !ok {
 		tags = make(map[string]interface{})
 	}
@@ -243,10 +595,7 @@ func (l *logger) Tags(ctx context.Context) map[string]interface{} {
 
 // Tag returns an individual tag, by name, associated with the provided context.
 func (l *logger) Tag(ctx context.Context, name string) interface{} {
-	l.mux.Lock()
-	defer l.mux.Unlock()
-
-	tags, ok := ctx.Value(l.options.TagsContextKey).(map[string]interface{})
+	tags, ok := ctx.Value(l.currentOptions().TagsContextKey).(map[string]interface{})
 	if !ok {
 		return nil
 	}
@@ -258,50 +607,303 @@ func (l *logger) Tag(ctx context.Context, name string) interface{} {
 	return tag
 }
 
-// AddTag adds or updates a tag, by name, associated with the provided context.
-//
-// NOTE: Be wary of adding tags in any goroutines if there's any possibility of
-// duplicate tag names. Although loggy uses mutexes to ensure there's no race
-// condition, there's no guarantee as to which value will be stored last.
+// AddTag adds or updates a tag, by name, returning a context with the updated
+// tags. The provided context is not modified, so contexts derived from it
+// before the call, e.g. by other goroutines, keep their own tags. The returned
+// map is shared with the returned context, and must not be modified.
 func (l *logger) AddTag(ctx context.Context, name string, value interface{}) (map[string]interface{}, context.Context) {
-	l.mux.Lock()
-	defer l.mux.Unlock()
-
-	tags, ok := ctx.Value(l.options.TagsContextKey).(map[string]interface{})
-	if !ok {
-		tags = make(map[string]interface{})
+	key := l.currentOptions().TagsContextKey
+	tags, _ := ctx.Value(key).(map[string]interface{})
+	if tags == nil && l.currentOptions().Diagnostics && l.hasForeignTags(ctx) {
+		l.diagnose(2, misuseForeignTags)
 	}
-	if name != "" {
-		tags[name] = value
-		ctx = context.WithValue(ctx, l.options.TagsContextKey, tags)
+	if name == "" {
+		if tags == nil {
+			tags = make(map[string]interface{})
+		}
+		return tags, ctx
+	}
+
+	// Tags are copied on write, so that they can be read without locking.
+	updated := make(map[string]interface{}, len(tags)+1)
+	for n, v := range tags {
+		updated[n] = v
 	}
+	updated[name] = value
 
-	return tags, ctx
+	return updated, context.WithValue(ctx, key, updated)
 }
 
-// RemoveTag removes a tag, by name, associated with the provided context.
+// RemoveTag removes a tag, by name, returning a context with the updated tags.
+// As with AddTag, the provided context is not modified.
 func (l *logger) RemoveTag(ctx context.Context, name string) (map[string]interface{}, context.Context) {
-	l.mux.Lock()
-	defer l.mux.Unlock()
+	key := l.currentOptions().TagsContextKey
+	tags, _ := ctx.Value(key).(map[string]interface{})
+	if _, ok := tags[name]; !ok {
+		if tags == nil {
+			tags = make(map[string]interface{})
+		}
+		return tags, ctx
+	}
 
-	tags, ok := ctx.Value(l.options.TagsContextKey).(map[string]interface{})
-	if !ok {
-		tags = make(map[string]interface{})
+	updated := make(map[string]interface{}, len(tags))
+	for n, v := range tags {
+		if n != name {
+			updated[n] = v
+		}
 	}
-	if name != "" {
-		delete(tags, name)
-		ctx = context.WithValue(ctx, l.options.TagsContextKey, tags)
+
+	return updated, context.WithValue(ctx, key, updated)
+}
+
+// formatTags compiles tags into a bracketed list, sorted by name, e.g.
+// "[bacon:2, waffles:1]".
+func formatTags(options *Options, tags map[string]interface{}) string {
+	return string(appendTags(nil, options, tags))
+}
+
+// appendTags appends the output of formatTags to buf.
+func appendTags(buf []byte, options *Options, tags map[string]interface{}) []byte {
+	return appendPreparedTags(buf, prepareTags(options, tags))
+}
+
+// appendPreparedTags appends tags that have been through prepareTags.
+func appendPreparedTags(buf []byte, tags map[string]interface{}) []byte {
+	fields := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		fields[name] = formatTagValue(value)
+	}
+
+	// Sort by name, so tags are output in a consistent order.
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf = append(buf, '[')
+	for i, name := range names {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = append(append(buf, name...), ':')
+		buf = appendTagValue(buf, fields[name])
+	}
+	return append(buf, ']')
+}
+
+// prepareTags bounds each tag value with Sanitize and, if Options.FlattenTags
+// is set, flattens it, returning a new map.
+func prepareTags(options *Options, tags map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		value = Sanitize(value, options.MaxValueDepth, options.MaxValueItems)
+		if options.FlattenTags {
+			for key, flattened := range Flatten(name, value) {
+				fields[key] = flattened
+			}
+		} else {
+			fields[name] = value
+		}
 	}
+	return fields
+}
+
+// appendTagValue appends a formatted tag value, as with the %v verb.
+func appendTagValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(buf, v...)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	}
+	return append(buf, fmt.Sprint(value)...)
+}
+
+// formatTagValue prepares an individual tag value for text output.
+func formatTagValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return formatError(err)
+	}
+	return textValue(value)
+}
+
+// SetOutput swaps the output and error streams used by the logger. It is safe
+// to call while other goroutines are logging, so a long-lived logger can be
+// redirected (e.g. from stdout to a file after daemonizing) without losing any
+// of its configuration. A nil writer falls back to the DefaultOptions stream.
+// Derived loggers share their parent's streams, so the change applies to the
+// whole family of loggers.
+func (l *logger) SetOutput(out, err io.Writer) {
+	if l.parent != nil {
+		l.parent.SetOutput(out, err)
+		return
+	}
+	if out == nil {
+		out = DefaultOptions.Out
+	}
+	if err == nil {
+		err = DefaultOptions.Err
+	}
+
+	l.optionsMux.Lock()
+	defer l.optionsMux.Unlock()
+
+	if l.incidentBase != nil {
+		// Keep the change when incident mode is switched off.
+		base := *l.incidentBase
+		base.Out = out
+		base.Err = err
+		l.incidentBase = &base
+		l.options = incidentOptions(&base)
+		return
+	}
+	options := *l.options
+	options.Out = out
+	options.Err = err
+	l.options = &options
+}
+
+// SetThreshold changes the threshold used by the logger, e.g. to LevelDebug
+// while diagnosing a live problem, without creating a new logger. It is safe to
+// call while other goroutines are logging. Derived loggers share their parent's
+// threshold, so the change applies to the whole family of loggers, although
+// Options.Thresholds still overrides it for matching named loggers. While
+// incident mode is on, the change takes effect once it's switched off.
+func (l *logger) SetThreshold(threshold Level) {
+	if l.parent != nil {
+		l.parent.SetThreshold(threshold)
+		return
+	}
+
+	l.optionsMux.Lock()
+	defer l.optionsMux.Unlock()
+
+	if l.incidentBase != nil {
+		// Keep the change when incident mode is switched off.
+		base := *l.incidentBase
+		base.Threshold = threshold
+		l.incidentBase = &base
+		l.options = incidentOptions(&base)
+		return
+	}
+	options := *l.options
+	options.Threshold = threshold
+	l.options = &options
+}
+
+// GetThreshold returns the threshold currently applied to the logger's
+// messages, taking into account Options.Thresholds and incident mode.
+func (l *logger) GetThreshold() Level {
+	return l.threshold(l.currentOptions())
+}
+
+// Options returns a copy of the logger's effective configuration, after any
+// missing values have been filled in from DefaultOptions. Changing the returned
+// value, including its maps and slices, has no effect on the logger. While
+// incident mode is on, it returns the configuration to restore once it's
+// switched off, rather than the incident overrides, so that it can be modified
+// and passed to Reload.
+func (l *logger) Options() Options {
+	root := l.root()
+
+	root.optionsMux.RLock()
+	defer root.optionsMux.RUnlock()
+
+	if root.incidentBase != nil {
+		return root.incidentBase.clone()
+	}
+	return root.options.clone()
+}
+
+// root returns the logger that this one was derived from, or itself if it
+// wasn't derived.
+func (l *logger) root() *logger {
+	for l.parent != nil {
+		l = l.parent
+	}
+	return l
+}
+
+// currentSampler returns the root logger's sampler, if sampling is enabled.
+func (l *logger) currentSampler() *sampler {
+	l.optionsMux.RLock()
+	defer l.optionsMux.RUnlock()
+
+	return l.sampler
+}
+
+// fullName returns the logger's name, prefixed by the names of the loggers it
+// was derived from and Options.Name, separated by dots.
+func (l *logger) fullName(options *Options) string {
+	var names []string
+	for d := l; d != nil; d = d.parent {
+		if d.name != "" {
+			names = append([]string{d.name}, names...)
+		}
+	}
+	if options.Name != "" {
+		names = append([]string{options.Name}, names...)
+	}
+	return strings.Join(names, ".")
+}
+
+// With creates a child logger that adds the tags to every message, e.g. a
+// component logger that always tags "component=db", without the tags having to
+// be added to each context. The tags replace any context tags of the same name,
+// and are themselves replaced by any Fields passed with a message. The child
+// shares the parent's options, so changes such as SetOutput apply to both.
+func (l *logger) With(tags map[string]interface{}) Logger {
+	permanent := make(map[string]interface{}, len(tags))
+	for name, value := range tags {
+		permanent[name] = value
+	}
+	return &logger{
+		parent: l,
+		tags:   permanent,
+	}
+}
+
+// permanentTags merges the tags set by With on this logger and its parents,
+// where a child's tags replace its parents'. It returns nil if there are none.
+func (l *logger) permanentTags() map[string]interface{} {
+	var merged map[string]interface{}
+	for d := l; d != nil; d = d.parent {
+		for name, value := range d.tags {
+			if merged == nil {
+				merged = make(map[string]interface{}, len(d.tags))
+			}
+			if _, ok := merged[name]; !ok {
+				merged[name] = value
+			}
+		}
+	}
+	return merged
+}
+
+// currentOptions returns the options in effect at the time of the call. The
+// returned value must be treated as read-only, since any changes are made by
+// swapping in a modified copy.
+func (l *logger) currentOptions() *Options {
+	if l.parent != nil {
+		return l.parent.currentOptions()
+	}
+
+	l.optionsMux.RLock()
+	defer l.optionsMux.RUnlock()
 
-	return tags, ctx
+	return l.options
 }
 
-func (l *logger) maybePrefixTimestamp(msg string) string {
-	if !l.options.DisableTimestamps {
-		msg = fmt.Sprintf(
-			"%s %s",
-			l.options.TimestampFunc().
-				Format(l.options.TimestampFormat), msg)
+func maybePrefixTimestamp(options *Options, msg string) string {
+	if options.DisableTimestamps {
+		return msg
 	}
-	return msg
+	buf := appendTimestamp(make([]byte, 0, 32+len(msg)), options, options.TimestampFunc())
+	buf = append(append(buf, ' '), msg...)
+	return string(buf)
 }
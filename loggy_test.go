@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
 	"regexp"
+	"sync"
 	"testing"
 )
 
@@ -14,8 +18,8 @@ var logTestCases = []struct {
 	Name                string
 	Message             string
 	Prefix              string
-	Severity            int
-	Threshold           int
+	Severity            Level
+	Threshold           Level
 	ExpectedStdoutRegex *regexp.Regexp
 	ExpectedStderrRegex *regexp.Regexp
 }{
@@ -120,6 +124,48 @@ var logTestCases = []struct {
 	},
 }
 
+func TestFromContext(t *testing.T) {
+	l, ctx := New(context.Background(), Options{})
+	_, ctx = l.AddTag(ctx, "request", 1)
+
+	found, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Logger(l), found)
+	assert.Equal(t, Logger(l), MustFromContext(ctx))
+
+	found, ok = FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Nil(t, found)
+	assert.PanicsWithValue(t, "loggy: no logger in context", func() {
+		MustFromContext(context.Background())
+	})
+}
+
+func TestNewContext(t *testing.T) {
+	l, _ := New(context.Background(), Options{})
+	ctx := NewContext(context.Background(), l.Named("db"))
+
+	found, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "db", found.(*logger).name)
+
+	// Another package's string key can't shadow the logger, or its tags.
+	ctx = context.WithValue(ctx, "loggy.Tags", map[string]interface{}{"foreign": true})
+	assert.Empty(t, found.Tags(ctx))
+}
+
+func TestNewTagsKey(t *testing.T) {
+	first, ctx := New(context.Background(), Options{TagsContextKey: NewTagsKey("shared")})
+	second, _ := New(context.Background(), Options{TagsContextKey: NewTagsKey("shared")})
+	third, _ := New(context.Background(), Options{})
+	_, ctx = first.AddTag(ctx, "request", 1)
+
+	assert.Equal(t, "shared", first.Options().TagsContextKey.String())
+	assert.Equal(t, DefaultTagsKey, third.Options().TagsContextKey)
+	assert.Equal(t, map[string]interface{}{"request": 1}, second.Tags(ctx))
+	assert.Empty(t, third.Tags(ctx))
+}
+
 func TestLogger_Log(t *testing.T) {
 	for _, testCase := range logTestCases {
 		t.Run(testCase.Name, func(t *testing.T) {
@@ -189,3 +235,520 @@ func TestLogger_Tags(t *testing.T) {
 	assert.Equal(t, 2, l.Tag(ctx, "bacon"))
 	assert.Equal(t, 3, l.Tag(ctx, "waffles"))
 }
+
+func TestLogger_AddTag_CopyOnWrite(t *testing.T) {
+	l, ctx := New(context.Background(), Options{})
+	_, parent := l.AddTag(ctx, "request", 1)
+	_, child := l.AddTag(parent, "user", "bob")
+	_, removed := l.RemoveTag(child, "request")
+
+	assert.Equal(t, map[string]interface{}{"request": 1}, l.Tags(parent))
+	assert.Equal(t, map[string]interface{}{"request": 1, "user": "bob"}, l.Tags(child))
+	assert.Equal(t, map[string]interface{}{"user": "bob"}, l.Tags(removed))
+}
+
+func TestLogger_With(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 buf,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	db := l.With(map[string]interface{}{"component": "db", "shard": 1})
+	replica := db.With(map[string]interface{}{"shard": 2})
+	_, ctx = l.AddTag(ctx, "request", 7)
+	_, shadowed := l.AddTag(ctx, "component", "http")
+
+	assert.Nil(t, db.Info(ctx, "connected"))
+	assert.Nil(t, replica.Info(shadowed, "connected"))
+	assert.Nil(t, replica.Info(ctx, "slow", Fields(map[string]interface{}{"shard": 3})))
+	assert.Nil(t, l.Info(ctx, "done"))
+
+	assert.Equal(t, ""+
+		"INFO [component:db, request:7, shard:1] connected\n"+
+		"INFO [component:db, request:7, shard:2] connected\n"+
+		"INFO [component:db, request:7, shard:3] slow\n"+
+		"INFO [request:7] done\n",
+		buf.String())
+	assert.Equal(t, map[string]interface{}{"request": 7}, l.Tags(ctx))
+}
+
+func TestLogger_Tags_Concurrent(t *testing.T) {
+	options := Options{
+		Out:       ioutil.Discard,
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, ctx := l.AddTag(ctx, "worker", i)
+			for j := 0; j < 100; j++ {
+				assert.Nil(t, l.Info(ctx, "working"))
+				assert.Equal(t, i, l.Tag(ctx, "worker"))
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Nil(t, l.Tag(ctx, "worker"))
+}
+
+func TestLogger_SetOutput(t *testing.T) {
+	first := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 first,
+		Err:                 first,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "waffles", 1)
+	assert.Nil(t, l.Info(ctx, "before"))
+
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	l.SetOutput(stdout, stderr)
+	assert.Nil(t, l.Info(ctx, "after"))
+	assert.Nil(t, l.Critical(ctx, "after"))
+
+	assert.Equal(t, "INFO [waffles:1] before\n", first.String())
+	assert.Equal(t, "INFO [waffles:1] after\n", stdout.String())
+	assert.Equal(t, "CRIT [waffles:1] after\n", stderr.String())
+}
+
+func TestLogger_SetOutput_Concurrent(t *testing.T) {
+	options := Options{
+		Out:       bytes.NewBuffer([]byte{}),
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.SetOutput(ioutil.Discard, ioutil.Discard)
+			assert.Nil(t, l.Info(ctx, "racing"))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLogger_SetThreshold(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 buf,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	db := l.Named("db")
+
+	assert.Nil(t, db.Debug(ctx, "hidden"))
+	db.SetThreshold(LevelDebug)
+	assert.Equal(t, LevelDebug, l.GetThreshold())
+	assert.Equal(t, LevelDebug, db.GetThreshold())
+	assert.Nil(t, db.Debug(ctx, "shown"))
+	assert.Nil(t, l.Debug(ctx, "shown"))
+
+	l.SetThreshold(LevelWarning)
+	assert.Nil(t, l.Info(ctx, "hidden"))
+	assert.Equal(t, "DEBUG db shown\nDEBUG shown\n", buf.String())
+}
+
+func TestLogger_SetThreshold_Incident(t *testing.T) {
+	options := Options{
+		Out:       ioutil.Discard,
+		Threshold: LevelInfo,
+		Incident:  &Incident{Threshold: LevelTrace},
+	}
+	l, _ := New(context.Background(), options)
+
+	l.SetIncidentMode(true)
+	l.SetThreshold(LevelWarning)
+	assert.Equal(t, LevelTrace, l.GetThreshold())
+	l.SetIncidentMode(false)
+	assert.Equal(t, LevelWarning, l.GetThreshold())
+}
+
+func TestLogger_SetThreshold_Concurrent(t *testing.T) {
+	options := Options{
+		Out:       ioutil.Discard,
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l.SetThreshold(LevelInfo + Level(i%2))
+			assert.Nil(t, l.Debug(ctx, "racing"))
+			_ = l.GetThreshold()
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLogger_Options(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Threshold: LevelDebug,
+		Prefix:    "~~~",
+	}
+	l, _ := New(context.Background(), options)
+
+	effective := l.Options()
+	assert.Equal(t, stdout, effective.Out)
+	assert.Equal(t, DefaultOptions.Err, effective.Err)
+	assert.Equal(t, LevelDebug, effective.Threshold)
+	assert.Equal(t, "~~~", effective.Prefix)
+	assert.Equal(t, DefaultOptions.TimestampFormat, effective.TimestampFormat)
+	assert.Equal(t, DefaultOptions.TagsContextKey, effective.TagsContextKey)
+	assert.NotNil(t, effective.TimestampFunc)
+
+	// Modifying the copy must not leak back into the logger.
+	effective.Prefix = "!!!"
+	assert.Equal(t, "~~~", l.Options().Prefix)
+}
+
+func TestLogger_Options_DeepCopy(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:          stdout,
+		Threshold:    LevelInfo,
+		Thresholds:   map[string]Level{"db": LevelDebug},
+		Destinations: map[string]io.Writer{"audit": stdout},
+		LevelOutputs: map[Level]io.Writer{LevelError: stdout},
+		Processors:   []Processor{func(ctx context.Context, entry *Entry) bool { return true }},
+		Sampling:     &Sampling{Initial: 1},
+	}
+	l, _ := New(context.Background(), options)
+
+	effective := l.Options()
+	effective.Thresholds["db"] = LevelTrace
+	effective.Thresholds["*"] = LevelTrace
+	delete(effective.Destinations, "audit")
+	effective.LevelOutputs[LevelWarning] = stdout
+	effective.Processors[0] = nil
+	effective.Sampling.Initial = 100
+
+	actual := l.Options()
+	assert.Equal(t, map[string]Level{"db": LevelDebug}, actual.Thresholds)
+	assert.Equal(t, map[string]io.Writer{"audit": stdout}, actual.Destinations)
+	assert.Equal(t, map[Level]io.Writer{LevelError: stdout}, actual.LevelOutputs)
+	assert.NotNil(t, actual.Processors[0])
+	assert.Equal(t, 1, actual.Sampling.Initial)
+}
+
+func TestLogger_Infof(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Threshold: LevelInfo,
+	}
+	l, ctx := New(context.Background(), options)
+	l.Infof(ctx, "%d messages", 2)
+
+	regex := regexp.MustCompile(timestampRegexp + " INFO loggy.TestLogger_Infof 2 messages\n$")
+	assert.Regexp(t, regex, stdout.String())
+}
+
+func TestLogger_Trace(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Threshold:         LevelDebug,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+	assert.Nil(t, l.Trace(ctx, "hidden"))
+	l.Reload(Options{Out: stdout, Threshold: LevelTrace, DisableTimestamps: true})
+	stdout.Reset()
+	assert.Nil(t, l.Trace(ctx, "some detail"))
+	assert.Nil(t, l.Tracef(ctx, "%d details", 2))
+
+	assert.Equal(t, "TRACE loggy.TestLogger_Trace some detail\nTRACE loggy.TestLogger_Trace 2 details\n", stdout.String())
+}
+
+func TestLogger_Error(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Err:       stderr,
+		Threshold: LevelError,
+	}
+	l, ctx := New(context.Background(), options)
+	assert.Nil(t, l.Error(ctx, "some error"))
+	assert.Nil(t, l.Errorf(ctx, "%d errors", 2))
+	assert.Nil(t, l.Warning(ctx, "ignored"))
+
+	regex := regexp.MustCompile("^" + timestampRegexp + " ERROR loggy.TestLogger_Error some error\n" +
+		timestampRegexp + " ERROR loggy.TestLogger_Error 2 errors\n$")
+	assert.Regexp(t, regex, stderr.String())
+	assert.Empty(t, stdout.String())
+}
+
+func TestLogger_Panic(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:               stderr,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	// Shown despite the threshold, which only allows standard messages.
+	assert.PanicsWithValue(t, "out of disk 2", func() {
+		l.Panic(ctx, "out of disk", 2, SkipCaller())
+	})
+	assert.PanicsWithValue(t, "2 disks full", func() {
+		l.Panicf(ctx, "%d disks full", 2)
+	})
+	regex := regexp.MustCompile(`^CRIT out of disk 2\nCRIT loggy.TestLogger_Panic.func2 2 disks full\n$`)
+	assert.Regexp(t, regex, stderr.String())
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	var codes []int
+	options := Options{
+		Err:                 stderr,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		ExitFunc: func(code int) {
+			codes = append(codes, code)
+		},
+	}
+	l, ctx := New(context.Background(), options)
+
+	// Shown despite the threshold, which only allows standard messages.
+	assert.Nil(t, l.Fatal(ctx, "out of disk"))
+	assert.Nil(t, l.Fatalf(ctx, "%d disks full", 2))
+	assert.Equal(t, "CRIT out of disk\nCRIT 2 disks full\n", stderr.String())
+	assert.Equal(t, []int{1, 1}, codes)
+}
+
+func TestLogger_Fatal_Exit(t *testing.T) {
+	code := stubExit(t)
+	var flushed []string
+	defer FlushOnExit(&flushRecorder{name: "logs", flushed: &flushed})()
+	l, ctx := New(context.Background(), Options{Err: bytes.NewBuffer([]byte{})})
+
+	assert.Nil(t, l.Fatal(ctx, "out of disk"))
+	assert.Equal(t, 1, *code)
+	assert.Equal(t, []string{"logs"}, flushed)
+}
+
+func TestLogger_Log_SkipCanceled(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stderr,
+		Threshold:           LevelDebug,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		SkipCanceled:        true,
+	}
+	l, ctx := New(context.Background(), options)
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assert.Nil(t, l.Debug(ctx, "tearing down"))
+	assert.Nil(t, l.Info(ctx, "tearing down"))
+	assert.Nil(t, l.Std(ctx, "result"))
+	assert.Nil(t, l.Warning(ctx, "abandoned"))
+
+	assert.Equal(t, "OUT result\n", stdout.String())
+	assert.Equal(t, "WARN abandoned\n", stderr.String())
+}
+
+func TestLogger_Emit(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Err:               stderr,
+		Threshold:         LevelWarning,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	result, err := l.Emit(ctx, LevelCritical, "%d failures", 3)
+	assert.Nil(t, err)
+	assert.False(t, result.Filtered)
+	assert.Equal(t, stderr, result.Destination)
+	assert.Equal(t, "CRIT loggy.TestLogger_Emit 3 failures\n", string(result.Output))
+	assert.Equal(t, len(result.Output), result.Written)
+	assert.Equal(t, string(result.Output), stderr.String())
+
+	result, err = l.Emit(ctx, LevelInfo, "ignored")
+	assert.Nil(t, err)
+	assert.True(t, result.Filtered)
+	assert.Nil(t, result.Destination)
+	assert.Equal(t, 0, result.Written)
+	assert.Equal(t, "", stdout.String())
+}
+
+func TestOptions_Name(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:              "api",
+		Out:               stdout,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "named"))
+	assert.Equal(t, "INFO api loggy.TestOptions_Name named\n", stdout.String())
+}
+
+func TestOptions_DynamicFields(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	inFlight := 0
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		DynamicFields: map[string]func(ctx context.Context) interface{}{
+			"in_flight": func(ctx context.Context) interface{} {
+				inFlight++
+				return inFlight
+			},
+			"shard": func(ctx context.Context) interface{} {
+				return "eu-1"
+			},
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "shard", "us-1")
+
+	assert.Nil(t, l.Info(ctx, "first"))
+	assert.Nil(t, l.Info(ctx, "second", Fields(map[string]interface{}{"shard": "override"})))
+	assert.Nil(t, l.Debug(ctx, "filtered"))
+
+	assert.Equal(t,
+		"INFO [in_flight:1, shard:eu-1] first\nINFO [in_flight:2, shard:override] second\n",
+		stdout.String(),
+	)
+	// The context's tags are left untouched.
+	assert.Equal(t, map[string]interface{}{"shard": "us-1"}, l.Tags(ctx))
+}
+
+var appendTagValueTestCases = []struct {
+	Name     string
+	Value    interface{}
+	Expected string
+}{
+	{Name: "string", Value: "bob", Expected: "bob"},
+	{Name: "int", Value: -42, Expected: "-42"},
+	{Name: "int64", Value: int64(42), Expected: "42"},
+	{Name: "bool", Value: true, Expected: "true"},
+	{Name: "nil", Value: nil, Expected: "<nil>"},
+	{Name: "slice", Value: []int{1, 2}, Expected: "[1 2]"},
+}
+
+func TestAppendTagValue(t *testing.T) {
+	for _, testCase := range appendTagValueTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, "x="+testCase.Expected, string(appendTagValue([]byte("x="), testCase.Value)))
+		})
+	}
+}
+
+type contextValuesTestKey struct{}
+
+func TestOptions_ContextValues(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		ContextValues: map[string]interface{}{
+			"user.id":    contextValuesTestKey{},
+			"request.id": "request-id",
+		},
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "anonymous"))
+	ctx = context.WithValue(ctx, contextValuesTestKey{}, 42)
+	assert.Nil(t, l.Info(ctx, "signed in"))
+
+	assert.Equal(t, "INFO anonymous\nINFO [user.id:42] signed in\n", stdout.String())
+}
+
+func TestOptions_TeeCriticalStderr(t *testing.T) {
+	stderr, err := ioutil.TempFile(t.TempDir(), "stderr")
+	assert.Nil(t, err)
+	defer stderr.Close()
+	original := os.Stderr
+	os.Stderr = stderr
+	defer func() {
+		os.Stderr = original
+	}()
+
+	sink := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 sink,
+		Err:                 sink,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		TeeCriticalStderr:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Error(ctx, "recovered"))
+	assert.Nil(t, l.Critical(ctx, "out of disk"))
+	l.SetOutput(sink, os.Stderr)
+	assert.Nil(t, l.Critical(ctx, "still out of disk"))
+
+	teed, err := ioutil.ReadFile(stderr.Name())
+	assert.Nil(t, err)
+	assert.Equal(t, "CRIT out of disk\nCRIT still out of disk\n", string(teed))
+	assert.Equal(t, "ERROR recovered\nCRIT out of disk\n", sink.String())
+}
+
+func TestOptions_LevelOutputs(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	alerts := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out: stdout,
+		Err: stderr,
+		LevelOutputs: map[Level]io.Writer{
+			LevelCritical: alerts,
+			LevelWarning:  stdout,
+		},
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Critical(ctx, "paging"))
+	assert.Nil(t, l.Error(ctx, "failed"))
+	assert.Nil(t, l.Warning(ctx, "careful"))
+	assert.Nil(t, l.Info(ctx, "hello"))
+	assert.Nil(t, l.Critical(ctx, "forced", ToErr()))
+
+	assert.Equal(t, "CRIT paging\n", alerts.String())
+	assert.Equal(t, "ERROR failed\nCRIT forced\n", stderr.String())
+	assert.Equal(t, "WARN careful\nINFO hello\n", stdout.String())
+}
@@ -0,0 +1,128 @@
+// Package loggybench runs standard workloads against a loggy configuration and
+// reports how long each message takes to log and how much it allocates, so that
+// custom formats and destinations can be compared against the defaults.
+package loggybench
+
+import (
+	"context"
+	"fmt"
+	"github.com/foresthoffman/loggy"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// Workload logs a single message, as one benchmark operation.
+type Workload struct {
+	Name string
+	Log  func(ctx context.Context, l loggy.Logger) error
+}
+
+// tenFields are the tags logged by the TenFields workload.
+var tenFields = func() map[string]interface{} {
+	fields := make(map[string]interface{}, 10)
+	for i := 0; i < 10; i++ {
+		fields[fmt.Sprintf("field%d", i)] = i
+	}
+	return fields
+}()
+
+// largePayload is the message logged by the LargePayload workload.
+var largePayload = strings.Repeat("lorem ipsum ", 1024)
+
+var (
+	// Simple logs a short message, with no tags.
+	Simple = Workload{
+		Name: "simple",
+		Log: func(ctx context.Context, l loggy.Logger) error {
+			return l.Info(ctx, "request handled")
+		},
+	}
+	// TenFields logs a short message, with ten tags.
+	TenFields = Workload{
+		Name: "ten-fields",
+		Log: func(ctx context.Context, l loggy.Logger) error {
+			return l.Info(ctx, "request handled", loggy.Fields(tenFields))
+		},
+	}
+	// LargePayload logs a 12KiB message.
+	LargePayload = Workload{
+		Name: "large-payload",
+		Log: func(ctx context.Context, l loggy.Logger) error {
+			return l.Info(ctx, largePayload)
+		},
+	}
+)
+
+// Workloads are the standard workloads, run by Run and Benchmark.
+var Workloads = []Workload{Simple, TenFields, LargePayload}
+
+// Result is the cost of logging a single message of a workload.
+type Result struct {
+	Workload    string
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s\t%d ns/op\t%d B/op\t%d allocs/op", r.Workload, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+}
+
+// Run benchmarks each of the Workloads against a logger created with options,
+// outside of a test binary. Output and error streams that aren't set are
+// discarded, rather than written to stdout and stderr.
+func Run(options loggy.Options) []Result {
+	results := make([]Result, 0, len(Workloads))
+	for _, workload := range Workloads {
+		workload := workload
+		result := testing.Benchmark(func(b *testing.B) {
+			bench(b, options, workload)
+		})
+		results = append(results, Result{
+			Workload:    workload.Name,
+			NsPerOp:     result.NsPerOp(),
+			AllocsPerOp: result.AllocsPerOp(),
+			BytesPerOp:  result.AllocedBytesPerOp(),
+		})
+	}
+	return results
+}
+
+// Benchmark runs each of the Workloads as a sub-benchmark of b, against a logger
+// created with options, as described by Run:
+//
+//	func BenchmarkJSON(b *testing.B) {
+//		loggybench.Benchmark(b, loggy.Options{Out: file, Threshold: loggy.LevelInfo})
+//	}
+func Benchmark(b *testing.B, options loggy.Options) {
+	for _, workload := range Workloads {
+		workload := workload
+		b.Run(workload.Name, func(b *testing.B) {
+			bench(b, options, workload)
+		})
+	}
+}
+
+func bench(b *testing.B, options loggy.Options, workload Workload) {
+	l, ctx := New(options)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := workload.Log(ctx, l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// New creates the logger that workloads are run against.
+func New(options loggy.Options) (loggy.Logger, context.Context) {
+	if options.Out == nil {
+		options.Out = ioutil.Discard
+	}
+	if options.Err == nil {
+		options.Err = ioutil.Discard
+	}
+	return loggy.New(context.Background(), options)
+}
@@ -0,0 +1,36 @@
+package loggybench
+
+import (
+	"bytes"
+	"github.com/foresthoffman/loggy"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestWorkloads(t *testing.T) {
+	for _, workload := range Workloads {
+		t.Run(workload.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer([]byte{})
+			l, ctx := New(loggy.Options{
+				Out:                 buf,
+				Threshold:           loggy.LevelInfo,
+				DisableFunctionName: true,
+				DisableTimestamps:   true,
+			})
+
+			assert.Nil(t, workload.Log(ctx, l))
+			assert.True(t, strings.HasPrefix(buf.String(), "INFO "))
+			assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		})
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	result := Result{Workload: "simple", NsPerOp: 250, AllocsPerOp: 3, BytesPerOp: 128}
+	assert.Equal(t, "simple\t250 ns/op\t128 B/op\t3 allocs/op", result.String())
+}
+
+func BenchmarkDefaults(b *testing.B) {
+	Benchmark(b, loggy.Options{Threshold: loggy.LevelInfo})
+}
@@ -0,0 +1,53 @@
+package loggytest
+
+import (
+	"github.com/foresthoffman/loggy"
+	"strings"
+	"testing"
+)
+
+// AssertNotLogged fails the test if any message captured by buf contains text.
+// It's useful for checking that a hot path stays quiet, or that a particular
+// warning is no longer produced.
+func AssertNotLogged(t testing.TB, buf *Buffer, text string) {
+	t.Helper()
+
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.Contains(line, text) {
+			t.Errorf("unexpected log message containing %q:\n%s", text, line)
+		}
+	}
+}
+
+// AssertNoEntriesAbove fails the test if buf captured any message that is more
+// severe than level, e.g. AssertNoEntriesAbove(t, buf, loggy.LevelWarning)
+// fails on any LevelError or LevelCritical message. LevelStd messages are never
+// considered severe.
+func AssertNoEntriesAbove(t testing.TB, buf *Buffer, level loggy.Level) {
+	t.Helper()
+
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		severity, ok := entryLevel(line)
+		if ok && severity != loggy.LevelStd && severity < level {
+			t.Errorf("unexpected %s log message:\n%s", loggy.LevelNames[severity], line)
+		}
+	}
+}
+
+// entryLevel returns the level labelled on a line of text output, which follows
+// the timestamp when there is one. Lines without a label, such as the lines of
+// a stack trace, are not entries.
+func entryLevel(line string) (loggy.Level, bool) {
+	fields := strings.Fields(line)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	for _, field := range fields {
+		for level, name := range loggy.LevelNames {
+			if field == name {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
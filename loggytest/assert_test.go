@@ -0,0 +1,75 @@
+package loggytest
+
+import (
+	"context"
+	"github.com/foresthoffman/loggy"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var entryLevelTestCases = []struct {
+	Name          string
+	Line          string
+	ExpectedLevel loggy.Level
+	ExpectedOK    bool
+}{
+	{
+		Name:          "timestamp",
+		Line:          "2006-01-02T15:04:05Z WARN loggytest.TestThing low on syrup\n",
+		ExpectedLevel: loggy.LevelWarning,
+		ExpectedOK:    true,
+	},
+	{
+		Name:          "no-timestamp",
+		Line:          "ERROR loggytest.TestThing out of syrup\n",
+		ExpectedLevel: loggy.LevelError,
+		ExpectedOK:    true,
+	},
+	{
+		Name:          "message-only",
+		Line:          "2006-01-02T15:04:05Z INFO loggytest.TestThing CRIT\n",
+		ExpectedLevel: loggy.LevelInfo,
+		ExpectedOK:    true,
+	},
+	{
+		Name:       "stack-trace",
+		Line:       "\tloggytest.TestThing()\n",
+		ExpectedOK: false,
+	},
+}
+
+func TestEntryLevel(t *testing.T) {
+	for _, testCase := range entryLevelTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			level, ok := entryLevel(testCase.Line)
+			assert.Equal(t, testCase.ExpectedLevel, level)
+			assert.Equal(t, testCase.ExpectedOK, ok)
+		})
+	}
+}
+
+func TestAssertNotLogged(t *testing.T) {
+	l, ctx, buf := New(context.Background())
+	assert.Nil(t, l.Info(ctx, "breakfast is served"))
+
+	mock := &testing.T{}
+	AssertNotLogged(mock, buf, "syrup")
+	assert.False(t, mock.Failed())
+
+	AssertNotLogged(mock, buf, "breakfast")
+	assert.True(t, mock.Failed())
+}
+
+func TestAssertNoEntriesAbove(t *testing.T) {
+	l, ctx, buf := New(context.Background())
+	assert.Nil(t, l.Std(ctx, "breakfast is served"))
+	assert.Nil(t, l.Warning(ctx, "low on syrup"))
+
+	mock := &testing.T{}
+	AssertNoEntriesAbove(mock, buf, loggy.LevelWarning)
+	assert.False(t, mock.Failed())
+
+	assert.Nil(t, l.Logf(ctx, loggy.LevelError, "out of syrup"))
+	AssertNoEntriesAbove(mock, buf, loggy.LevelWarning)
+	assert.True(t, mock.Failed())
+}
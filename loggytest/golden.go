@@ -0,0 +1,38 @@
+package loggytest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateGolden causes AssertGolden to rewrite golden files with the output it
+// receives, rather than comparing against them. It defaults to true when the
+// LOGGYTEST_UPDATE environment variable is set, and may also be bound to a flag
+// by the calling test package.
+var UpdateGolden = os.Getenv("LOGGYTEST_UPDATE") != ""
+
+// AssertGolden fails the test if got does not match the contents of the golden
+// file at path.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+
+	if UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, set LOGGYTEST_UPDATE=1 to create it: %s", err)
+	}
+	if string(got) != string(expected) {
+		t.Errorf("output does not match golden file %s\ngot:\n%s\nexpected:\n%s", path, got, expected)
+	}
+}
@@ -0,0 +1,72 @@
+package loggytest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+)
+
+var (
+	// ErrEmptyEntry is returned by CheckEntry for an entry with no content.
+	ErrEmptyEntry = errors.New("entry is empty")
+	// ErrUnterminatedEntry is returned by CheckEntry for an entry that doesn't
+	// end with a newline.
+	ErrUnterminatedEntry = errors.New("entry is not terminated by a newline")
+	// ErrMultilineEntry is returned by CheckEntry for an entry that spans more
+	// than one line.
+	ErrMultilineEntry = errors.New("entry spans multiple lines")
+	// ErrInvalidUTF8 is returned by CheckEntry for an entry that isn't valid
+	// UTF-8.
+	ErrInvalidUTF8 = errors.New("entry is not valid UTF-8")
+	// ErrNilFields is returned by CheckFields for a nil map.
+	ErrNilFields = errors.New("fields are nil")
+	// ErrEmptyFieldName is returned by CheckFields for a field without a name.
+	ErrEmptyFieldName = errors.New("field name is empty")
+)
+
+// CheckEntry reports whether a single formatted entry meets loggy's output
+// contract: it's valid UTF-8, and fits on exactly one line, terminated by a
+// newline. Log collectors rely on this to split entries, so any formatter
+// should pass CheckEntry for every input, making it a useful property for
+// fuzz tests.
+func CheckEntry(entry []byte) error {
+	if len(entry) == 0 {
+		return ErrEmptyEntry
+	}
+	if entry[len(entry)-1] != '\n' {
+		return ErrUnterminatedEntry
+	}
+	if i := bytes.IndexByte(entry, '\n'); i < len(entry)-1 {
+		return fmt.Errorf("%w: newline at byte %d", ErrMultilineEntry, i)
+	}
+	if !utf8.Valid(entry) {
+		return ErrInvalidUTF8
+	}
+
+	return nil
+}
+
+// CheckFields reports whether the fields passed to a formatter meet loggy's
+// contract: the map is non-nil, even when there are no fields, and every field
+// has a name.
+func CheckFields(fields map[string]interface{}) error {
+	if fields == nil {
+		return ErrNilFields
+	}
+	if _, ok := fields[""]; ok {
+		return ErrEmptyFieldName
+	}
+
+	return nil
+}
+
+// AssertEntry fails the test if entry does not pass CheckEntry.
+func AssertEntry(t testing.TB, entry []byte) {
+	t.Helper()
+
+	if err := CheckEntry(entry); err != nil {
+		t.Errorf("invalid entry %q: %s", entry, err)
+	}
+}
@@ -0,0 +1,95 @@
+package loggytest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var checkEntryTestCases = []struct {
+	Name     string
+	Entry    string
+	Expected error
+}{
+	{
+		Name:     "valid",
+		Entry:    "2006-01-02T15:04:05Z INFO main.run started\n",
+		Expected: nil,
+	},
+	{
+		Name:     "empty",
+		Entry:    "",
+		Expected: ErrEmptyEntry,
+	},
+	{
+		Name:     "unterminated",
+		Entry:    "INFO main.run started",
+		Expected: ErrUnterminatedEntry,
+	},
+	{
+		Name:     "multiline",
+		Entry:    "INFO main.run started\nagain\n",
+		Expected: ErrMultilineEntry,
+	},
+	{
+		Name:     "invalid-utf8",
+		Entry:    "INFO main.run \xff\n",
+		Expected: ErrInvalidUTF8,
+	},
+}
+
+func TestCheckEntry(t *testing.T) {
+	for _, testCase := range checkEntryTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := CheckEntry([]byte(testCase.Entry))
+			if testCase.Expected == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, testCase.Expected)
+			}
+		})
+	}
+}
+
+var checkFieldsTestCases = []struct {
+	Name     string
+	Fields   map[string]interface{}
+	Expected error
+}{
+	{
+		Name:     "empty",
+		Fields:   map[string]interface{}{},
+		Expected: nil,
+	},
+	{
+		Name:     "nil-value",
+		Fields:   map[string]interface{}{"user": nil},
+		Expected: nil,
+	},
+	{
+		Name:     "nil",
+		Fields:   nil,
+		Expected: ErrNilFields,
+	},
+	{
+		Name:     "empty-name",
+		Fields:   map[string]interface{}{"": 1},
+		Expected: ErrEmptyFieldName,
+	},
+}
+
+func TestCheckFields(t *testing.T) {
+	for _, testCase := range checkFieldsTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, CheckFields(testCase.Fields))
+		})
+	}
+}
+
+func TestAssertEntry(t *testing.T) {
+	mock := &testing.T{}
+	AssertEntry(mock, []byte("INFO main.run started\n"))
+	assert.False(t, mock.Failed())
+
+	AssertEntry(mock, []byte("INFO main.run started"))
+	assert.True(t, mock.Failed())
+}
@@ -0,0 +1,102 @@
+// Package loggytest provides helpers for testing code that logs through loggy.
+// Loggers created by this package produce deterministic output: timestamps are
+// fixed, tags are sorted by name, and caller names are normalized, so captured
+// logs can be compared byte-for-byte against golden files.
+package loggytest
+
+import (
+	"bytes"
+	"context"
+	"github.com/foresthoffman/loggy"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FixedTime is the timestamp written on every message by loggers configured
+// with Options.
+var FixedTime = time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+// closureRegexp matches the segments the Go runtime appends to the names of
+// anonymous functions, e.g. "func1" or the "2" in "func1.2".
+var closureRegexp = regexp.MustCompile(`^(func[0-9]+|[0-9]+)$`)
+
+// Options returns logger options that write both streams to out with a fixed
+// timestamp and normalized caller names. The threshold is LevelTrace, so that
+// every message is captured.
+func Options(out io.Writer) loggy.Options {
+	return loggy.Options{
+		Out:       out,
+		Err:       out,
+		Threshold: loggy.LevelTrace,
+		TimestampFunc: func() time.Time {
+			return FixedTime
+		},
+		CallerFunc: NormalizeCaller,
+	}
+}
+
+// New creates a deterministic logger, as described by Options, that writes to
+// the returned Buffer.
+func New(ctx context.Context) (loggy.Logger, context.Context, *Buffer) {
+	buf := &Buffer{}
+	l, ctx := loggy.New(ctx, Options(buf))
+
+	return l, ctx, buf
+}
+
+// NormalizeCaller strips the compiler-generated closure suffixes from a function
+// name, e.g. "pkg.TestThing.func1.2" becomes "pkg.TestThing". These suffixes
+// are numbered by their position in the source file, so they change whenever
+// unrelated closures are added or removed.
+func NormalizeCaller(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if i > 0 && closureRegexp.MatchString(part) {
+			return strings.Join(parts[:i], ".")
+		}
+	}
+
+	return name
+}
+
+// Buffer is a bytes.Buffer that is safe for concurrent use, so it can capture
+// output from loggers shared between goroutines.
+type Buffer struct {
+	buf bytes.Buffer
+	mux sync.Mutex
+}
+
+// Write appends p to the buffer.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return b.buf.Write(p)
+}
+
+// Bytes returns a copy of the buffered output.
+func (b *Buffer) Bytes() []byte {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return append([]byte{}, b.buf.Bytes()...)
+}
+
+// String returns the buffered output as a string.
+func (b *Buffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return b.buf.String()
+}
+
+// Reset discards all buffered output.
+func (b *Buffer) Reset() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.buf.Reset()
+}
@@ -0,0 +1,57 @@
+package loggytest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var normalizeCallerTestCases = []struct {
+	Name     string
+	Caller   string
+	Expected string
+}{
+	{
+		Name:     "function",
+		Caller:   "loggytest.TestThing",
+		Expected: "loggytest.TestThing",
+	},
+	{
+		Name:     "method",
+		Caller:   "loggy.(*logger).Logf",
+		Expected: "loggy.(*logger).Logf",
+	},
+	{
+		Name:     "closure",
+		Caller:   "loggytest.TestThing.func1",
+		Expected: "loggytest.TestThing",
+	},
+	{
+		Name:     "nested-closure",
+		Caller:   "loggytest.TestThing.func1.2",
+		Expected: "loggytest.TestThing",
+	},
+}
+
+func TestNormalizeCaller(t *testing.T) {
+	for _, testCase := range normalizeCallerTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, NormalizeCaller(testCase.Caller))
+		})
+	}
+}
+
+func TestNew_Golden(t *testing.T) {
+	l, ctx, buf := New(context.Background())
+	_, ctx = l.AddTag(ctx, "waffles", 1)
+	_, ctx = l.AddTag(ctx, "bacon", 2)
+	_, ctx = l.AddTag(ctx, "eggs", 3)
+
+	func() {
+		assert.Nil(t, l.Info(ctx, "breakfast is served"))
+	}()
+	assert.Nil(t, l.Critical(ctx, "out of syrup"))
+	assert.Nil(t, l.Debugf(ctx, "%d pancakes remaining", 0))
+
+	AssertGolden(t, "testdata/golden.log", buf.Bytes())
+}
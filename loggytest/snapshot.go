@@ -0,0 +1,60 @@
+package loggytest
+
+import (
+	"regexp"
+	"testing"
+)
+
+// Replacement rewrites every match of Pattern in captured output, with With.
+// With may refer to submatches, as in regexp.Regexp.ReplaceAll.
+type Replacement struct {
+	Pattern *regexp.Regexp
+	With    string
+}
+
+// VolatileFields normalizes the parts of captured output that change from run
+// to run, so that output from loggers not created by New can still be compared
+// against a snapshot. Append to it to normalize other values, e.g. request IDs.
+var VolatileFields = []Replacement{
+	{
+		// Leading timestamps, in any of the RFC3339 or epoch formats.
+		Pattern: regexp.MustCompile(`(?m)^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})|\d{10}(\.\d+)?|\d{13}) `),
+		With:    "<time> ",
+	},
+	{
+		// Tags holding durations, e.g. "mq.duration:1.5ms".
+		Pattern: regexp.MustCompile(`([\[ ][^\[ :]*duration:)[^,\]]+`),
+		With:    "${1}<duration>",
+	},
+	{
+		// Pointer addresses, e.g. in stack traces or formatted pointers.
+		Pattern: regexp.MustCompile(`0x[0-9a-f]+`),
+		With:    "0x<addr>",
+	},
+	{
+		// Goroutine IDs in stack traces.
+		Pattern: regexp.MustCompile(`goroutine \d+ `),
+		With:    "goroutine <id> ",
+	},
+}
+
+// Snapshot returns a copy of captured output with the VolatileFields
+// normalized.
+func Snapshot(output []byte) []byte {
+	snapshot := append([]byte{}, output...)
+	for _, replacement := range VolatileFields {
+		snapshot = replacement.Pattern.ReplaceAll(snapshot, []byte(replacement.With))
+	}
+
+	return snapshot
+}
+
+// AssertSnapshot fails the test if the Snapshot of output does not match the
+// snapshot file at path. Like AssertGolden, the file is rewritten instead when
+// UpdateGolden is set. Storing snapshots alongside the tests locks down what a
+// service logs, so that changes to it show up in review.
+func AssertSnapshot(t testing.TB, path string, output []byte) {
+	t.Helper()
+
+	AssertGolden(t, path, Snapshot(output))
+}
@@ -0,0 +1,68 @@
+package loggytest
+
+import (
+	"context"
+	"github.com/foresthoffman/loggy"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var snapshotTestCases = []struct {
+	Name     string
+	Output   string
+	Expected string
+}{
+	{
+		Name:     "rfc3339",
+		Output:   "2021-06-01T12:30:00Z INFO main.run started\n",
+		Expected: "<time> INFO main.run started\n",
+	},
+	{
+		Name:     "rfc3339-nano",
+		Output:   "2021-06-01T12:30:00.123456789-07:00 INFO main.run started\n",
+		Expected: "<time> INFO main.run started\n",
+	},
+	{
+		Name:     "epoch-millis",
+		Output:   "1622550600123 INFO main.run started\n",
+		Expected: "<time> INFO main.run started\n",
+	},
+	{
+		Name:     "duration",
+		Output:   "INFO main.run [job.duration:1.5ms, job.name:nightly] job finished\n",
+		Expected: "INFO main.run [job.duration:<duration>, job.name:nightly] job finished\n",
+	},
+	{
+		Name:     "stack-trace",
+		Output:   "goroutine 42 [running]:\nmain.run(0xc000012345)\n",
+		Expected: "goroutine <id> [running]:\nmain.run(0x<addr>)\n",
+	},
+	{
+		Name:     "message",
+		Output:   "INFO main.run took 2006-01-02T15:04:05Z ",
+		Expected: "INFO main.run took 2006-01-02T15:04:05Z ",
+	},
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, testCase := range snapshotTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, string(Snapshot([]byte(testCase.Output))))
+		})
+	}
+}
+
+func TestAssertSnapshot(t *testing.T) {
+	buf := &Buffer{}
+	options := Options(buf)
+	options.TimestampFunc = time.Now
+	l, ctx := loggy.New(context.Background(), options)
+
+	start := time.Now()
+	_, ctx = l.AddTag(ctx, "job.name", "nightly-report")
+	_, ctx = l.AddTag(ctx, "job.duration", time.Since(start))
+	assert.Nil(t, l.Info(ctx, "job finished"))
+
+	AssertSnapshot(t, "testdata/snapshot.log", buf.Bytes())
+}
@@ -0,0 +1,120 @@
+package loggy
+
+import (
+	"io"
+)
+
+// LogOption overrides the logger's behavior for a single message. LogOptions are
+// passed along with the message arguments, to any of the logging methods, and
+// are removed from the message before it is formatted:
+//
+//	l.Info(ctx, "user deleted", loggy.Bypass(), loggy.Fields(map[string]interface{}{"actor": id}))
+type LogOption func(o *logOptions)
+
+type logOptions struct {
+	skipCaller  bool
+	destination io.Writer
+	fields      map[string]interface{}
+	bypass      bool
+	code        string
+	stream      stream
+	stack       string
+}
+
+// stream identifies one of the logger's output streams.
+type stream int
+
+const (
+	streamDefault stream = iota
+	streamOut
+	streamErr
+)
+
+// SkipCaller omits the calling function name from the message.
+func SkipCaller() LogOption {
+	return func(o *logOptions) {
+		o.skipCaller = true
+	}
+}
+
+// withStack sets the entry's stack trace, e.g. of a recovered panic, regardless
+// of Options.StacktraceLevel.
+func withStack(stack string) LogOption {
+	return func(o *logOptions) {
+		o.stack = stack
+	}
+}
+
+// ForceDestination writes the message to w, instead of the output or error
+// stream selected by its severity.
+func ForceDestination(w io.Writer) LogOption {
+	return func(o *logOptions) {
+		o.destination = w
+	}
+}
+
+// ToOut writes the message to the output stream, regardless of its severity,
+// e.g. a user-facing result of a CLI tool that is also logged as an Error.
+func ToOut() LogOption {
+	return func(o *logOptions) {
+		o.stream = streamOut
+	}
+}
+
+// ToErr writes the message to the error stream, regardless of its severity.
+func ToErr() LogOption {
+	return func(o *logOptions) {
+		o.stream = streamErr
+	}
+}
+
+// Fields adds tags to the message, without adding them to the context. They
+// replace any context tags of the same name.
+func Fields(fields map[string]interface{}) LogOption {
+	return func(o *logOptions) {
+		if o.fields == nil {
+			o.fields = make(map[string]interface{}, len(fields))
+		}
+		for name, value := range fields {
+			o.fields[name] = value
+		}
+	}
+}
+
+// Bypass writes the message regardless of the threshold, delegation policies,
+// and any other filtering, e.g. for audit lines that must never be dropped.
+// Processors still see the message, but can't drop it, and it waits for room
+// if the async queue is full. The message is still dropped if
+// logging is disabled entirely.
+func Bypass() LogOption {
+	return func(o *logOptions) {
+		o.bypass = true
+	}
+}
+
+// extractLogOptions separates any LogOptions from the message arguments.
+func extractLogOptions(message []interface{}) ([]interface{}, logOptions) {
+	var o logOptions
+	var found bool
+	for _, m := range message {
+		if _, ok := m.(LogOption); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return message, o
+	}
+
+	filtered := make([]interface{}, 0, len(message))
+	for _, m := range message {
+		if option, ok := m.(LogOption); ok {
+			if option != nil {
+				option(&o)
+			}
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered, o
+}
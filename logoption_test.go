@@ -0,0 +1,74 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLogOption(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	audit := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Err:               stderr,
+		Threshold:         LevelWarning,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", 1)
+
+	// Filtered without overrides.
+	assert.Nil(t, l.Info(ctx, "hidden"))
+	assert.Equal(t, "", stdout.String())
+
+	assert.Nil(t, l.Infof(ctx, "user %s deleted", "bob",
+		Bypass(),
+		SkipCaller(),
+		ForceDestination(audit),
+		Fields(map[string]interface{}{"actor": "admin", "request": 2}),
+	))
+	assert.Equal(t, "INFO [actor:admin, request:2] user bob deleted\n", audit.String())
+	assert.Equal(t, "", stdout.String())
+
+	// Fields must not leak into the context.
+	assert.Equal(t, map[string]interface{}{"request": 1}, l.Tags(ctx))
+
+	assert.Nil(t, l.Warning(ctx, "plain", SkipCaller()))
+	assert.Equal(t, "WARN [request:1] plain\n", stderr.String())
+}
+
+func TestLogOption_Disabled(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Threshold: -1,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Std(ctx, "quiet", Bypass()))
+	assert.Equal(t, "", stdout.String())
+}
+
+func TestLogOption_Stream(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	audit := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Error(ctx, "3 files failed to copy", ToOut()))
+	assert.Nil(t, l.Info(ctx, "progress", ToErr()))
+	assert.Nil(t, l.Info(ctx, "audited", ToErr(), ForceDestination(audit)))
+	assert.Equal(t, "ERROR 3 files failed to copy\n", stdout.String())
+	assert.Equal(t, "INFO progress\n", stderr.String())
+	assert.Equal(t, "INFO audited\n", audit.String())
+}
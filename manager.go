@@ -0,0 +1,140 @@
+package loggy
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Manager owns the streams, hooks, and Options.Async queue shared by a family
+// of named loggers, e.g. one logger per subsystem of a large application. The
+// named loggers are lightweight: they share the Manager's options, streams,
+// hooks, and queue rather than each having their own, so a change such as
+// SetOutput applies to all of them, and everything only needs to be flushed and
+// shut down once, via the Manager. It is safe for concurrent use.
+type Manager struct {
+	root    *logger
+	mux     sync.Mutex
+	loggers map[string]*logger
+	// The hook sinks added by AddHookSink, in the order they were added.
+	sinks []HookSink
+}
+
+// HookSink is a hook with resources of its own to flush and close, such as a
+// Sentry or Webhook, which deliver entries in the background.
+type HookSink interface {
+	Capture(entry Entry) error
+	Flush() error
+	Close() error
+}
+
+// NewManager creates a Manager whose loggers use the provided options.
+func NewManager(ctx context.Context, options Options) (*Manager, context.Context) {
+	root, ctx := New(ctx, options)
+	return &Manager{
+		root:    root,
+		loggers: make(map[string]*logger),
+	}, ctx
+}
+
+// Logger returns the logger with the given name, creating it on first use. The
+// name is output after the severity, following Options.Name if one is set, e.g.
+// "app.db". An empty name returns the Manager's root logger.
+func (m *Manager) Logger(name string) Logger {
+	if name == "" {
+		return m.root
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	l, ok := m.loggers[name]
+	if !ok {
+		l = &logger{
+			parent: m.root,
+			name:   name,
+		}
+		m.loggers[name] = l
+	}
+	return l
+}
+
+// Names returns the names of the loggers created so far, sorted.
+func (m *Manager) Names() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	names := make([]string, 0, len(m.loggers))
+	for name := range m.loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AddHook registers fn to be called with the entries of every logger, as
+// described by Logger.AddHook.
+func (m *Manager) AddHook(levels []Level, fn func(entry Entry) error) {
+	m.root.AddHook(levels, fn)
+}
+
+// AddHookSink registers the sink's Capture method as a hook of every logger, as
+// described by Logger.AddHook, and hands the sink to the Manager, which flushes
+// it in Flush and closes it in Close.
+func (m *Manager) AddHookSink(levels []Level, sink HookSink) {
+	m.mux.Lock()
+	m.sinks = append(m.sinks, sink)
+	m.mux.Unlock()
+
+	m.root.AddHook(levels, sink.Capture)
+}
+
+// SetOutput swaps the output and error streams of every logger, as described by
+// Logger.SetOutput.
+func (m *Manager) SetOutput(out, err io.Writer) {
+	m.root.SetOutput(out, err)
+}
+
+// Flush writes any queued messages, then commits any data buffered by the
+// shared streams, as described by Logger.Flush, then flushes the hook sinks,
+// returning the first error encountered.
+func (m *Manager) Flush() error {
+	first := m.root.Flush()
+	for _, sink := range m.hookSinks() {
+		if err := sink.Flush(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close shuts down the queue, then flushes and closes the shared streams, as
+// described by Logger.Close, then closes the hook sinks, so that they capture
+// every queued message first. It returns the first error encountered. The
+// loggers must not be used after calling Close. Calling Close more than once
+// has no effect.
+func (m *Manager) Close() error {
+	first := m.root.Close()
+
+	m.mux.Lock()
+	sinks := m.sinks
+	m.sinks = nil
+	m.mux.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// hookSinks returns a copy of the hook sinks.
+func (m *Manager) hookSinks() []HookSink {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return append([]HookSink(nil), m.sinks...)
+}
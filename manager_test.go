@@ -0,0 +1,131 @@
+package loggy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+)
+
+// closeRecorder counts how many times it has been closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed int
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:                "app",
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	m, ctx := NewManager(context.Background(), options)
+
+	db := m.Logger("db")
+	assert.Same(t, db, m.Logger("db"))
+	assert.Nil(t, db.Info(ctx, "connected"))
+	assert.Nil(t, m.Logger("api").Info(ctx, "listening"))
+	assert.Nil(t, m.Logger("").Info(ctx, "started"))
+	assert.Equal(t, []string{"api", "db"}, m.Names())
+
+	// The loggers share the manager's streams.
+	moved := bytes.NewBuffer([]byte{})
+	m.SetOutput(moved, nil)
+	assert.Nil(t, db.Info(ctx, "moved"))
+
+	assert.Equal(t, "INFO app.db connected\nINFO app.api listening\nINFO app started\n", stdout.String())
+	assert.Equal(t, "INFO app.db moved\n", moved.String())
+}
+
+func TestManager_Close(t *testing.T) {
+	sink := &closeRecorder{}
+	audit := &closeRecorder{}
+	buffered := bufio.NewWriter(sink)
+	options := Options{
+		Out:                 buffered,
+		Err:                 sink,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Destinations:        map[string]io.Writer{"audit": audit, "copy": audit},
+	}
+	m, ctx := NewManager(context.Background(), options)
+
+	assert.Nil(t, m.Logger("db").Info(ctx, "buffered"))
+	assert.Equal(t, "", sink.String())
+
+	assert.Nil(t, m.Close())
+	assert.Equal(t, "INFO db buffered\n", sink.String())
+	assert.Equal(t, 1, sink.closed)
+	assert.Equal(t, 1, audit.closed)
+}
+
+// hookSinkRecorder records the entries it captures, and how many times it was
+// flushed and closed.
+type hookSinkRecorder struct {
+	mux      sync.Mutex
+	captured []string
+	flushed  int
+	closed   int
+}
+
+func (r *hookSinkRecorder) Capture(entry Entry) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.captured = append(r.captured, entry.Message)
+	return nil
+}
+
+func (r *hookSinkRecorder) Flush() error {
+	r.flushed++
+	return nil
+}
+
+func (r *hookSinkRecorder) Close() error {
+	r.closed++
+	return nil
+}
+
+func TestManager_HookSinks(t *testing.T) {
+	options := Options{
+		Out:       &lockedBuffer{},
+		Threshold: LevelInfo,
+		Async:     &Async{},
+	}
+	m, ctx := NewManager(context.Background(), options)
+	sink := &hookSinkRecorder{}
+	m.AddHookSink([]Level{LevelInfo}, sink)
+	var hooked int
+	m.AddHook(nil, func(entry Entry) error {
+		hooked++
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, m.Logger("db").Infof(ctx, "%d", i))
+	}
+	assert.Nil(t, m.Flush())
+	assert.Len(t, sink.captured, 10)
+	assert.Equal(t, 1, sink.flushed)
+
+	// Close drains the queue into the sink before closing it.
+	assert.Nil(t, m.Logger("api").Info(ctx, "last"))
+	assert.Nil(t, m.Close())
+	assert.Nil(t, m.Close())
+	assert.Len(t, sink.captured, 11)
+	assert.Equal(t, "last", sink.captured[10])
+	assert.Equal(t, 11, hooked)
+	assert.Equal(t, 1, sink.closed)
+}
@@ -0,0 +1,138 @@
+package loggy
+
+import (
+	"sync/atomic"
+)
+
+// memory is the budget shared by every buffering feature, e.g. MergeWriter.
+var memory = &memoryBudget{}
+
+// entryOverhead and tagOverhead approximate the memory used by an Entry, and by
+// each of its tags, beyond the text they hold.
+const (
+	entryOverhead = 256
+	tagOverhead   = 64
+)
+
+// SetMemoryLimit caps the total memory, in bytes, that loggy may use to buffer
+// messages, across every buffering feature in the process. When the limit is
+// reached, each feature applies its own eviction policy, e.g. MergeWriter
+// writes out its oldest entries early, rather than buffering more. A limit <= 0
+// removes the cap, which is the default.
+//
+// The queue of Options.Async drops messages as configured by Async.Overflow,
+// or, with OverflowBlock, keeps queueing them, raising the Pressure. The
+// entries kept for Options.RecentErrors always count, replacing older ones. A
+// NetWriter returns ErrBacklogFull rather than grow its backlog, and a
+// SpoolWriter counts each record while it's replayed.
+func SetMemoryLimit(limit int64) {
+	atomic.StoreInt64(&memory.limit, limit)
+}
+
+// MemoryUsage returns the memory, in bytes, currently used to buffer messages.
+func MemoryUsage() int64 {
+	return atomic.LoadInt64(&memory.used)
+}
+
+// Pressure returns the fraction of the memory limit currently in use, from 0 to
+// 1, or 0 if there is no limit. It rises as buffered messages back up, e.g.
+// while a destination is slow, and gives applications a chance to shed their
+// own load, e.g. by reducing verbosity, before messages start being evicted.
+func Pressure() float64 {
+	return memory.pressure()
+}
+
+// OnPressure registers fn to be called, in its own goroutine, each time
+// Pressure rises to or above threshold, having previously been below it.
+// Passing a nil fn removes the callback.
+func OnPressure(threshold float64, fn func(pressure float64)) {
+	atomic.StoreInt32(&memory.pressured, 0)
+	memory.callback.Store(pressureCallback{
+		threshold: threshold,
+		fn:        fn,
+	})
+}
+
+type memoryBudget struct {
+	limit int64
+	used  int64
+
+	// The pressureCallback registered by OnPressure.
+	callback atomic.Value
+	// 1 while the pressure is at or above the callback's threshold.
+	pressured int32
+}
+
+type pressureCallback struct {
+	threshold float64
+	fn        func(pressure float64)
+}
+
+func (b *memoryBudget) pressure() float64 {
+	limit := atomic.LoadInt64(&b.limit)
+	if limit <= 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&b.used)) / float64(limit)
+}
+
+// notify calls the OnPressure callback if the pressure has crossed its
+// threshold, and re-arms it once the pressure has fallen below.
+func (b *memoryBudget) notify() {
+	callback, _ := b.callback.Load().(pressureCallback)
+	if callback.fn == nil {
+		return
+	}
+	pressure := b.pressure()
+	if pressure < callback.threshold {
+		atomic.StoreInt32(&b.pressured, 0)
+		return
+	}
+	if atomic.CompareAndSwapInt32(&b.pressured, 0, 1) {
+		go callback.fn(pressure)
+	}
+}
+
+// claim claims n bytes of the budget, even if that exceeds the limit, for
+// features that can't evict anything.
+func (b *memoryBudget) claim(n int) {
+	atomic.AddInt64(&b.used, int64(n))
+	b.notify()
+}
+
+// reserve claims n bytes of the budget, reporting false if that would exceed
+// the limit.
+func (b *memoryBudget) reserve(n int) bool {
+	for {
+		used := atomic.LoadInt64(&b.used)
+		limit := atomic.LoadInt64(&b.limit)
+		if limit > 0 && used+int64(n) > limit {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&b.used, used, used+int64(n)) {
+			b.notify()
+			return true
+		}
+	}
+}
+
+// release returns n bytes, previously reserved, to the budget.
+func (b *memoryBudget) release(n int) {
+	atomic.AddInt64(&b.used, -int64(n))
+	b.notify()
+}
+
+// entrySize estimates the memory held by an entry.
+func entrySize(entry Entry) int {
+	size := entryOverhead + len(entry.Logger) + len(entry.Caller) + len(entry.Code) +
+		len(entry.Prefix) + len(entry.Message) + len(entry.Stack)
+	for _, tags := range []map[string]interface{}{entry.Tags, entry.Fields} {
+		for name, value := range tags {
+			size += tagOverhead + len(name)
+			if text, ok := value.(string); ok {
+				size += len(text)
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,226 @@
+package loggy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setMemoryLimit starts a fresh budget with the limit, so that the memory still
+// claimed by loggers from other tests isn't counted.
+func setMemoryLimit(t *testing.T, limit int64) {
+	previous := memory
+	memory = &memoryBudget{}
+	SetMemoryLimit(limit)
+	t.Cleanup(func() {
+		memory = previous
+	})
+}
+
+func TestMemoryBudget(t *testing.T) {
+	b := &memoryBudget{limit: 10}
+
+	assert.True(t, b.reserve(6))
+	assert.False(t, b.reserve(6))
+	assert.True(t, b.reserve(4))
+	b.release(6)
+	assert.True(t, b.reserve(6))
+	assert.Equal(t, int64(10), b.used)
+
+	b.limit = 0
+	assert.True(t, b.reserve(1000))
+}
+
+func TestMergeWriter_MemoryLimit(t *testing.T) {
+	setMemoryLimit(t, 50)
+	out := &lockedBuffer{}
+	w := NewMergeWriter(out, time.Minute)
+
+	_, _ = w.Write([]byte("2006-01-02T15:04:05.2Z b\n"))
+	_, _ = w.Write([]byte("2006-01-02T15:04:05.1Z a\n"))
+	assert.Equal(t, int64(50), MemoryUsage())
+	assert.Equal(t, "", out.String())
+
+	// The oldest entry is written early to make room.
+	_, _ = w.Write([]byte("2006-01-02T15:04:05.3Z c\n"))
+	assert.Equal(t, "2006-01-02T15:04:05.1Z a\n", out.String())
+
+	// Entries larger than the limit skip the queue, once it's empty.
+	big := "2006-01-02T15:04:05.4Z " + string(make([]byte, 50)) + "\n"
+	_, _ = w.Write([]byte(big))
+	assert.Equal(t,
+		"2006-01-02T15:04:05.1Z a\n2006-01-02T15:04:05.2Z b\n2006-01-02T15:04:05.3Z c\n"+big,
+		out.String(),
+	)
+	assert.Equal(t, int64(0), MemoryUsage())
+
+	assert.Nil(t, w.Close())
+}
+
+func TestPressure(t *testing.T) {
+	assert.Equal(t, float64(0), Pressure())
+
+	setMemoryLimit(t, 100)
+	pressures := make(chan float64, 10)
+	OnPressure(0.5, func(pressure float64) {
+		pressures <- pressure
+	})
+	defer OnPressure(0, nil)
+
+	assert.True(t, memory.reserve(40))
+	assert.Equal(t, 0.4, Pressure())
+	assert.True(t, memory.reserve(20))
+	assert.Equal(t, 0.6, <-pressures)
+
+	// The callback is only called again once the pressure has dropped.
+	assert.True(t, memory.reserve(20))
+	memory.release(50)
+	assert.True(t, memory.reserve(30))
+	assert.Equal(t, 0.6, <-pressures)
+
+	memory.release(60)
+	assert.Equal(t, float64(0), Pressure())
+	assert.Len(t, pressures, 0)
+}
+
+func TestAsync_Pressure(t *testing.T) {
+	setMemoryLimit(t, 4*entryOverhead)
+	pressures := make(chan float64, 10)
+	OnPressure(0.5, func(pressure float64) {
+		pressures <- pressure
+	})
+	defer OnPressure(0, nil)
+
+	out := &gatedWriter{gate: make(chan struct{}), out: &lockedBuffer{}}
+	options := Options{
+		Out:                 out,
+		Threshold:           LevelInfo,
+		Async:               &Async{},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	// The first entry is taken off the queue, and held by the gated writer.
+	assert.Nil(t, l.Info(ctx, "1"))
+	assert.Eventually(t, func() bool { return len(l.async.entries) == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), MemoryUsage())
+
+	// Queued entries raise the pressure, even beyond the limit, as the queue
+	// blocks rather than drops.
+	for i := 2; i <= 5; i++ {
+		assert.Nil(t, l.Info(ctx, i))
+	}
+	assert.Equal(t, int64(4*(entryOverhead+1)), MemoryUsage())
+	assert.Greater(t, Pressure(), 1.0)
+	assert.GreaterOrEqual(t, <-pressures, 0.5)
+
+	close(out.gate)
+	assert.Nil(t, l.Flush())
+	assert.Equal(t, int64(0), MemoryUsage())
+	assert.Equal(t, "INFO 1\nINFO 2\nINFO 3\nINFO 4\nINFO 5\n", out.out.String())
+	assert.Nil(t, l.Close())
+}
+
+func TestAsync_MemoryLimit(t *testing.T) {
+	setMemoryLimit(t, 2*(entryOverhead+1))
+
+	for _, overflow := range []Overflow{OverflowDropNewest, OverflowDropOldest} {
+		out := &gatedWriter{gate: make(chan struct{}), out: &lockedBuffer{}}
+		options := Options{
+			Out:                 out,
+			Threshold:           LevelInfo,
+			Async:               &Async{Overflow: overflow},
+			DisableFunctionName: true,
+			DisableTimestamps:   true,
+		}
+		l, ctx := New(context.Background(), options)
+
+		assert.Nil(t, l.Info(ctx, "1"))
+		assert.Eventually(t, func() bool { return len(l.async.entries) == 0 }, time.Second, time.Millisecond)
+		// The queue has room, but the memory limit only allows two entries.
+		for i := 2; i <= 4; i++ {
+			assert.Nil(t, l.Info(ctx, i))
+		}
+		assert.Equal(t, int64(1), l.AsyncDropped())
+		assert.Equal(t, int64(2*(entryOverhead+1)), MemoryUsage())
+
+		close(out.gate)
+		assert.Nil(t, l.Close())
+		assert.Equal(t, int64(0), MemoryUsage())
+		if overflow == OverflowDropNewest {
+			assert.Equal(t, "INFO 1\nINFO 2\nINFO 3\n", out.out.String())
+		} else {
+			assert.Equal(t, "INFO 1\nINFO 3\nINFO 4\n", out.out.String())
+		}
+	}
+}
+
+func TestRecentErrors_Memory(t *testing.T) {
+	setMemoryLimit(t, 0)
+	options := Options{
+		Out:          &lockedBuffer{},
+		Err:          &lockedBuffer{},
+		Threshold:    LevelInfo,
+		RecentErrors: 2,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Error(ctx, "one"))
+	one := MemoryUsage()
+	assert.Greater(t, one, int64(entryOverhead))
+	assert.Nil(t, l.Error(ctx, "two"))
+	assert.Nil(t, l.Error(ctx, "three"))
+	// Only the entries kept are counted.
+	assert.Equal(t, 2*one+2, MemoryUsage())
+
+	assert.Nil(t, l.Close())
+	assert.Equal(t, int64(0), MemoryUsage())
+}
+
+func TestNetWriter_MemoryLimit(t *testing.T) {
+	setMemoryLimit(t, 10)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	assert.Nil(t, listener.Close())
+
+	w := NewNetWriter("tcp", address, NetOptions{ReconnectDelay: time.Hour})
+	_, err = w.Write([]byte("123456\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), MemoryUsage())
+	_, err = w.Write([]byte("7890\n"))
+	assert.Equal(t, ErrBacklogFull, err)
+
+	// Whatever's left in the backlog is released when the writer is closed.
+	assert.NotNil(t, w.Close())
+	assert.Equal(t, int64(0), MemoryUsage())
+}
+
+// budgetWriter records the memory used while it's written to.
+type budgetWriter struct {
+	usage []int64
+}
+
+func (w *budgetWriter) Write(p []byte) (int, error) {
+	w.usage = append(w.usage, MemoryUsage())
+	return len(p), nil
+}
+
+func TestSpoolWriter_Memory(t *testing.T) {
+	setMemoryLimit(t, 0)
+	path := filepath.Join(t.TempDir(), "spool.log")
+	down := NewSpoolWriter(&flakyWriter{down: true}, path, 0)
+	_, _ = down.Write([]byte("one\n"))
+	_, _ = down.Write([]byte("three\n"))
+
+	// Each record counts while it's replayed.
+	out := &budgetWriter{}
+	w := NewSpoolWriter(out, path, 0)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []int64{4, 6}, out.usage)
+	assert.Equal(t, int64(0), MemoryUsage())
+}
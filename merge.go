@@ -0,0 +1,235 @@
+package loggy
+
+import (
+	"bytes"
+	"container/heap"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var _ io.WriteCloser = &MergeWriter{}
+
+// MergeWriter combines the output of several loggers, e.g. one per subsystem,
+// into a single destination in timestamp order. Each write is treated as one
+// entry, and held for up to window so that entries written slightly out of
+// order can be sorted. An entry is released once an entry at least window
+// newer has been written, or once it has been held for window. It is safe for
+// concurrent use.
+//
+// Queued entries count towards the limit set by SetMemoryLimit. When the limit
+// is reached, the oldest entries are written early to make room.
+//
+// Timestamps are read from the start of each entry, in the RFC3339 formats or
+// as epoch seconds or milliseconds. Entries without a timestamp are ordered as
+// though they were logged at the same time as the newest entry seen so far.
+type MergeWriter struct {
+	out     io.Writer
+	window  time.Duration
+	mux     sync.Mutex
+	pending mergeQueue
+	// The newest timestamp written so far.
+	latest time.Time
+	// Increments with each write, to keep entries with equal timestamps in the
+	// order they were written.
+	seq    uint64
+	closed bool
+	done   chan struct{}
+}
+
+// NewMergeWriter creates a MergeWriter for out that reorders entries within the
+// window. A window <= 0 writes entries immediately, in the order received.
+func NewMergeWriter(out io.Writer, window time.Duration) *MergeWriter {
+	w := &MergeWriter{
+		out:    out,
+		window: window,
+		done:   make(chan struct{}),
+	}
+	if window > 0 {
+		go w.releaseEvery(window)
+	}
+	return w
+}
+
+// Write queues p as a single entry, and writes any entries that are ready.
+func (w *MergeWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.window <= 0 {
+		return w.out.Write(p)
+	}
+
+	t, ok := parseEntryTime(p)
+	if !ok {
+		t = w.latest
+	}
+	if t.After(w.latest) {
+		w.latest = t
+	}
+	for !memory.reserve(len(p)) {
+		if w.pending.Len() == 0 {
+			// Nothing left to evict, so skip the queue.
+			return w.out.Write(p)
+		}
+		// Make room by writing out the oldest entries early.
+		if err := w.writeNext(); err != nil {
+			return 0, err
+		}
+	}
+	w.seq++
+	heap.Push(&w.pending, &mergeEntry{
+		time:    t,
+		seq:     w.seq,
+		arrived: time.Now(),
+		data:    append([]byte{}, p...),
+	})
+
+	if err := w.release(w.latest.Add(-w.window)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes every queued entry, in timestamp order.
+func (w *MergeWriter) Flush() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.flush()
+}
+
+// Close writes every queued entry and stops the periodic release of entries. The
+// underlying writer is not closed. Calling Close more than once has no effect.
+func (w *MergeWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+
+	return w.flush()
+}
+
+func (w *MergeWriter) flush() error {
+	for w.pending.Len() > 0 {
+		if err := w.writeNext(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release writes the queued entries timestamped at or before cutoff.
+func (w *MergeWriter) release(cutoff time.Time) error {
+	for w.pending.Len() > 0 && !w.pending[0].time.After(cutoff) {
+		if err := w.writeNext(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStale writes the entries that have been held for the whole window,
+// along with any entries timestamped before them.
+func (w *MergeWriter) releaseStale() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	var cutoff time.Time
+	stale := time.Now().Add(-w.window)
+	for _, entry := range w.pending {
+		if !entry.arrived.After(stale) && entry.time.After(cutoff) {
+			cutoff = entry.time
+		}
+	}
+	if cutoff.IsZero() {
+		return nil
+	}
+	return w.release(cutoff)
+}
+
+// writeNext writes the oldest queued entry. On failure, the entry is discarded,
+// so that one bad entry doesn't block the rest.
+func (w *MergeWriter) writeNext() error {
+	entry := heap.Pop(&w.pending).(*mergeEntry)
+	memory.release(len(entry.data))
+	_, err := w.out.Write(entry.data)
+	return err
+}
+
+func (w *MergeWriter) releaseEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.releaseStale()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// parseEntryTime reads the timestamp at the start of an entry.
+func parseEntryTime(p []byte) (time.Time, bool) {
+	end := bytes.IndexAny(p, " \n")
+	if end <= 0 {
+		return time.Time{}, false
+	}
+	field := string(p[:end])
+	if t, err := time.Parse(time.RFC3339Nano, field); err == nil {
+		return t, true
+	}
+
+	// Epoch seconds and milliseconds, as output by EpochSecondsEncoder and
+	// EpochMillisEncoder.
+	n, err := strconv.ParseInt(field, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(field) >= 13 {
+		return time.Unix(0, n*int64(time.Millisecond)), true
+	}
+	return time.Unix(n, 0), true
+}
+
+type mergeEntry struct {
+	time    time.Time
+	seq     uint64
+	arrived time.Time
+	data    []byte
+}
+
+// mergeQueue is a heap of entries, ordered by timestamp and then by the order
+// they were written.
+type mergeQueue []*mergeEntry
+
+func (q mergeQueue) Len() int { return len(q) }
+func (q mergeQueue) Less(i, j int) bool {
+	if q[i].time.Equal(q[j].time) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].time.Before(q[j].time)
+}
+func (q mergeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *mergeQueue) Push(x interface{}) {
+	*q = append(*q, x.(*mergeEntry))
+}
+
+func (q *mergeQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return entry
+}
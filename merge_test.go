@@ -0,0 +1,116 @@
+package loggy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var parseEntryTimeTestCases = []struct {
+	Name     string
+	Entry    string
+	Expected time.Time
+	OK       bool
+}{
+	{
+		Name:     "rfc3339",
+		Entry:    "2006-01-02T15:04:05Z INFO main.run started\n",
+		Expected: time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		OK:       true,
+	},
+	{
+		Name:     "rfc3339-nano",
+		Entry:    "2006-01-02T15:04:05.123456789Z INFO main.run started\n",
+		Expected: loggyTestTime,
+		OK:       true,
+	},
+	{
+		Name:     "epoch-seconds",
+		Entry:    "1136214245 INFO main.run started\n",
+		Expected: time.Unix(1136214245, 0),
+		OK:       true,
+	},
+	{
+		Name:     "epoch-millis",
+		Entry:    "1136214245123 INFO main.run started\n",
+		Expected: time.Unix(1136214245, int64(123*time.Millisecond)),
+		OK:       true,
+	},
+	{
+		Name:  "no-timestamp",
+		Entry: "INFO main.run started\n",
+		OK:    false,
+	},
+}
+
+func TestParseEntryTime(t *testing.T) {
+	for _, testCase := range parseEntryTimeTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			actual, ok := parseEntryTime([]byte(testCase.Entry))
+			assert.Equal(t, testCase.OK, ok)
+			assert.True(t, testCase.Expected.Equal(actual), "expected %s, got %s", testCase.Expected, actual)
+		})
+	}
+}
+
+func TestMergeWriter(t *testing.T) {
+	out := &lockedBuffer{}
+	w := NewMergeWriter(out, time.Second)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("2006-01-02T15:04:05.2Z b\n"))
+	_, _ = w.Write([]byte("2006-01-02T15:04:05.1Z a\n"))
+	_, _ = w.Write([]byte("c\n"))
+	assert.Equal(t, "", out.String())
+
+	// An entry more than a window newer releases everything before it.
+	_, _ = w.Write([]byte("2006-01-02T15:04:07Z d\n"))
+	assert.Equal(t, "2006-01-02T15:04:05.1Z a\n2006-01-02T15:04:05.2Z b\nc\n", out.String())
+
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, "2006-01-02T15:04:05.1Z a\n2006-01-02T15:04:05.2Z b\nc\n2006-01-02T15:04:07Z d\n", out.String())
+}
+
+func TestMergeWriter_Loggers(t *testing.T) {
+	out := &lockedBuffer{}
+	w := NewMergeWriter(out, time.Minute)
+	newLogger := func(name string, offset time.Duration) Logger {
+		l, _ := New(context.Background(), Options{
+			Name:                name,
+			Threshold:           LevelInfo,
+			Out:                 w,
+			DisableFunctionName: true,
+			TimestampEncoder:    RFC3339NanoEncoder,
+			TimestampFunc: func() time.Time {
+				return loggyTestTime.Add(offset)
+			},
+		})
+		return l
+	}
+	db := newLogger("db", time.Millisecond)
+	api := newLogger("api", 0)
+
+	assert.Nil(t, db.Info(context.Background(), "query"))
+	assert.Nil(t, api.Info(context.Background(), "request"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t,
+		"2006-01-02T15:04:05.123456789Z INFO api request\n"+
+			"2006-01-02T15:04:05.124456789Z INFO db query\n",
+		out.String(),
+	)
+	_, err := w.Write([]byte("late\n"))
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestMergeWriter_Stale(t *testing.T) {
+	out := &lockedBuffer{}
+	w := NewMergeWriter(out, 10*time.Millisecond)
+	defer w.Close()
+
+	_, _ = w.Write([]byte("2006-01-02T15:04:05Z a\n"))
+	assert.Eventually(t, func() bool {
+		return out.String() == "2006-01-02T15:04:05Z a\n"
+	}, time.Second, 5*time.Millisecond)
+}
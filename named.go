@@ -0,0 +1,79 @@
+package loggy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Named creates a child logger for a subsystem, e.g. l.Named("db").Named("pool"),
+// whose dotted name is output after the severity, following Options.Name if one
+// is set, e.g. "app.db.pool". The child shares the parent's options, so changes
+// such as SetOutput apply to both. Its threshold can be overridden by name via
+// Options.Thresholds.
+func (l *logger) Named(name string) Logger {
+	return &logger{
+		parent: l,
+		name:   name,
+	}
+}
+
+// ParseThresholds parses a comma-separated list of per-name threshold overrides
+// for Options.Thresholds, e.g. "db.*=DEBUG, *=INFO". Levels are parsed with
+// ParseLevel, so "off" disables logging for the name. An empty spec returns nil.
+func ParseThresholds(spec string) (map[string]Level, error) {
+	var thresholds map[string]Level
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid threshold %q: expected name=LEVEL", rule)
+		}
+		name, levelName := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if name == "" {
+			return nil, fmt.Errorf("invalid threshold %q: missing name", rule)
+		}
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %w", rule, err)
+		}
+		if thresholds == nil {
+			thresholds = make(map[string]Level)
+		}
+		thresholds[name] = level
+	}
+	return thresholds, nil
+}
+
+// threshold returns the threshold for this logger's messages. The most specific
+// entry of Options.Thresholds that matches the logger's name, excluding
+// Options.Name, takes precedence: the exact name, then "name.*" for the name and
+// each of its ancestors, then "*". Otherwise Options.Threshold applies.
+func (l *logger) threshold(options *Options) Level {
+	if len(options.Thresholds) == 0 {
+		return options.Threshold
+	}
+
+	var names []string
+	for d := l; d != nil; d = d.parent {
+		if d.name != "" {
+			names = append([]string{d.name}, names...)
+		}
+	}
+	if len(names) > 0 {
+		if level, ok := options.Thresholds[strings.Join(names, ".")]; ok {
+			return level
+		}
+	}
+	for i := len(names); i > 0; i-- {
+		if level, ok := options.Thresholds[strings.Join(names[:i], ".")+".*"]; ok {
+			return level
+		}
+	}
+	if level, ok := options.Thresholds["*"]; ok {
+		return level
+	}
+	return options.Threshold
+}
@@ -0,0 +1,160 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var namedThresholdTestCases = []struct {
+	Name       string
+	Thresholds map[string]Level
+	Logger     []string
+	Expected   Level
+}{
+	{
+		Name:     "no-overrides",
+		Logger:   []string{"db"},
+		Expected: LevelInfo,
+	},
+	{
+		Name:       "wildcard",
+		Thresholds: map[string]Level{"*": LevelWarning},
+		Logger:     []string{"db"},
+		Expected:   LevelWarning,
+	},
+	{
+		Name:       "wildcard-root",
+		Thresholds: map[string]Level{"*": LevelWarning, "db.*": LevelDebug},
+		Expected:   LevelWarning,
+	},
+	{
+		Name:       "subtree-includes-name",
+		Thresholds: map[string]Level{"*": LevelWarning, "db.*": LevelDebug},
+		Logger:     []string{"db"},
+		Expected:   LevelDebug,
+	},
+	{
+		Name:       "subtree-includes-descendants",
+		Thresholds: map[string]Level{"*": LevelWarning, "db.*": LevelDebug},
+		Logger:     []string{"db", "pool"},
+		Expected:   LevelDebug,
+	},
+	{
+		Name:       "deepest-subtree-wins",
+		Thresholds: map[string]Level{"db.*": LevelDebug, "db.pool.*": LevelError},
+		Logger:     []string{"db", "pool", "conn"},
+		Expected:   LevelError,
+	},
+	{
+		Name:       "exact-name-wins",
+		Thresholds: map[string]Level{"db.*": LevelDebug, "db.pool": LevelTrace},
+		Logger:     []string{"db", "pool"},
+		Expected:   LevelTrace,
+	},
+	{
+		Name:       "exact-name-excludes-descendants",
+		Thresholds: map[string]Level{"db": LevelDebug},
+		Logger:     []string{"db", "pool"},
+		Expected:   LevelInfo,
+	},
+	{
+		Name:       "no-match",
+		Thresholds: map[string]Level{"db.*": LevelDebug},
+		Logger:     []string{"http"},
+		Expected:   LevelInfo,
+	},
+}
+
+func TestLogger_Named_Threshold(t *testing.T) {
+	for _, tc := range namedThresholdTestCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			options := Options{
+				Name:       "app",
+				Threshold:  LevelInfo,
+				Thresholds: tc.Thresholds,
+			}
+			root, _ := New(context.Background(), options)
+			var l Logger = root
+			for _, name := range tc.Logger {
+				l = l.Named(name)
+			}
+			assert.Equal(t, tc.Expected, l.(*logger).threshold(root.currentOptions()))
+		})
+	}
+}
+
+func TestLogger_Named(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	options := Options{
+		Name:                "app",
+		Out:                 buf,
+		Err:                 buf,
+		Threshold:           LevelInfo,
+		Thresholds:          map[string]Level{"db.*": LevelDebug, "http": -1},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	pool := l.Named("db").Named("pool")
+	http := l.Named("http")
+
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+	assert.Nil(t, pool.Debug(ctx, "acquired"))
+	assert.Nil(t, http.Critical(ctx, "hidden"))
+	assert.Nil(t, http.Std(ctx, "hidden"))
+	assert.Nil(t, l.Info(ctx, "shown"))
+
+	assert.Equal(t, "DEBUG app.db.pool acquired\nINFO app shown\n", buf.String())
+}
+
+var parseThresholdsTestCases = []struct {
+	Name     string
+	Spec     string
+	Expected map[string]Level
+	Error    string
+}{
+	{
+		Name: "empty",
+	},
+	{
+		Name:     "rules",
+		Spec:     "db.*=DEBUG, *=info,http=off",
+		Expected: map[string]Level{"db.*": LevelDebug, "*": LevelInfo, "http": -1},
+	},
+	{
+		Name:     "trailing-comma",
+		Spec:     "db=WARN,",
+		Expected: map[string]Level{"db": LevelWarning},
+	},
+	{
+		Name:  "missing-level",
+		Spec:  "db.*",
+		Error: `invalid threshold "db.*": expected name=LEVEL`,
+	},
+	{
+		Name:  "missing-name",
+		Spec:  "=DEBUG",
+		Error: `invalid threshold "=DEBUG": missing name`,
+	},
+	{
+		Name:  "unknown-level",
+		Spec:  "db=LOUD",
+		Error: `invalid threshold "db=LOUD": unknown level "LOUD"`,
+	},
+}
+
+func TestParseThresholds(t *testing.T) {
+	for _, tc := range parseThresholdsTestCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			thresholds, err := ParseThresholds(tc.Spec)
+			if tc.Error != "" {
+				assert.EqualError(t, err, tc.Error)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.Expected, thresholds)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package loggy
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// NATSPublisher publishes messages to NATS. It's satisfied by *nats.Conn, so
+// NATSWriter doesn't depend on any particular NATS package. See NATSPublishFunc
+// for JetStream.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublishFunc adapts a function to NATSPublisher, e.g. to publish to a
+// JetStream stream, waiting for each message to be acknowledged:
+//
+//	loggy.NATSPublishFunc(func(subject string, data []byte) error {
+//		_, err := js.Publish(subject, data)
+//		return err
+//	})
+type NATSPublishFunc func(subject string, data []byte) error
+
+// Publish implements NATSPublisher.
+func (f NATSPublishFunc) Publish(subject string, data []byte) error {
+	return f(subject, data)
+}
+
+var _ io.Writer = &NATSWriter{}
+
+// NATSWriter publishes each write, such as an encoded log line, as a message on
+// a NATS subject, without its trailing newline. Writes to the NATSWriter itself
+// are published on its subject; LevelOutputs returns writers that publish on a
+// subject for each level, so that subscribers can pick levels with wildcards.
+// It's as safe for concurrent use as its publisher.
+type NATSWriter struct {
+	publisher NATSPublisher
+	subject   string
+}
+
+// NewNATSWriter creates a NATSWriter that publishes on the subject. For example:
+//
+//	w := loggy.NewNATSWriter(nc, "logs.billing")
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, LevelOutputs: w.LevelOutputs(), Encoder: loggy.JSONEncoder{}})
+func NewNATSWriter(publisher NATSPublisher, subject string) *NATSWriter {
+	return &NATSWriter{
+		publisher: publisher,
+		subject:   subject,
+	}
+}
+
+// Write publishes p on the writer's subject.
+func (w *NATSWriter) Write(p []byte) (int, error) {
+	return w.publish(w.subject, p)
+}
+
+// LevelOutputs returns a writer for each level, for Options.LevelOutputs, which
+// publishes on the writer's subject followed by the lowercase name of the level,
+// e.g. "logs.billing.error", so that "logs.billing.error" and "logs.billing.crit"
+// can be subscribed to for alerts, and "logs.billing.>" for everything.
+func (w *NATSWriter) LevelOutputs() map[Level]io.Writer {
+	outputs := make(map[Level]io.Writer, len(LevelNames))
+	for level, name := range LevelNames {
+		outputs[level] = natsSubject{writer: w, subject: w.subject + "." + strings.ToLower(name)}
+	}
+	return outputs
+}
+
+// Flush flushes the publisher's buffered messages to the server, if it
+// implements Flusher, as *nats.Conn does.
+func (w *NATSWriter) Flush() error {
+	if flusher, ok := w.publisher.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// publish publishes a copy of p, without its trailing newline, as publishers may
+// keep the data after returning.
+func (w *NATSWriter) publish(subject string, p []byte) (int, error) {
+	data := append([]byte(nil), bytes.TrimSuffix(p, []byte{'\n'})...)
+	if err := w.publisher.Publish(subject, data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// natsSubject publishes on a single subject.
+type natsSubject struct {
+	writer  *NATSWriter
+	subject string
+}
+
+func (s natsSubject) Write(p []byte) (int, error) {
+	return s.writer.publish(s.subject, p)
+}
@@ -0,0 +1,67 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+)
+
+// natsRecorder records published messages, like a NATS connection.
+type natsRecorder struct {
+	mux      sync.Mutex
+	messages []string
+	flushed  int
+}
+
+func (r *natsRecorder) Publish(subject string, data []byte) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.messages = append(r.messages, subject+" "+string(data))
+	return nil
+}
+
+func (r *natsRecorder) Flush() error {
+	r.flushed++
+	return nil
+}
+
+func TestNATSWriter(t *testing.T) {
+	recorder := &natsRecorder{}
+	w := NewNATSWriter(recorder, "logs.app")
+	l, ctx := New(context.Background(), Options{
+		Out:                 w,
+		Err:                 w,
+		LevelOutputs:        map[Level]io.Writer{LevelError: w.LevelOutputs()[LevelError]},
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	})
+
+	assert.Nil(t, l.Info(ctx, "started"))
+	assert.Nil(t, l.Error(ctx, "oops"))
+	assert.Nil(t, l.Flush())
+	assert.Equal(t, []string{"logs.app INFO started", "logs.app.error ERROR oops"}, recorder.messages)
+	assert.Equal(t, 1, recorder.flushed)
+
+	outputs := w.LevelOutputs()
+	assert.Len(t, outputs, len(LevelNames))
+	_, err := outputs[LevelCritical].Write([]byte("down\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "logs.app.crit down", recorder.messages[2])
+}
+
+func TestNATSPublishFunc(t *testing.T) {
+	failed := errors.New("no responders")
+	w := NewNATSWriter(NATSPublishFunc(func(subject string, data []byte) error {
+		return failed
+	}), "logs.app")
+
+	n, err := w.Write([]byte("lost\n"))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, failed, err)
+	assert.Nil(t, w.Flush())
+}
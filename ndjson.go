@@ -0,0 +1,59 @@
+package loggy
+
+import (
+	"errors"
+)
+
+// ErrLineTooLarge is returned when a single encoded entry doesn't fit within a
+// batch on its own.
+var ErrLineTooLarge = errors.New("encoded line exceeds the maximum batch size")
+
+var _ Encoder = NDJSONEncoder{}
+
+// NDJSONEncoder encodes entries as newline-delimited JSON (one JSON document per
+// line), as JSONEncoder does, and can also group them into batches for shipping
+// to a network destination that limits the size of each request.
+type NDJSONEncoder struct {
+	JSONEncoder
+	// The maximum size of each batch, in bytes. A value < 1 disables the limit,
+	// so every entry is encoded into a single batch.
+	MaxBatchSize int
+}
+
+// Encode implements Encoder, encoding the entry as a single JSON line. An entry
+// larger than MaxBatchSize returns ErrLineTooLarge, since it could never be
+// shipped.
+func (e NDJSONEncoder) Encode(entry Entry) ([]byte, error) {
+	line, err := e.JSONEncoder.Encode(entry)
+	if err != nil {
+		return nil, err
+	}
+	if e.MaxBatchSize > 0 && len(line) > e.MaxBatchSize {
+		return nil, ErrLineTooLarge
+	}
+	return line, nil
+}
+
+// EncodeBatches encodes each entry as a JSON line and groups the lines into
+// batches, starting a new batch whenever the next line would exceed
+// MaxBatchSize. Entries are never split across batches.
+func (e NDJSONEncoder) EncodeBatches(entries ...Entry) ([][]byte, error) {
+	var batches [][]byte
+	var batch []byte
+	for _, entry := range entries {
+		line, err := e.Encode(entry)
+		if err != nil {
+			return nil, err
+		}
+		if e.MaxBatchSize > 0 && len(batch)+len(line) > e.MaxBatchSize {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		batch = append(batch, line...)
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches, nil
+}
@@ -0,0 +1,77 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var ndjsonTestCases = []struct {
+	Name            string
+	MaxBatchSize    int
+	Entries         []Entry
+	ExpectedBatches []string
+	ExpectedErr     error
+}{
+	{
+		Name:            "empty",
+		Entries:         nil,
+		ExpectedBatches: nil,
+	},
+	{
+		Name:    "unlimited",
+		Entries: []Entry{{Level: LevelInfo, Message: "a"}, {Level: LevelInfo, Message: "<b>"}},
+		ExpectedBatches: []string{
+			`{"schema":1,"level":"INFO","message":"a"}` + "\n" + `{"schema":1,"level":"INFO","message":"<b>"}` + "\n",
+		},
+	},
+	{
+		Name:         "split",
+		MaxBatchSize: 90,
+		Entries:      []Entry{{Level: LevelInfo, Message: "a"}, {Level: LevelInfo, Message: "b"}, {Level: LevelInfo, Message: "c"}},
+		ExpectedBatches: []string{
+			`{"schema":1,"level":"INFO","message":"a"}` + "\n" + `{"schema":1,"level":"INFO","message":"b"}` + "\n",
+			`{"schema":1,"level":"INFO","message":"c"}` + "\n",
+		},
+	},
+	{
+		Name:         "line-too-large",
+		MaxBatchSize: 4,
+		Entries:      []Entry{{Level: LevelInfo, Message: "too large"}},
+		ExpectedErr:  ErrLineTooLarge,
+	},
+}
+
+func TestNDJSONEncoder_EncodeBatches(t *testing.T) {
+	for _, testCase := range ndjsonTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoder := NDJSONEncoder{MaxBatchSize: testCase.MaxBatchSize}
+			batches, err := encoder.EncodeBatches(testCase.Entries...)
+			assert.Equal(t, testCase.ExpectedErr, err)
+
+			var actual []string
+			for _, batch := range batches {
+				actual = append(actual, string(batch))
+			}
+			assert.Equal(t, testCase.ExpectedBatches, actual)
+		})
+	}
+}
+
+func TestOptions_NDJSONEncoder(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Encoder:             NDJSONEncoder{MaxBatchSize: 64},
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Info(ctx, "shipped"))
+	assert.Equal(t, ErrLineTooLarge, l.Info(ctx, "a message that's much too large to fit within a single batch"))
+	assert.Equal(t, `{"schema":1,"level":"INFO","message":"shipped"}`+"\n", stdout.String())
+}
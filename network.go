@@ -0,0 +1,288 @@
+package loggy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrBacklogFull is returned when data can't be delivered or held in the
+// backlog, because the backlog has reached its maximum size.
+var ErrBacklogFull = errors.New("backlog is full")
+
+// errNotConnected is returned by connect while waiting to reconnect.
+var errNotConnected = errors.New("not connected")
+
+var _ io.WriteCloser = &NetWriter{}
+
+// NetOptions configures a NetWriter.
+type NetOptions struct {
+	// The time allowed to connect. If zero, 5 seconds is used.
+	DialTimeout time.Duration
+	// The time allowed for each write. A write that times out is treated as a
+	// lost connection. If zero, 5 seconds is used.
+	WriteTimeout time.Duration
+	// The delay before reconnecting after the connection is lost, doubling with
+	// each failed attempt, up to MaxReconnectDelay. If zero, 1 second is used.
+	ReconnectDelay time.Duration
+	// The longest delay between attempts to reconnect. If zero, 1 minute is used.
+	MaxReconnectDelay time.Duration
+	// The maximum size, in bytes, of the data held while the connection is
+	// down. If zero, 1 MiB is used. A MaxBacklog < 0 disables the backlog, so
+	// writes fail while the connection is down. The backlog also counts towards
+	// the limit set by SetMemoryLimit.
+	MaxBacklog int64
+}
+
+// NetWriter ships each write, such as an encoded log line, to a TCP or UDP
+// endpoint, e.g. a Logstash or Fluentd listener. If the connection is lost, the
+// writer keeps writes in an in-memory backlog, and reconnects on a later write,
+// backing off between attempts, then delivers the backlog, in order, before any
+// new data. Connections are dialed in the background, so that a slow endpoint
+// doesn't hold up logging; writes are held in the backlog in the meantime.
+// Writes that are held in the backlog count as written. A write that's only
+// partly sent when the connection is lost is sent again, in full, once it's
+// back, so that the endpoint never receives a fragment of one as a record of
+// its own; a truncated copy may still arrive on the lost connection. TCP can't
+// report the loss of the connection until the write after it, so a write made
+// just as the endpoint goes away may be lost. It is safe for concurrent use.
+type NetWriter struct {
+	network string
+	address string
+	options NetOptions
+	mux     sync.Mutex
+	conn    net.Conn
+	// Writes waiting for the connection, oldest first, and their total size.
+	backlog     [][]byte
+	backlogSize int64
+	// The delay before the next attempt to reconnect, and when it's due.
+	delay       time.Duration
+	nextAttempt time.Time
+	// Whether a connection is being dialed in the background, signaled by
+	// dialed once it's done, and the error from the last attempt, if it failed.
+	dialing bool
+	dialed  *sync.Cond
+	dialErr error
+	closed  bool
+	now     func() time.Time
+	netDial func(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// NewNetWriter creates a NetWriter for the address, where network is "tcp" or
+// "udp", as for net.Dial. It starts connecting in the background, and writes are
+// held in the backlog until the endpoint can be reached. For example:
+//
+//	w := loggy.NewNetWriter("tcp", "logstash:5000", loggy.NetOptions{})
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, Encoder: loggy.JSONEncoder{}})
+func NewNetWriter(network, address string, options NetOptions) *NetWriter {
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 5 * time.Second
+	}
+	if options.WriteTimeout == 0 {
+		options.WriteTimeout = 5 * time.Second
+	}
+	if options.ReconnectDelay == 0 {
+		options.ReconnectDelay = time.Second
+	}
+	if options.MaxReconnectDelay == 0 {
+		options.MaxReconnectDelay = time.Minute
+	}
+	if options.MaxBacklog == 0 {
+		options.MaxBacklog = 1 << 20
+	}
+	w := &NetWriter{
+		network: network,
+		address: address,
+		options: options,
+		delay:   options.ReconnectDelay,
+		now:     time.Now,
+		netDial: net.DialTimeout,
+	}
+	w.dialed = sync.NewCond(&w.mux)
+
+	w.mux.Lock()
+	w.connect()
+	w.mux.Unlock()
+	return w
+}
+
+// Write sends p to the endpoint, after any backlog, or adds it to the backlog
+// if the connection is down.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if err := w.deliver(); err != nil {
+		return w.hold(p)
+	}
+	if _, err := w.send(p); err != nil {
+		return w.hold(p)
+	}
+	return len(p), nil
+}
+
+// Flush attempts to deliver the backlog, reconnecting if needed, regardless of
+// the delay before the next attempt, and waiting for the connection.
+func (w *NetWriter) Flush() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed || len(w.backlog) == 0 {
+		return nil
+	}
+	return w.reconnect()
+}
+
+// Backlog returns the number of bytes waiting to be delivered.
+func (w *NetWriter) Backlog() int64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.backlogSize
+}
+
+// Close attempts to deliver the backlog, then closes the connection, returning
+// an error if any of the backlog was lost. Calling Close more than once has no
+// effect.
+func (w *NetWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	var err error
+	if len(w.backlog) > 0 {
+		err = w.reconnect()
+		if w.closed {
+			// Closed while waiting for the connection.
+			return nil
+		}
+	}
+	w.closed = true
+	if w.conn != nil {
+		if closeErr := w.conn.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	// Anything left in the backlog is lost.
+	memory.release(int(w.backlogSize))
+	w.backlog = nil
+	w.backlogSize = 0
+	return err
+}
+
+// reconnect delivers the backlog, first connecting, regardless of the delay
+// before the next attempt, and waiting for the connection, if it's down.
+func (w *NetWriter) reconnect() error {
+	if w.conn == nil {
+		w.nextAttempt = time.Time{}
+		w.connect()
+		for w.dialing {
+			w.dialed.Wait()
+		}
+		if w.conn == nil && w.dialErr != nil {
+			return w.dialErr
+		}
+	}
+	return w.deliver()
+}
+
+// deliver sends the backlog, if connected, otherwise starting to reconnect if an
+// attempt is due. It returns an error if anything is left in the backlog.
+func (w *NetWriter) deliver() error {
+	if w.conn == nil {
+		w.connect()
+		return errNotConnected
+	}
+	for len(w.backlog) > 0 {
+		// A write that's only partly sent is kept whole, to be sent again.
+		if _, err := w.send(w.backlog[0]); err != nil {
+			return err
+		}
+		w.backlogSize -= int64(len(w.backlog[0]))
+		memory.release(len(w.backlog[0]))
+		w.backlog[0] = nil
+		w.backlog = w.backlog[1:]
+	}
+	return nil
+}
+
+// send writes p to the connection, dropping the connection if the write fails,
+// so that the next write reconnects.
+func (w *NetWriter) send(p []byte) (int, error) {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(w.options.WriteTimeout)); err != nil {
+		w.disconnect()
+		return 0, err
+	}
+	n, err := w.conn.Write(p)
+	if err != nil {
+		w.disconnect()
+	}
+	return n, err
+}
+
+// hold adds p to the backlog.
+func (w *NetWriter) hold(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if w.options.MaxBacklog < 0 || w.backlogSize+int64(len(p)) > w.options.MaxBacklog {
+		return 0, ErrBacklogFull
+	}
+	if !memory.reserve(len(p)) {
+		return 0, ErrBacklogFull
+	}
+	w.backlog = append(w.backlog, append([]byte(nil), p...))
+	w.backlogSize += int64(len(p))
+	return len(p), nil
+}
+
+// connect starts dialing the endpoint in the background, if an attempt is due
+// and one isn't already under way.
+func (w *NetWriter) connect() {
+	if w.dialing || w.now().Before(w.nextAttempt) {
+		return
+	}
+	w.dialing = true
+	go w.dial()
+}
+
+// dial connects to the endpoint, then delivers the backlog, backing off after a
+// failure.
+func (w *NetWriter) dial() {
+	conn, err := w.netDial(w.network, w.address, w.options.DialTimeout)
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	defer w.dialed.Broadcast()
+
+	w.dialing = false
+	w.dialErr = err
+	if err != nil {
+		w.nextAttempt = w.now().Add(w.delay)
+		if w.delay *= 2; w.delay > w.options.MaxReconnectDelay {
+			w.delay = w.options.MaxReconnectDelay
+		}
+		return
+	}
+	if w.closed {
+		_ = conn.Close()
+		return
+	}
+	w.conn = conn
+	w.delay = w.options.ReconnectDelay
+	_ = w.deliver()
+}
+
+// disconnect closes the connection after a failure.
+func (w *NetWriter) disconnect() {
+	_ = w.conn.Close()
+	w.conn = nil
+	w.nextAttempt = w.now().Add(w.delay)
+}
@@ -0,0 +1,250 @@
+package loggy
+
+import (
+	"bufio"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+// partialConn is a connection that accepts limit bytes, then fails, recording
+// what it accepted.
+type partialConn struct {
+	net.Conn
+	limit   int
+	written []byte
+}
+
+func (c *partialConn) Write(p []byte) (int, error) {
+	if len(p) > c.limit {
+		c.written = append(c.written, p[:c.limit]...)
+		n := c.limit
+		c.limit = 0
+		return n, errors.New("connection reset")
+	}
+	c.written = append(c.written, p...)
+	c.limit -= len(p)
+	return len(p), nil
+}
+
+func (c *partialConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *partialConn) Close() error {
+	return nil
+}
+
+// readLine reads a line from the connection, failing the test if none arrives.
+func readLine(t *testing.T, reader *bufio.Reader, conn net.Conn) string {
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := reader.ReadString('\n')
+	assert.Nil(t, err)
+	return line
+}
+
+func TestNetWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+
+	now := time.Now()
+	w := NewNetWriter("tcp", address, NetOptions{ReconnectDelay: time.Second, MaxReconnectDelay: 2 * time.Second})
+	w.now = func() time.Time { return now }
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+
+	n, err := w.Write([]byte("first\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "first\n", readLine(t, bufio.NewReader(conn), conn))
+
+	// Take the endpoint down. The writes are held until it's back.
+	assert.Nil(t, conn.Close())
+	assert.Nil(t, listener.Close())
+	assert.Eventually(t, func() bool {
+		_, err := w.Write([]byte("lost?\n"))
+		return err == nil && w.Backlog() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = w.Write([]byte("second\n"))
+	assert.Nil(t, err)
+	backlog := w.Backlog()
+
+	listener, err = net.Listen("tcp", address)
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	// Not due to reconnect yet.
+	_, err = w.Write([]byte("third\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, backlog+6, w.Backlog())
+
+	now = now.Add(2 * time.Second)
+	_, err = w.Write([]byte("fourth\n"))
+	assert.Nil(t, err)
+	conn, err = listener.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	var lines []string
+	for len(lines) == 0 || lines[len(lines)-1] != "fourth\n" {
+		lines = append(lines, readLine(t, reader, conn))
+	}
+	assert.Equal(t, []string{"second\n", "third\n", "fourth\n"}, lines[len(lines)-3:])
+	assert.Equal(t, int64(0), w.Backlog())
+
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	_, err = w.Write([]byte("late\n"))
+	assert.Equal(t, ErrClosed, err)
+}
+
+func TestNetWriter_Backlog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	assert.Nil(t, listener.Close())
+
+	w := NewNetWriter("tcp", address, NetOptions{ReconnectDelay: time.Hour, MaxBacklog: 10})
+	_, err = w.Write([]byte("123456\n"))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("7890\n"))
+	assert.Equal(t, ErrBacklogFull, err)
+	assert.Equal(t, int64(7), w.Backlog())
+
+	// Flush and Close retry immediately, regardless of the delay.
+	listener, err = net.Listen("tcp", address)
+	assert.Nil(t, err)
+	defer listener.Close()
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, int64(0), w.Backlog())
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+	assert.Equal(t, "123456\n", readLine(t, bufio.NewReader(conn), conn))
+	assert.Nil(t, w.Close())
+
+	w = NewNetWriter("tcp", "127.0.0.1:1", NetOptions{MaxBacklog: -1})
+	_, err = w.Write([]byte("dropped\n"))
+	assert.Equal(t, ErrBacklogFull, err)
+}
+
+func TestNetWriter_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	w := NewNetWriter("udp", server.LocalAddr().String(), NetOptions{})
+	defer w.Close()
+	_, err = w.Write([]byte(`{"message":"hi"}` + "\n"))
+	assert.Nil(t, err)
+
+	buf := make([]byte, 1024)
+	assert.Nil(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := server.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"message":"hi"}`+"\n", string(buf[:n]))
+}
+
+func TestNetWriter_SlowDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	w := NewNetWriter("tcp", listener.Addr().String(), NetOptions{ReconnectDelay: time.Hour})
+	// Wait for the first connection, then drop it, so that the next write
+	// redials, slowly.
+	conn, err := listener.Accept()
+	assert.Nil(t, err)
+	release := make(chan struct{})
+	w.mux.Lock()
+	for w.dialing {
+		w.dialed.Wait()
+	}
+	w.netDial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+		<-release
+		return net.DialTimeout(network, address, timeout)
+	}
+	w.disconnect()
+	w.nextAttempt = time.Time{}
+	w.mux.Unlock()
+	assert.Nil(t, conn.Close())
+
+	// The write is held while the endpoint is dialed.
+	_, err = w.Write([]byte("held\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), w.Backlog())
+
+	close(release)
+	conn, err = listener.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+	assert.Equal(t, "held\n", readLine(t, bufio.NewReader(conn), conn))
+	assert.Nil(t, w.Close())
+}
+
+func TestNetWriter_PartialWrite(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Limits []int
+		Writes []string
+		Sent   []string
+	}{
+		{
+			Name:   "write",
+			Limits: []int{3, 100},
+			Writes: []string{"record\n"},
+			Sent:   []string{"rec", "record\n"},
+		},
+		{
+			Name:   "backlog",
+			Limits: []int{0, 9, 100},
+			Writes: []string{"first\n", "second\n"},
+			Sent:   []string{"", "first\nsec", "second\n"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var conns []*partialConn
+			w := NewNetWriter("tcp", "endpoint", NetOptions{ReconnectDelay: time.Hour})
+			w.mux.Lock()
+			for w.dialing {
+				w.dialed.Wait()
+			}
+			w.netDial = func(network, address string, timeout time.Duration) (net.Conn, error) {
+				conn := &partialConn{limit: tc.Limits[len(conns)]}
+				conns = append(conns, conn)
+				return conn, nil
+			}
+			w.conn = nil
+			w.nextAttempt = time.Time{}
+			w.connect()
+			for w.dialing {
+				w.dialed.Wait()
+			}
+			w.mux.Unlock()
+
+			for _, p := range tc.Writes {
+				n, err := w.Write([]byte(p))
+				assert.Nil(t, err)
+				assert.Equal(t, len(p), n)
+			}
+			// Each flush redials after the last connection failed.
+			for len(conns) < len(tc.Limits) {
+				_ = w.Flush()
+			}
+			assert.Nil(t, w.Flush())
+			assert.Equal(t, int64(0), w.Backlog())
+			assert.Nil(t, w.Close())
+
+			var sent []string
+			for _, conn := range conns {
+				sent = append(sent, string(conn.written))
+			}
+			assert.Equal(t, tc.Sent, sent)
+		})
+	}
+}
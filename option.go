@@ -0,0 +1,133 @@
+package loggy
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Option configures a logger created by NewWith. Since Options are applied on
+// top of DefaultOptions, rather than to a zero Options, they distinguish a
+// field that was explicitly set to its zero value, e.g. WithThreshold(LevelStd),
+// from one that was left unset. Profiles, e.g. Profile12Factor, are Options too.
+type Option func(options *Options)
+
+// NewWith creates a logger, as New does, from DefaultOptions with the provided
+// Options applied in order, e.g.
+//
+//	logger, ctx := loggy.NewWith(ctx, loggy.WithThreshold(loggy.LevelDebug), loggy.WithPrefix("~~~"))
+func NewWith(ctx context.Context, opts ...Option) (*logger, context.Context) {
+	options := DefaultOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return New(ctx, options)
+}
+
+// WithOptions replaces every option with those provided, e.g. to start from an
+// Options struct loaded from a configuration file, before applying the Options
+// that follow it.
+func WithOptions(o Options) Option {
+	return func(options *Options) {
+		*options = o
+	}
+}
+
+// WithName sets Options.Name.
+func WithName(name string) Option {
+	return func(options *Options) {
+		options.Name = name
+	}
+}
+
+// WithOut sets the output stream, Options.Out.
+func WithOut(out io.Writer) Option {
+	return func(options *Options) {
+		options.Out = out
+	}
+}
+
+// WithErr sets the error stream, Options.Err.
+func WithErr(err io.Writer) Option {
+	return func(options *Options) {
+		options.Err = err
+	}
+}
+
+// WithThreshold sets Options.Threshold. Unlike the Options struct, where the
+// zero value is LevelStd, the threshold is LevelInfo unless this is provided.
+func WithThreshold(threshold Level) Option {
+	return func(options *Options) {
+		options.Threshold = threshold
+	}
+}
+
+// WithThresholds sets the per-name threshold overrides, Options.Thresholds.
+func WithThresholds(thresholds map[string]Level) Option {
+	return func(options *Options) {
+		options.Thresholds = thresholds
+	}
+}
+
+// WithPrefix sets Options.Prefix.
+func WithPrefix(prefix string) Option {
+	return func(options *Options) {
+		options.Prefix = prefix
+	}
+}
+
+// WithColor sets Options.Color.
+func WithColor(color bool) Option {
+	return func(options *Options) {
+		options.Color = color
+	}
+}
+
+// WithEncoder sets Options.Encoder.
+func WithEncoder(encoder Encoder) Option {
+	return func(options *Options) {
+		options.Encoder = encoder
+	}
+}
+
+// WithTimestampFormat sets Options.TimestampFormat.
+func WithTimestampFormat(format string) Option {
+	return func(options *Options) {
+		options.TimestampFormat = format
+	}
+}
+
+// WithTimestampFunc sets Options.TimestampFunc.
+func WithTimestampFunc(fn func() time.Time) Option {
+	return func(options *Options) {
+		options.TimestampFunc = fn
+	}
+}
+
+// WithoutTimestamps sets Options.DisableTimestamps.
+func WithoutTimestamps() Option {
+	return func(options *Options) {
+		options.DisableTimestamps = true
+	}
+}
+
+// WithoutFunctionName sets Options.DisableFunctionName.
+func WithoutFunctionName() Option {
+	return func(options *Options) {
+		options.DisableFunctionName = true
+	}
+}
+
+// WithSampling sets Options.Sampling.
+func WithSampling(sampling *Sampling) Option {
+	return func(options *Options) {
+		options.Sampling = sampling
+	}
+}
+
+// WithProcessors appends to Options.Processors.
+func WithProcessors(processors ...Processor) Option {
+	return func(options *Options) {
+		options.Processors = append(append([]Processor{}, options.Processors...), processors...)
+	}
+}
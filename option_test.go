@@ -0,0 +1,93 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+var newWithTestCases = []struct {
+	Name     string
+	Options  []Option
+	Expected func(options *Options)
+}{
+	{
+		Name: "defaults",
+		Expected: func(options *Options) {
+			options.Threshold = LevelInfo
+		},
+	},
+	{
+		Name:    "explicit-zero-threshold",
+		Options: []Option{WithThreshold(LevelStd)},
+		Expected: func(options *Options) {
+			options.Threshold = LevelStd
+		},
+	},
+	{
+		Name:    "applied-in-order",
+		Options: []Option{WithThreshold(LevelDebug), WithPrefix("~~~"), WithThreshold(LevelWarning)},
+		Expected: func(options *Options) {
+			options.Threshold = LevelWarning
+			options.Prefix = "~~~"
+		},
+	},
+	{
+		Name:    "struct-then-options",
+		Options: []Option{WithOptions(Options{Name: "api", Threshold: LevelError}), WithoutTimestamps()},
+		Expected: func(options *Options) {
+			options.Name = "api"
+			options.Threshold = LevelError
+			options.DisableTimestamps = true
+		},
+	},
+	{
+		Name:    "profile",
+		Options: []Option{Profile12Factor},
+		Expected: func(options *Options) {
+			options.Err = os.Stdout
+			options.Encoder = JSONEncoder{EpochTimestamps: true}
+		},
+	},
+}
+
+func TestNewWith(t *testing.T) {
+	for _, tc := range newWithTestCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			l, _ := NewWith(context.Background(), tc.Options...)
+			actual := l.Options()
+
+			expected := DefaultOptions
+			tc.Expected(&expected)
+			assert.Equal(t, expected.Name, actual.Name)
+			assert.Equal(t, expected.Threshold, actual.Threshold)
+			assert.Equal(t, expected.Prefix, actual.Prefix)
+			assert.Equal(t, expected.Out, actual.Out)
+			assert.Equal(t, expected.Err, actual.Err)
+			assert.Equal(t, expected.DisableTimestamps, actual.DisableTimestamps)
+			assert.Equal(t, expected.TimestampEncoder != nil, actual.TimestampEncoder != nil)
+			assert.Equal(t, expected.Encoder, actual.Encoder)
+		})
+	}
+}
+
+func TestNewWith_Output(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	l, ctx := NewWith(context.Background(),
+		WithName("api"),
+		WithOut(stdout),
+		WithErr(stderr),
+		WithThreshold(LevelDebug),
+		WithPrefix("~~~"),
+		WithoutTimestamps(),
+		WithoutFunctionName(),
+	)
+
+	assert.Nil(t, l.Debug(ctx, "shown"))
+	assert.Nil(t, l.Critical(ctx, "oops"))
+	assert.Equal(t, "DEBUG api ~~~ shown\n", stdout.String())
+	assert.Equal(t, "CRIT api ~~~ oops\n", stderr.String())
+}
@@ -1,38 +1,140 @@
 package loggy
 
 import (
+	"context"
 	"io"
 	"os"
 	"time"
 )
 
 type Options struct {
+	// An optional preset configuration, e.g. Profile12Factor, applied when the
+	// logger is created. Fields set by the profile take precedence.
+	Profile Profile
+	// An optional name identifying the logger, e.g. the application or component,
+	// output after the severity.
+	Name string
 	// The underlying stdout logger.
 	Out io.Writer
 	// The underlying stderr logger.
 	Err io.Writer
+	// Named streams that messages can be routed to, regardless of severity, by
+	// tagging them with TagDestination, e.g. {"audit": auditFile}.
+	Destinations map[string]io.Writer
+	// Optional streams for individual levels, replacing Out or Err for messages
+	// of that level, e.g. {LevelCritical: alerts} sends Critical messages to an
+	// alerting stream, and {LevelWarning: os.Stdout} sends warnings to stdout.
+	// Messages routed by tag, or forced to a stream, are unaffected.
+	LevelOutputs map[Level]io.Writer
 	// The maximum severity to display for this logger. To disable logging completely, provide a Level < 0.
 	Threshold Level
+	// Overrides Threshold for named loggers, keyed by name, e.g. {"db.*":
+	// LevelDebug, "*": LevelInfo} shows Debug messages from the "db" logger and
+	// its descendants only. See Logger.Named and ParseThresholds.
+	Thresholds map[string]Level
 	// The text to place at the beginning of each log message, after the timestamp,
 	// severity, function name, and context tags.
 	Prefix string
+	// Set to true to color the severity labels with ANSI escape codes, see
+	// LevelColors. On Windows, escape code processing is enabled for console
+	// streams when the logger is created; if that fails, colors are disabled.
+	Color bool
 	// Set to true to disable timestamps. This is useful if piping logs into a writer
 	// that already uses timestamps.
 	DisableTimestamps bool
 	// Time format to use to output timestamps.
 	TimestampFormat string
+	// Optional function to encode timestamps, which takes precedence over
+	// TimestampFormat. See EpochSecondsEncoder, EpochMillisEncoder, and
+	// RFC3339NanoEncoder.
+	TimestampEncoder TimestampEncoder
 	// Timestamp function to get current time.
 	TimestampFunc func() time.Time
+	// Set to true to drop Info and Debug messages when the provided context has
+	// already been canceled, e.g. a request that was abandoned by the client.
+	// Standard messages, and Warning and above, are always kept.
+	SkipCanceled bool
+	// The Encoder that formats each message, e.g. JSONEncoder or LogfmtEncoder.
+	// If nil, the text layout of TextEncoder is used. Colors only apply to text.
+	Encoder Encoder
+	// Optionally writes messages in the background, rather than on the goroutine
+	// that logs them. It can't be changed by Logger.Reload. See Async.
+	Async *Async
+	// Optional sampling of repetitive messages, to limit throughput. Standard
+	// messages are never sampled.
+	Sampling *Sampling
+	// The verbose configuration to switch to while incident mode is on. If nil,
+	// DefaultIncident is used. See Logger.SetIncidentMode.
+	Incident *Incident
+	// Messages at this severity, or more severe, include a stack trace of the
+	// calling goroutine. The zero value, LevelStd, disables stack traces.
+	StacktraceLevel Level
+	// An optional stream that receives a one-line trace for each message that is
+	// dropped, stating why it was dropped. This is meant for debugging missing
+	// logs, and is not affected by the threshold.
+	DropTrace io.Writer
+	// The number of Warning, Error, and Critical entries to keep in memory for
+	// Logger.RecentErrors. If zero, or < 0, none are kept. It can't be changed by
+	// Logger.Reload.
+	RecentErrors int
+	// Set to true to detect common mistakes, such as logging to a closed writer
+	// or adding an excessive number of tags, and log a warning with a hint on
+	// fixing each one, once per call site. This is meant for development.
+	Diagnostics bool
+	// Set to true to also write Critical messages to os.Stderr, when they're sent
+	// somewhere else, e.g. a file or network sink. Orchestrators that only
+	// capture stderr then still see fatal conditions.
+	TeeCriticalStderr bool
+	// Set to true to log a summary when Logger.Close is called, with the uptime,
+	// the number of entries written at each level, the number filtered out by
+	// the threshold or a delegation, the number dropped, e.g. by sampling or a
+	// full Async queue, and the number that failed to write. This gives a cheap
+	// end-of-run report.
+	ShutdownSummary bool
+	// The function that Logger.Fatal and Logger.Fatalf call to exit, e.g. to
+	// record the exit status in tests. If nil, Exit is used, which flushes
+	// everything registered with FlushOnExit before calling os.Exit.
+	ExitFunc func(code int)
 	// Set to true to log un-resolvable internal errors as fatal logs. Otherwise, return the errors and log nothing.
 	LogFatal bool
+	// Set to true to include the stacks of all goroutines, rather than just the
+	// panicking goroutine, when logging a panic via HandlePanics.
+	PanicAllGoroutines bool
 	// Set to true to disable outputting the calling function name before the rest of the log message.
 	DisableFunctionName bool
+	// Optional function to rewrite the calling function name before it is output,
+	// e.g. to strip closure suffixes such as ".func1".
+	CallerFunc func(name string) string
 	// Set to true to disable outputting the context tags. This purely hides the tag
 	// list from being prepended to any log messages, the *Tag* helper functions will
-	// still work and will still manage state.
+	// still work and will still manage state. Fields passed with a message are
+	// still output.
 	DisableTags bool
-	// The context key where the logger can store tags exposed by the *Tag* helper functions.
-	TagsContextKey string
+	// Set to true to expand struct and map tag values into individual dotted tags,
+	// e.g. "user.ID:1, user.Name:bob" rather than "user:{1 bob}". See Flatten.
+	FlattenTags bool
+	// Optional context values to output as tags, keyed by tag name, e.g.
+	// {"user.id": auth.UserIDKey}, so that values stored in the context by other
+	// packages are logged without changing the call sites. Values that aren't
+	// set in the context are omitted. They take precedence over tags of the same
+	// name added via the *Tag* helper functions.
+	ContextValues map[string]interface{}
+	// Optional tags computed for each message when it's logged, e.g. the number
+	// of in-flight requests, keyed by tag name. They take precedence over tags
+	// of the same name from the context, and ContextValues, but not over Fields.
+	DynamicFields map[string]func(ctx context.Context) interface{}
+	// Optional functions that enrich, modify, or drop each message before it's
+	// formatted, called in order. See Processor.
+	Processors []Processor
+	// The context key where the logger can store tags exposed by the *Tag* helper
+	// functions. If unset, DefaultTagsKey is used. See NewTagsKey.
+	TagsContextKey TagsKey
+	// The maximum depth to walk nested tag values, such as structs within structs,
+	// before replacing them with a placeholder. Provide a value < 0 for no limit.
+	MaxValueDepth int
+	// The maximum number of slice, array, or map items to output for tag values.
+	// Provide a value < 0 for no limit.
+	MaxValueItems int
 }
 
 // DefaultOptions contains all the standard options that a logger will use when certain options are not provided.
@@ -46,5 +148,64 @@ var DefaultOptions = Options{
 	TimestampFunc:       time.Now,
 	LogFatal:            false,
 	DisableFunctionName: false,
-	TagsContextKey:      ContextKeyTags,
+	TagsContextKey:      DefaultTagsKey,
+	MaxValueDepth:       10,
+	MaxValueItems:       100,
+}
+
+// clone returns a copy of the options that shares no maps, slices, or pointers
+// with them, so that changes to either don't affect the other. The writers,
+// functions, and values they hold are shared.
+func (o Options) clone() Options {
+	if o.Destinations != nil {
+		destinations := make(map[string]io.Writer, len(o.Destinations))
+		for name, w := range o.Destinations {
+			destinations[name] = w
+		}
+		o.Destinations = destinations
+	}
+	if o.LevelOutputs != nil {
+		outputs := make(map[Level]io.Writer, len(o.LevelOutputs))
+		for level, w := range o.LevelOutputs {
+			outputs[level] = w
+		}
+		o.LevelOutputs = outputs
+	}
+	if o.Thresholds != nil {
+		thresholds := make(map[string]Level, len(o.Thresholds))
+		for name, level := range o.Thresholds {
+			thresholds[name] = level
+		}
+		o.Thresholds = thresholds
+	}
+	if o.ContextValues != nil {
+		values := make(map[string]interface{}, len(o.ContextValues))
+		for name, value := range o.ContextValues {
+			values[name] = value
+		}
+		o.ContextValues = values
+	}
+	if o.DynamicFields != nil {
+		fields := make(map[string]func(ctx context.Context) interface{}, len(o.DynamicFields))
+		for name, field := range o.DynamicFields {
+			fields[name] = field
+		}
+		o.DynamicFields = fields
+	}
+	if o.Processors != nil {
+		o.Processors = append([]Processor(nil), o.Processors...)
+	}
+	if o.Async != nil {
+		async := *o.Async
+		o.Async = &async
+	}
+	if o.Sampling != nil {
+		sampling := *o.Sampling
+		o.Sampling = &sampling
+	}
+	if o.Incident != nil {
+		incident := *o.Incident
+		o.Incident = &incident
+	}
+	return o
 }
@@ -0,0 +1,61 @@
+package loggy
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// HandlePanics logs any panic in progress at LevelCritical, as the message
+// "panic" with the panic value in the "panic" tag and the stack trace in
+// Entry.Stack, bypassing the threshold, delegations, and sampling, then flushes the
+// logger, including its async queue, and resumes panicking. It must be
+// deferred directly, typically at the top of main and of each goroutine:
+//
+//	defer loggy.HandlePanics(ctx, logger)
+//
+// Set Options.PanicAllGoroutines to include the stacks of every goroutine.
+func HandlePanics(ctx context.Context, l Logger) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	options := l.Options()
+	stack := debug.Stack()
+	if options.PanicAllGoroutines {
+		stack = allStacks()
+	}
+	fields := map[string]interface{}{"panic": fmt.Sprint(r)}
+	trace := strings.TrimSuffix(string(stack), "\n")
+	_, _ = l.Emit(ctx, LevelCritical, "panic", Bypass(), Fields(fields), withStack(trace))
+	_ = l.Flush()
+
+	panic(r)
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// flushWriter commits any data buffered by the writer, if it supports flushing
+// (e.g. bufio.Writer) or syncing (e.g. os.File).
+func flushWriter(w interface{}) error {
+	switch w := w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package loggy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestHandlePanics(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	buffered := bufio.NewWriter(stderr)
+	options := Options{
+		Err:               buffered,
+		Threshold:         LevelCritical,
+		DisableTimestamps: true,
+	}
+	l, ctx := New(context.Background(), options)
+	var entries []Entry
+	l.AddHook(nil, func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer HandlePanics(ctx, l)
+		panic("boom")
+	})
+
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "panic", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{"panic": "boom"}, entries[0].Fields)
+		assert.Regexp(t, regexp.MustCompile(`(?s)^goroutine [0-9]+ \[running\]:\n.*loggy.TestHandlePanics`), entries[0].Stack)
+	}
+	// The buffered stream must have been flushed.
+	assert.Regexp(t, regexp.MustCompile(`(?s)^CRIT loggy.HandlePanics \[panic:boom\] panic\ngoroutine [0-9]+ \[running\]:\n`), stderr.String())
+}
+
+func TestHandlePanics_AllGoroutines(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:                stderr,
+		Threshold:          LevelCritical,
+		DisableTimestamps:  true,
+		PanicAllGoroutines: true,
+	}
+	l, ctx := New(context.Background(), options)
+
+	block := make(chan struct{})
+	defer close(block)
+	go func() {
+		<-block
+	}()
+
+	assert.Panics(t, func() {
+		defer HandlePanics(ctx, l)
+		panic("boom")
+	})
+	assert.Regexp(t, regexp.MustCompile(`goroutine [0-9]+ \[chan receive\]`), stderr.String())
+}
+
+func TestHandlePanics_NoPanic(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:       stderr,
+		Threshold: LevelCritical,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.NotPanics(t, func() {
+		defer HandlePanics(ctx, l)
+	})
+	assert.Equal(t, "", stderr.String())
+}
+
+func TestHandlePanics_Async(t *testing.T) {
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Err:               stderr,
+		Threshold:         LevelCritical,
+		DisableTimestamps: true,
+		Async:             &Async{},
+		Processors: []Processor{
+			func(ctx context.Context, entry *Entry) bool {
+				return false
+			},
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	defer l.Close()
+
+	assert.Panics(t, func() {
+		defer HandlePanics(ctx, l)
+		panic("boom")
+	})
+	// The entry must have been written before resuming the panic, despite the
+	// processor dropping everything.
+	assert.Contains(t, stderr.String(), "CRIT loggy.HandlePanics [panic:boom] panic\n")
+}
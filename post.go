@@ -0,0 +1,216 @@
+package loggy
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDeliveryBackoff is the delay before the first retry, used when
+// Delivery.Backoff is zero.
+const DefaultDeliveryBackoff = time.Second
+
+// Delivery configures how the Sentry and Webhook integrations deliver requests.
+// A request stays in the queue, counting towards its size, until the server
+// acknowledges it with a 2xx status, or it's dropped.
+type Delivery struct {
+	// The maximum number of requests sent at once, awaiting acknowledgement. If
+	// zero, 1 is used, so requests are delivered in the order they were queued.
+	InFlight int
+	// The number of times a request that isn't acknowledged is retried before
+	// it's dropped, if it failed to send, or the server responded with 429 Too
+	// Many Requests or a 5xx status. If zero, it's dropped after the first
+	// attempt.
+	Retries int
+	// The delay before the first retry, doubled for each retry after it. If
+	// zero, DefaultDeliveryBackoff is used.
+	Backoff time.Duration
+}
+
+// poster sends HTTP requests in the background, for integrations such as
+// Sentry and Webhook, so that logging never waits on the network. Requests are
+// sent in the order they were queued, up to Delivery.InFlight at a time.
+type poster struct {
+	client   *http.Client
+	delivery Delivery
+	size     int64
+	requests chan postItem
+	// The number of requests queued or in flight, which haven't been
+	// acknowledged or dropped yet.
+	pending int64
+	// The number of requests dropped, as the queue was full or they failed.
+	dropped int64
+	// Limits the requests in flight, and tracks them for flush and close.
+	inFlight chan struct{}
+	sending  sync.WaitGroup
+	// Guards closed, and sends on requests against the channel being closed.
+	mux    sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// postItem is a request waiting to be sent, or a marker for flush.
+type postItem struct {
+	request *http.Request
+	// If set, this is a marker, closed once every request queued before it has
+	// been acknowledged, or dropped.
+	flushed chan struct{}
+}
+
+// newPoster starts the goroutine that sends requests. A nil client is replaced
+// by one with a 10 second timeout.
+func newPoster(client *http.Client, queueSize int, delivery Delivery) *poster {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if delivery.InFlight <= 0 {
+		delivery.InFlight = 1
+	}
+	if delivery.Backoff <= 0 {
+		delivery.Backoff = DefaultDeliveryBackoff
+	}
+	p := &poster{
+		client:   client,
+		delivery: delivery,
+		size:     int64(queueSize),
+		requests: make(chan postItem, queueSize),
+		inFlight: make(chan struct{}, delivery.InFlight),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// post queues the request, dropping it if the queue is full or closed.
+func (p *poster) post(request *http.Request) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	if p.closed {
+		p.drop()
+		return
+	}
+	if atomic.AddInt64(&p.pending, 1) > p.size {
+		atomic.AddInt64(&p.pending, -1)
+		p.drop()
+		return
+	}
+	select {
+	case p.requests <- postItem{request: request}:
+	default:
+		atomic.AddInt64(&p.pending, -1)
+		p.drop()
+	}
+}
+
+// drop counts a request that wasn't sent.
+func (p *poster) drop() {
+	atomic.AddInt64(&p.dropped, 1)
+}
+
+// flush waits until every request queued so far has been acknowledged, or
+// dropped.
+func (p *poster) flush() {
+	flushed := make(chan struct{})
+	p.mux.RLock()
+	closed := p.closed
+	if !closed {
+		p.requests <- postItem{flushed: flushed}
+	}
+	p.mux.RUnlock()
+
+	if closed {
+		<-p.done
+	} else {
+		<-flushed
+	}
+}
+
+// close delivers every queued request, then stops the background goroutine.
+// Calling close more than once has no effect.
+func (p *poster) close() {
+	p.mux.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.requests)
+	}
+	p.mux.Unlock()
+	<-p.done
+}
+
+func (p *poster) run() {
+	defer close(p.done)
+	defer p.sending.Wait()
+	for item := range p.requests {
+		if item.flushed != nil {
+			p.sending.Wait()
+			close(item.flushed)
+			continue
+		}
+		p.inFlight <- struct{}{}
+		p.sending.Add(1)
+		go func(request *http.Request) {
+			defer p.sending.Done()
+			p.deliver(request)
+			atomic.AddInt64(&p.pending, -1)
+			<-p.inFlight
+		}(item.request)
+	}
+}
+
+// deliver sends the request until it's acknowledged, retrying with a backoff,
+// and drops it once it runs out of retries.
+func (p *poster) deliver(request *http.Request) {
+	backoff := p.delivery.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := p.send(request)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= p.delivery.Retries {
+			p.drop()
+			return
+		}
+		if request, err = rewind(request); err != nil {
+			p.drop()
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// send sends a request, expecting a successful status, and reports whether a
+// failure is worth retrying.
+func (p *poster) send(request *http.Request) (bool, error) {
+	response, err := p.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode/100 == 5
+		return retry, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return false, nil
+}
+
+// rewind returns a copy of the request with its body reset, so it can be sent
+// again.
+func rewind(request *http.Request) (*http.Request, error) {
+	if request.Body == nil || request.GetBody == nil {
+		if request.ContentLength > 0 {
+			return nil, fmt.Errorf("request body can't be resent")
+		}
+		return request, nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	retry := request.Clone(request.Context())
+	retry.Body = body
+	return retry, nil
+}
@@ -0,0 +1,144 @@
+package loggy
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoster_Retries(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Status   int
+		Failures int
+		Retries  int
+		Attempts int
+		Bodies   []string
+		Dropped  int64
+	}{
+		{
+			Name:     "acknowledged",
+			Status:   http.StatusServiceUnavailable,
+			Failures: 0,
+			Retries:  2,
+			Attempts: 1,
+			Bodies:   []string{"event"},
+		},
+		{
+			Name:     "retried",
+			Status:   http.StatusServiceUnavailable,
+			Failures: 2,
+			Retries:  2,
+			Attempts: 3,
+			Bodies:   []string{"event", "event", "event"},
+		},
+		{
+			Name:     "rate-limited",
+			Status:   http.StatusTooManyRequests,
+			Failures: 1,
+			Retries:  2,
+			Attempts: 2,
+			Bodies:   []string{"event", "event"},
+		},
+		{
+			Name:     "out-of-retries",
+			Status:   http.StatusServiceUnavailable,
+			Failures: 3,
+			Retries:  2,
+			Attempts: 3,
+			Bodies:   []string{"event", "event", "event"},
+			Dropped:  1,
+		},
+		{
+			Name:     "no-retries",
+			Status:   http.StatusServiceUnavailable,
+			Failures: 1,
+			Attempts: 1,
+			Bodies:   []string{"event"},
+			Dropped:  1,
+		},
+		{
+			Name:     "rejected",
+			Status:   http.StatusBadRequest,
+			Failures: 1,
+			Retries:  2,
+			Attempts: 1,
+			Bodies:   []string{"event"},
+			Dropped:  1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var mux sync.Mutex
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.Nil(t, err)
+				mux.Lock()
+				bodies = append(bodies, string(body))
+				attempt := len(bodies)
+				mux.Unlock()
+				if attempt <= tc.Failures {
+					w.WriteHeader(tc.Status)
+				}
+			}))
+			defer server.Close()
+
+			p := newPoster(nil, 10, Delivery{Retries: tc.Retries, Backoff: time.Millisecond})
+			request, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("event")))
+			assert.Nil(t, err)
+			p.post(request)
+			p.flush()
+
+			assert.Len(t, bodies, tc.Attempts)
+			assert.Equal(t, tc.Bodies, bodies)
+			assert.Equal(t, tc.Dropped, atomic.LoadInt64(&p.dropped))
+			assert.Equal(t, int64(0), atomic.LoadInt64(&p.pending))
+			p.close()
+		})
+	}
+}
+
+func TestPoster_InFlight(t *testing.T) {
+	release := make(chan struct{})
+	var current, peak int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&current, 1)
+		for {
+			seen := atomic.LoadInt64(&peak)
+			if n <= seen || atomic.CompareAndSwapInt64(&peak, seen, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt64(&current, -1)
+	}))
+	defer server.Close()
+
+	p := newPoster(nil, 3, Delivery{InFlight: 2})
+	for i := 0; i < 4; i++ {
+		request, err := http.NewRequest(http.MethodPost, server.URL, nil)
+		assert.Nil(t, err)
+		p.post(request)
+	}
+
+	// Requests count towards the queue size until they're acknowledged, so the
+	// fourth is dropped even though two have left the queue to be sent.
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&current) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&p.pending))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&p.dropped))
+
+	close(release)
+	p.close()
+	assert.Equal(t, int64(2), atomic.LoadInt64(&peak))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&p.pending))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&p.dropped))
+}
@@ -0,0 +1,92 @@
+package loggy
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+// Processor enriches or modifies an entry before it's formatted, or drops it by
+// returning false. The entry's tags and fields belong to that entry alone, so
+// they may be changed freely. Processors are called in the order configured in
+// Options.Processors, after the threshold, delegations, and sampling have been
+// applied.
+type Processor func(ctx context.Context, entry *Entry) bool
+
+// process passes the entry through each processor, stopping at the first to
+// drop it.
+func process(ctx context.Context, processors []Processor, entry *Entry) bool {
+	// Copy the tags, since they may be shared with the context.
+	tags := make(map[string]interface{}, len(entry.Tags))
+	for name, value := range entry.Tags {
+		tags[name] = value
+	}
+	entry.Tags = tags
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]interface{})
+	}
+
+	for _, processor := range processors {
+		if !processor(ctx, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// LowercaseTags is a Processor that lowercases every tag and field name.
+func LowercaseTags(ctx context.Context, entry *Entry) bool {
+	for _, tags := range []map[string]interface{}{entry.Tags, entry.Fields} {
+		for name, value := range tags {
+			lower := strings.ToLower(name)
+			if lower != name {
+				delete(tags, name)
+				tags[lower] = value
+			}
+		}
+	}
+	return true
+}
+
+// DropEmptyTags is a Processor that removes tags and fields whose values are
+// nil, empty strings, or empty slices or maps.
+func DropEmptyTags(ctx context.Context, entry *Entry) bool {
+	for _, tags := range []map[string]interface{}{entry.Tags, entry.Fields} {
+		for name, value := range tags {
+			if isEmptyValue(value) {
+				delete(tags, name)
+			}
+		}
+	}
+	return true
+}
+
+// RenameTags returns a Processor that renames tags and fields, e.g.
+// {"uid": "user.id"}.
+func RenameTags(names map[string]string) Processor {
+	return func(ctx context.Context, entry *Entry) bool {
+		for _, tags := range []map[string]interface{}{entry.Tags, entry.Fields} {
+			for from, to := range names {
+				if value, ok := tags[from]; ok {
+					delete(tags, from)
+					tags[to] = value
+				}
+			}
+		}
+		return true
+	}
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
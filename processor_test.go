@@ -0,0 +1,93 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var processorTestCases = []struct {
+	Name      string
+	Processor Processor
+	Tags      map[string]interface{}
+	Expected  map[string]interface{}
+}{
+	{
+		Name:      "lowercase",
+		Processor: LowercaseTags,
+		Tags:      map[string]interface{}{"UserID": 1, "path": "/"},
+		Expected:  map[string]interface{}{"userid": 1, "path": "/"},
+	},
+	{
+		Name:      "drop-empty",
+		Processor: DropEmptyTags,
+		Tags: map[string]interface{}{
+			"nil":    nil,
+			"string": "",
+			"slice":  []int{},
+			"map":    map[string]int{},
+			"zero":   0,
+			"user":   "bob",
+		},
+		Expected: map[string]interface{}{"zero": 0, "user": "bob"},
+	},
+	{
+		Name:      "rename",
+		Processor: RenameTags(map[string]string{"uid": "user.id", "missing": "found"}),
+		Tags:      map[string]interface{}{"uid": 1, "path": "/"},
+		Expected:  map[string]interface{}{"user.id": 1, "path": "/"},
+	},
+}
+
+func TestProcessor(t *testing.T) {
+	for _, testCase := range processorTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			fields := make(map[string]interface{}, len(testCase.Tags))
+			for name, value := range testCase.Tags {
+				fields[name] = value
+			}
+			entry := &Entry{Tags: testCase.Tags, Fields: fields}
+			assert.True(t, testCase.Processor(context.Background(), entry))
+			assert.Equal(t, testCase.Expected, entry.Tags)
+			assert.Equal(t, testCase.Expected, entry.Fields)
+		})
+	}
+}
+
+func TestOptions_Processors(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	drops := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelDebug,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		DropTrace:           drops,
+		Processors: []Processor{
+			RenameTags(map[string]string{"uid": "user.id"}),
+			func(ctx context.Context, entry *Entry) bool {
+				// Drop health checks, and shout about everything else.
+				if entry.Tags["path"] == "/health" {
+					return false
+				}
+				entry.Message = strings.ToUpper(entry.Message)
+				entry.Tags["shard"] = 3
+				entry.Fields["batch"] = len(entry.Fields)
+				return true
+			},
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "uid", 1)
+	_, healthCtx := l.AddTag(context.Background(), "path", "/health")
+
+	assert.Nil(t, l.Infof(ctx, "%d pancakes", 3, Fields(map[string]interface{}{"uid": 2})))
+	assert.Nil(t, l.Info(healthCtx, "ok"))
+
+	assert.Equal(t, "INFO [batch:1, shard:3, user.id:2] 3 PANCAKES\n", stdout.String())
+	assert.Equal(t, "DROP processor INFO loggy.TestOptions_Processors \"ok\"\n", drops.String())
+	// The context's tags are left untouched.
+	assert.Equal(t, map[string]interface{}{"uid": 1}, l.Tags(ctx))
+}
@@ -0,0 +1,48 @@
+package loggy
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Profile is a preset configuration, applied to the options when a logger is
+// created, overriding any fields that the profile sets. See Options.Profile.
+type Profile = func(options *Options)
+
+// Profile12Factor configures a logger for the most common container deployment,
+// following the twelve-factor app methodology: every message is written to
+// stdout as a line of JSON, with an epoch timestamp, for the environment to
+// collect.
+func Profile12Factor(options *Options) {
+	options.Out = os.Stdout
+	options.Err = os.Stdout
+	options.Encoder = JSONEncoder{EpochTimestamps: true}
+	options.Color = false
+	options.DisableTimestamps = false
+}
+
+// NewDevelopment creates a logger suited to local development: every message,
+// including Debug, is written to the default streams with colored severities.
+func NewDevelopment(ctx context.Context) (*logger, context.Context) {
+	return New(ctx, Options{
+		Threshold: LevelDebug,
+		Color:     true,
+	})
+}
+
+// NewProduction creates a logger suited to production services: Info and more
+// severe messages are written as lines of JSON, repetitive messages are
+// sampled, and Error and Critical messages include a stack trace.
+func NewProduction(ctx context.Context) (*logger, context.Context) {
+	return New(ctx, Options{
+		Threshold: LevelInfo,
+		Encoder:   JSONEncoder{},
+		Sampling: &Sampling{
+			Initial:    100,
+			Thereafter: 100,
+			Tick:       time.Second,
+		},
+		StacktraceLevel: LevelError,
+	})
+}
@@ -0,0 +1,72 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestProfile12Factor(t *testing.T) {
+	options := Options{
+		Out:               bytes.NewBuffer([]byte{}),
+		Err:               bytes.NewBuffer([]byte{}),
+		Threshold:         LevelDebug,
+		Color:             true,
+		DisableTimestamps: true,
+		Profile:           Profile12Factor,
+	}
+	l, _ := New(context.Background(), options)
+
+	effective := l.Options()
+	assert.Equal(t, os.Stdout, effective.Out)
+	assert.Equal(t, os.Stdout, effective.Err)
+	assert.False(t, effective.Color)
+	assert.False(t, effective.DisableTimestamps)
+	assert.Equal(t, JSONEncoder{EpochTimestamps: true}, effective.Encoder)
+
+	// Fields the profile doesn't set are kept.
+	assert.Equal(t, LevelDebug, effective.Threshold)
+}
+
+func TestProfile12Factor_EpochTimestamps(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	l, ctx := New(context.Background(), Options{
+		Profile: func(options *Options) {
+			Profile12Factor(options)
+			options.Out = stdout
+		},
+		TimestampFunc: func() time.Time {
+			return loggyTestTime
+		},
+	})
+	assert.Nil(t, l.Std(ctx, "hi"))
+
+	var decoded map[string]interface{}
+	assert.Nil(t, json.Unmarshal(stdout.Bytes(), &decoded))
+	assert.Equal(t, float64(loggyTestTime.UnixNano())/1e9, decoded["time"])
+	assert.Nil(t, ValidateJSONEntry(stdout.Bytes()))
+}
+
+func TestNewDevelopment(t *testing.T) {
+	l, _ := NewDevelopment(context.Background())
+
+	options := l.Options()
+	assert.Equal(t, LevelDebug, options.Threshold)
+	assert.Equal(t, LevelStd, options.StacktraceLevel)
+	assert.Nil(t, options.Sampling)
+}
+
+func TestNewProduction(t *testing.T) {
+	l, _ := NewProduction(context.Background())
+
+	options := l.Options()
+	assert.Equal(t, LevelInfo, options.Threshold)
+	assert.Equal(t, LevelError, options.StacktraceLevel)
+	assert.False(t, options.Color)
+	assert.NotNil(t, options.Sampling)
+	assert.Equal(t, JSONEncoder{}, options.Encoder)
+}
@@ -0,0 +1,210 @@
+package loggy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// QueryTagPrefix prefixes the names of tags in queries, e.g. "tag:tenant".
+const QueryTagPrefix = "tag:"
+
+// Condition filters entries, matching those that satisfy all of its
+// comparisons. See Where and Query.
+type Condition struct {
+	comparisons []comparison
+	err         error
+}
+
+type comparison struct {
+	field string
+	op    string
+	value interface{}
+}
+
+// Where creates a Condition comparing one of an entry's fields to value, e.g.
+//
+//	loggy.Where("level", ">=", loggy.LevelError).And("tag:tenant", "=", "acme")
+//
+// The field is one of "time", "level", "logger", "caller", "code", "prefix",
+// "message", or a tag or field name prefixed by QueryTagPrefix. The operator is
+// one of "=", "!=", "<", "<=", ">", ">=", or "contains". Levels are compared by
+// their Severity, so "level >= LevelError" matches Error and Critical entries,
+// along with standard entries. Times are compared chronologically, numbers
+// numerically, and everything else by its %v formatting. A missing tag only
+// matches "!=".
+func Where(field, op string, value interface{}) *Condition {
+	return (&Condition{}).And(field, op, value)
+}
+
+// And returns a new condition that also requires the comparison. The original
+// condition is unchanged, so conditions can share a common base.
+func (c *Condition) And(field, op string, value interface{}) *Condition {
+	if c.err != nil {
+		return c
+	}
+	switch op {
+	case "=", "!=", "<", "<=", ">", ">=", "contains":
+	default:
+		return &Condition{err: fmt.Errorf("unknown query operator %q", op)}
+	}
+	switch field {
+	case "time", "level", "logger", "caller", "code", "prefix", "message":
+	default:
+		if !strings.HasPrefix(field, QueryTagPrefix) {
+			return &Condition{err: fmt.Errorf("unknown query field %q", field)}
+		}
+	}
+
+	comparisons := make([]comparison, len(c.comparisons), len(c.comparisons)+1)
+	copy(comparisons, c.comparisons)
+	return &Condition{
+		comparisons: append(comparisons, comparison{field: field, op: op, value: value}),
+	}
+}
+
+// Match reports whether the entry satisfies every comparison.
+func (c *Condition) Match(entry Entry) bool {
+	for _, comparison := range c.comparisons {
+		if !comparison.match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns the entries that match the condition, in their original order.
+// A nil condition matches every entry. An error is returned if the condition
+// has an unknown field or operator.
+func Query(entries []Entry, c *Condition) ([]Entry, error) {
+	if c == nil {
+		c = &Condition{}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	matched := []Entry{}
+	for _, entry := range entries {
+		if c.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched, nil
+}
+
+// CountBy counts the entries by the %v formatting of a field, named as in
+// Where, e.g. CountBy(entries, "tag:tenant"). Entries without the field are
+// counted under "".
+func CountBy(entries []Entry, field string) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		value, ok := queryField(entry, field)
+		key := ""
+		if ok {
+			if level, isLevel := value.(Severity); isLevel {
+				value = level.String()
+			}
+			key = fmt.Sprint(value)
+		}
+		counts[key]++
+	}
+	return counts
+}
+
+func (c comparison) match(entry Entry) bool {
+	actual, ok := queryField(entry, c.field)
+	if !ok {
+		return c.op == "!="
+	}
+	expected := c.value
+	if c.field == "level" {
+		if level, isLevel := expected.(Level); isLevel {
+			expected = SeverityOf(level)
+		}
+	}
+
+	if c.op == "contains" {
+		return strings.Contains(fmt.Sprint(actual), fmt.Sprint(expected))
+	}
+	order := compareValues(actual, expected)
+	switch c.op {
+	case "=":
+		return order == 0
+	case "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	}
+	return order >= 0
+}
+
+// queryField returns the value of an entry's field, named as in Where. Levels
+// are returned as their Severity.
+func queryField(entry Entry, field string) (interface{}, bool) {
+	switch field {
+	case "time":
+		return entry.Time, true
+	case "level":
+		return entry.Severity(), true
+	case "logger":
+		return entry.Logger, true
+	case "caller":
+		return entry.Caller, true
+	case "code":
+		return entry.Code, true
+	case "prefix":
+		return entry.Prefix, true
+	case "message":
+		return entry.Message, true
+	}
+	value, ok := entry.AllTags()[strings.TrimPrefix(field, QueryTagPrefix)]
+	return value, ok
+}
+
+// compareValues orders a relative to b, returning a negative number, zero, or a
+// positive number.
+func compareValues(a, b interface{}) int {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1
+			case at.After(bt):
+				return 1
+			}
+			return 0
+		}
+	}
+	if an, ok := toFloat(a); ok {
+		if bn, ok := toFloat(b); ok {
+			switch {
+			case an < bn:
+				return -1
+			case an > bn:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toFloat converts any integer or floating point value to a float64.
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,105 @@
+package loggy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var queryTestEntries = []Entry{
+	{Time: loggyTestTime, Level: LevelInfo, Message: "signed in", Tags: map[string]interface{}{"tenant": "acme", "attempt": 1}},
+	{Time: loggyTestTime.Add(time.Second), Level: LevelError, Message: "payment failed", Code: "E1", Tags: map[string]interface{}{"tenant": "acme"}},
+	{Time: loggyTestTime.Add(2 * time.Second), Level: LevelCritical, Message: "out of disk", Tags: map[string]interface{}{"tenant": "globex"}},
+	{Time: loggyTestTime.Add(3 * time.Second), Level: LevelWarning, Message: "retrying", Fields: map[string]interface{}{"tenant": "acme", "attempt": 3}},
+}
+
+var queryTestCases = []struct {
+	Name      string
+	Condition *Condition
+	Expected  []string
+	Err       string
+}{
+	{
+		Name:     "all",
+		Expected: []string{"signed in", "payment failed", "out of disk", "retrying"},
+	},
+	{
+		Name:      "level",
+		Condition: Where("level", ">=", LevelError),
+		Expected:  []string{"payment failed", "out of disk"},
+	},
+	{
+		Name:      "level-and-tag",
+		Condition: Where("level", ">=", LevelError).And("tag:tenant", "=", "acme"),
+		Expected:  []string{"payment failed"},
+	},
+	{
+		Name:      "field",
+		Condition: Where("tag:attempt", ">", 2),
+		Expected:  []string{"retrying"},
+	},
+	{
+		Name:      "missing-tag",
+		Condition: Where("tag:attempt", "!=", 1),
+		Expected:  []string{"payment failed", "out of disk", "retrying"},
+	},
+	{
+		Name:      "time",
+		Condition: Where("time", "<", loggyTestTime.Add(time.Second)),
+		Expected:  []string{"signed in"},
+	},
+	{
+		Name:      "contains",
+		Condition: Where("message", "contains", "fail").And("code", "=", "E1"),
+		Expected:  []string{"payment failed"},
+	},
+	{
+		Name:      "none",
+		Condition: Where("tag:tenant", "=", "initech"),
+		Expected:  []string{},
+	},
+	{
+		Name:      "unknown-operator",
+		Condition: Where("level", "~", LevelError),
+		Err:       `unknown query operator "~"`,
+	},
+	{
+		Name:      "unknown-field",
+		Condition: Where("level", "=", LevelError).And("tenant", "=", "acme"),
+		Err:       `unknown query field "tenant"`,
+	},
+}
+
+func TestQuery(t *testing.T) {
+	for _, testCase := range queryTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			entries, err := Query(queryTestEntries, testCase.Condition)
+			if testCase.Err != "" {
+				assert.EqualError(t, err, testCase.Err)
+				return
+			}
+			assert.Nil(t, err)
+			messages := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, testCase.Expected, messages)
+		})
+	}
+}
+
+func TestCondition_And(t *testing.T) {
+	base := Where("tag:tenant", "=", "acme")
+	errors := base.And("level", "=", LevelError)
+	warnings := base.And("level", "=", LevelWarning)
+
+	assert.True(t, errors.Match(queryTestEntries[1]))
+	assert.False(t, warnings.Match(queryTestEntries[1]))
+	assert.True(t, base.Match(queryTestEntries[0]))
+}
+
+func TestCountBy(t *testing.T) {
+	assert.Equal(t, map[string]int{"acme": 3, "globex": 1}, CountBy(queryTestEntries, "tag:tenant"))
+	assert.Equal(t, map[string]int{"INFO": 1, "ERROR": 1, "CRIT": 1, "WARN": 1}, CountBy(queryTestEntries, "level"))
+	assert.Equal(t, map[string]int{"": 2, "1": 1, "3": 1}, CountBy(queryTestEntries, "tag:attempt"))
+}
@@ -0,0 +1,95 @@
+package loggy
+
+import (
+	"runtime"
+	"sync"
+)
+
+// recentEntries is a fixed-size ring buffer of the most recent entries. The
+// entries count towards the limit set by SetMemoryLimit, until the logger is
+// closed, or garbage collected.
+type recentEntries struct {
+	mux     sync.Mutex
+	entries []Entry
+	// The budget the entries count towards, and the memory claimed for each.
+	budget *memoryBudget
+	sizes  []int
+	// The index the next entry is stored at.
+	next int
+	full bool
+}
+
+func newRecentEntries(size int) *recentEntries {
+	r := &recentEntries{
+		entries: make([]Entry, size),
+		budget:  memory,
+		sizes:   make([]int, size),
+	}
+	runtime.SetFinalizer(r, (*recentEntries).release)
+	return r
+}
+
+func (r *recentEntries) add(entry Entry) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	size := entrySize(entry)
+	r.budget.claim(size)
+	if old := r.sizes[r.next]; old > 0 {
+		r.budget.release(old)
+	}
+	r.entries[r.next] = entry
+	r.sizes[r.next] = size
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// release returns the memory claimed for the entries to the budget. The
+// entries are kept, but no longer counted.
+func (r *recentEntries) release() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	for i, size := range r.sizes {
+		if size > 0 {
+			r.budget.release(size)
+			r.sizes[i] = 0
+		}
+	}
+}
+
+// last returns up to n of the most recent entries, oldest first.
+func (r *recentEntries) last(n int) []Entry {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if n < 0 || n > count {
+		n = count
+	}
+
+	last := make([]Entry, n)
+	for i := 0; i < n; i++ {
+		index := (r.next - n + i + len(r.entries)) % len(r.entries)
+		last[i] = r.entries[index]
+	}
+	return last
+}
+
+// RecentErrors returns up to n of the most recent Warning, Error, and Critical
+// entries logged through this logger, or any logger derived from it, oldest
+// first. A negative n returns all of them. Up to Options.RecentErrors entries
+// are kept, in memory, so that health checks and admin endpoints can report
+// recent problems without parsing log files.
+func (l *logger) RecentErrors(n int) []Entry {
+	recent := l.root().recentErrors
+	if recent == nil {
+		return []Entry{}
+	}
+	return recent.last(n)
+}
@@ -0,0 +1,53 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLogger_RecentErrors(t *testing.T) {
+	options := Options{
+		Out:                 bytes.NewBuffer([]byte{}),
+		Err:                 bytes.NewBuffer([]byte{}),
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		RecentErrors:        3,
+		TimestampFunc: func() time.Time {
+			return loggyTestTime
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "user", "bob")
+	delegate := l.Delegate(Delegation{Threshold: LevelDebug})
+
+	assert.Equal(t, []Entry{}, l.RecentErrors(10))
+	assert.Nil(t, l.Warning(ctx, "one"))
+	assert.Nil(t, l.Info(ctx, "ignored"))
+	assert.Nil(t, l.Std(ctx, "ignored"))
+	assert.Nil(t, l.Logf(ctx, LevelError, "two"))
+	assert.Nil(t, delegate.Critical(ctx, "three", Code("E1")))
+	assert.Nil(t, l.Warning(ctx, "four"))
+
+	assert.Equal(t, []Entry{
+		{Time: loggyTestTime, Level: LevelCritical, Tags: map[string]interface{}{"user": "bob"}, Code: "E1", Message: "three"},
+		{Time: loggyTestTime, Level: LevelWarning, Tags: map[string]interface{}{"user": "bob"}, Message: "four"},
+	}, delegate.RecentErrors(2))
+	assert.Len(t, l.RecentErrors(-1), 3)
+	assert.Equal(t, "two", l.RecentErrors(-1)[0].Message)
+}
+
+func TestLogger_RecentErrors_Disabled(t *testing.T) {
+	for _, recentErrors := range []int{0, -1} {
+		options := Options{
+			Err:          bytes.NewBuffer([]byte{}),
+			RecentErrors: recentErrors,
+		}
+		l, ctx := New(context.Background(), options)
+
+		assert.Nil(t, l.Critical(ctx, "forgotten"))
+		assert.Equal(t, []Entry{}, l.RecentErrors(10), recentErrors)
+	}
+}
@@ -0,0 +1,172 @@
+package loggy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Reload replaces the logger's options, e.g. after its configuration file has
+// changed, filling in missing values as New does. Loggers derived from this one
+// pick up the new options too. The change is logged as a standard message,
+// tagged with each option that changed, from its old value to its new one,
+// e.g. "options.Threshold:INFO -> DEBUG", to leave an audit trail of
+// changes in production. Nothing is logged if no options changed. While
+// incident mode is on, the new options are compared with, and replace, the
+// options to restore once it's switched off, as returned by Options, and take
+// effect then; the incident overrides stay in place until then.
+func (l *logger) Reload(options Options) {
+	applyDefaults(&options)
+	root := l.root()
+
+	root.optionsMux.Lock()
+	old := root.options
+	if root.incidentBase != nil {
+		old = root.incidentBase
+		root.incidentBase = &options
+		root.options = incidentOptions(&options)
+	} else {
+		root.options = &options
+	}
+	if !sameValue(reflect.ValueOf(old.Sampling), reflect.ValueOf(options.Sampling)) {
+		root.sampler = nil
+		if options.Sampling != nil {
+			root.sampler = newSampler(*options.Sampling)
+		}
+	}
+	root.optionsMux.Unlock()
+
+	changes := diffOptions(old, &options)
+	if len(changes) == 0 {
+		return
+	}
+	_, _ = l.emit(context.Background(), 2, LevelStd, "options reloaded", Fields(changes))
+}
+
+// diffOptions returns the options that differ between old and updated, keyed by
+// "options.<name>", with values of the form "<old> -> <new>".
+func diffOptions(old, updated *Options) map[string]interface{} {
+	changes := make(map[string]interface{})
+	oldValue := reflect.ValueOf(old).Elem()
+	updatedValue := reflect.ValueOf(updated).Elem()
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.PkgPath != "" {
+			// Unexported fields aren't options, and can't be described.
+			continue
+		}
+		name := field.Name
+		a, b := oldValue.Field(i), updatedValue.Field(i)
+		if sameValue(a, b) {
+			continue
+		}
+		changes["options."+name] = fmt.Sprintf("%s -> %s", describeOption(name, a), describeOption(name, b))
+	}
+	return changes
+}
+
+// sameValue reports whether two option values are the same. Unlike
+// reflect.DeepEqual, functions are the same if they're the same function.
+func sameValue(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Func:
+		return a.Pointer() == b.Pointer()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type().Comparable() && b.Elem().Type().Comparable() {
+			return a.Interface() == b.Interface()
+		}
+		return sameValue(a.Elem(), b.Elem())
+	case reflect.Ptr:
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		if a.IsNil() || b.IsNil() {
+			return false
+		}
+		return sameValue(a.Elem(), b.Elem())
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameValue(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			value := b.MapIndex(iter.Key())
+			if !value.IsValid() || !sameValue(iter.Value(), value) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !sameValue(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	if !a.CanInterface() {
+		// An unexported field, e.g. of TagsKey, which can only be compared by its
+		// formatting.
+		return fmt.Sprint(a) == fmt.Sprint(b)
+	}
+	return a.Interface() == b.Interface()
+}
+
+// describeOption formats an option value for the reload message.
+func describeOption(name string, v reflect.Value) string {
+	switch name {
+	case "Threshold", "StacktraceLevel":
+		return describeLevel(Level(v.Int()))
+	}
+
+	switch v.Kind() {
+	case reflect.Func:
+		if v.IsNil() {
+			return "unset"
+		}
+		return "set"
+	case reflect.Interface:
+		if v.IsNil() {
+			return "unset"
+		}
+		return fmt.Sprintf("%T", v.Interface())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "unset"
+		}
+		return fmt.Sprintf("%+v", v.Elem().Interface())
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, fmt.Sprint(key.Interface()))
+		}
+		sort.Strings(keys)
+		return fmt.Sprintf("%v", keys)
+	case reflect.Slice:
+		return fmt.Sprintf("%d items", v.Len())
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
@@ -0,0 +1,125 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestLogger_Reload(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Sampling:            &Sampling{Initial: 1, Tick: time.Hour},
+	}
+	l, ctx := New(context.Background(), options)
+	delegate := l.Delegate(Delegation{Threshold: LevelDebug})
+
+	// Nothing is logged when the options are unchanged.
+	l.Reload(options)
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+
+	updated := options
+	updated.Threshold = LevelDebug
+	updated.Prefix = "~~~"
+	updated.Sampling = nil
+	updated.Destinations = map[string]io.Writer{"audit": stdout}
+	delegate.Reload(updated)
+	assert.Nil(t, delegate.Debug(ctx, "shown"))
+	assert.Nil(t, delegate.Debug(ctx, "shown"))
+
+	assert.Equal(t,
+		"OUT [options.Destinations:[] -> [audit], options.Prefix:\"\" -> \"~~~\", options.Sampling:{Initial:1 Thereafter:0 Tick:1h0m0s} -> unset, options.Threshold:INFO -> DEBUG] ~~~ options reloaded\n"+
+			"DEBUG ~~~ shown\nDEBUG ~~~ shown\n",
+		stdout.String(),
+	)
+}
+
+func TestLogger_Reload_IncidentMode(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelWarning,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	l.SetIncidentMode(true)
+
+	updated := options
+	updated.Threshold = LevelInfo
+	l.Reload(updated)
+	assert.Nil(t, l.Debug(ctx, "incident"))
+	l.SetIncidentMode(false)
+	assert.Nil(t, l.Info(ctx, "reloaded"))
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+
+	assert.Contains(t, stdout.String(), "OUT [options.Threshold:WARN -> INFO] options reloaded\nDEBUG incident\n")
+	assert.Contains(t, stdout.String(), "INFO reloaded\n")
+	assert.NotContains(t, stdout.String(), "hidden")
+}
+
+func TestLogger_Reload_IncidentTee(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	incident := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Incident:            &Incident{Tee: incident},
+	}
+	l, ctx := New(context.Background(), options)
+	l.SetIncidentMode(true)
+
+	updated := l.Options()
+	updated.Prefix = "~~~"
+	assert.NotPanics(t, func() {
+		l.Reload(updated)
+	})
+	assert.Nil(t, l.Debug(ctx, "teed"))
+
+	assert.Contains(t, stdout.String(), "options reloaded\n")
+	assert.Contains(t, incident.String(), "DEBUG ~~~ teed\n")
+}
+
+func TestLogger_Reload_IncidentBaseline(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelWarning,
+		Thresholds:          map[string]Level{"db": LevelError},
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	l.SetIncidentMode(true)
+
+	// Reloading the options read during incident mode changes nothing.
+	l.Reload(l.Options())
+	assert.NotContains(t, stdout.String(), "options reloaded")
+	assert.Nil(t, l.Debug(ctx, "incident"))
+
+	l.SetIncidentMode(false)
+	assert.Equal(t, LevelWarning, l.GetThreshold())
+	assert.Equal(t, map[string]Level{"db": LevelError}, l.Options().Thresholds)
+	assert.Nil(t, l.Info(ctx, "hidden"))
+	assert.Contains(t, stdout.String(), "DEBUG incident\n")
+	assert.NotContains(t, stdout.String(), "hidden")
+}
+
+func TestDiffOptions_Unexported(t *testing.T) {
+	// Every field must be an option that can be compared and described, or be
+	// unexported, and skipped.
+	options := Options{}
+	applyDefaults(&options)
+	updated := options.clone()
+	updated.Prefix = "~~~"
+	assert.Equal(t, map[string]interface{}{"options.Prefix": `"" -> "~~~"`}, diffOptions(&options, &updated))
+}
@@ -0,0 +1,26 @@
+package loggy
+
+import (
+	"io"
+)
+
+// EmitResult describes the delivery of a single log message.
+type EmitResult struct {
+	// Whether the message was dropped before being written, e.g. because its
+	// severity was above the threshold.
+	Filtered bool
+	// Whether the message was dropped by sampling. Sampled messages are also
+	// Filtered.
+	Sampled bool
+	// Why the message was dropped, if it was Filtered.
+	Reason DropReason
+	// The stream the message was written to. Nil if the message was filtered.
+	Destination io.Writer
+	// The compiled message, as it was written to the destination.
+	Output []byte
+	// The number of bytes accepted by the destination.
+	Written int
+	// Whether the message was queued to be written in the background, as
+	// configured by Options.Async. Destination, Output, and Written are unset.
+	Queued bool
+}
@@ -0,0 +1,44 @@
+package loggy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TagDestination is a reserved tag name, used to route individual messages to
+// one or more of the streams named in Options.Destinations, instead of the
+// output or error stream selected by severity. Multiple destinations are
+// separated by commas, e.g. "audit,security". The tag is not output.
+const TagDestination = "loggy.dest"
+
+// routeByTag looks up the destinations named by the TagDestination tag. It
+// returns the tags without the reserved tag, and a writer for the named
+// destinations, or nil if none of them are configured.
+func routeByTag(options *Options, tags map[string]interface{}) (map[string]interface{}, io.Writer) {
+	value, ok := tags[TagDestination]
+	if !ok {
+		return tags, nil
+	}
+
+	remaining := make(map[string]interface{}, len(tags)-1)
+	for name, tag := range tags {
+		if name != TagDestination {
+			remaining[name] = tag
+		}
+	}
+
+	var writers []io.Writer
+	for _, name := range strings.Split(fmt.Sprint(value), ",") {
+		if w, ok := options.Destinations[strings.TrimSpace(name)]; ok && w != nil {
+			writers = append(writers, w)
+		}
+	}
+	switch len(writers) {
+	case 0:
+		return remaining, nil
+	case 1:
+		return remaining, writers[0]
+	}
+	return remaining, destinations(writers)
+}
@@ -0,0 +1,41 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestOptions_Destinations(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	audit := bytes.NewBuffer([]byte{})
+	security := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Err:                 stderr,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		Destinations: map[string]io.Writer{
+			"audit":    audit,
+			"security": security,
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "user", "bob")
+
+	assert.Nil(t, l.Info(ctx, "routine"))
+	assert.Nil(t, l.Critical(ctx, "login failed", Fields(map[string]interface{}{TagDestination: "audit, security"})))
+	_, auditCtx := l.AddTag(ctx, TagDestination, "audit")
+	assert.Nil(t, l.Info(auditCtx, "record deleted"))
+	_, unknownCtx := l.AddTag(ctx, TagDestination, "nowhere")
+	assert.Nil(t, l.Warning(unknownCtx, "unrouted"))
+
+	assert.Equal(t, "INFO [user:bob] routine\n", stdout.String())
+	assert.Equal(t, "CRIT [user:bob] login failed\nINFO [user:bob] record deleted\n", audit.String())
+	assert.Equal(t, "CRIT [user:bob] login failed\n", security.String())
+	assert.Equal(t, "WARN [user:bob] unrouted\n", stderr.String())
+}
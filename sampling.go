@@ -0,0 +1,67 @@
+package loggy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sampling limits the throughput of repetitive messages. Within each Tick, the
+// first Initial messages with the same severity and text are written, then
+// every Thereafter-th message after that. The rest are dropped. See
+// Options.Sampling.
+type Sampling struct {
+	// The number of identical messages written, per Tick, before sampling begins.
+	Initial int
+	// Once sampling, every Thereafter-th message is written. A value < 1 drops
+	// every message after the Initial ones, until the next Tick.
+	Thereafter int
+	// The length of each sampling window. Defaults to one second.
+	Tick time.Duration
+}
+
+// sampler tracks how many times each message has been seen.
+type sampler struct {
+	config Sampling
+	mux    sync.Mutex
+	// When the current sampling window started.
+	start  time.Time
+	counts map[string]int
+}
+
+func newSampler(config Sampling) *sampler {
+	if config.Tick <= 0 {
+		config.Tick = time.Second
+	}
+	return &sampler{
+		config: config,
+		counts: make(map[string]int),
+	}
+}
+
+// sample reports whether the message should be written.
+func (s *sampler) sample(now time.Time, severity Level, format string, message []interface{}) bool {
+	key := format
+	if key == "" {
+		key = fmt.Sprint(message...)
+	}
+	key = fmt.Sprintf("%d:%s", severity, key)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if now.Sub(s.start) >= s.config.Tick || now.Before(s.start) {
+		s.start = now
+		s.counts = make(map[string]int)
+	}
+	s.counts[key]++
+	count := s.counts[key]
+
+	if count <= s.config.Initial {
+		return true
+	}
+	if s.config.Thereafter < 1 {
+		return false
+	}
+	return (count-s.config.Initial)%s.config.Thereafter == 0
+}
@@ -0,0 +1,86 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptions_Sampling(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	now := loggyTestTime
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		TimestampFunc: func() time.Time {
+			return now
+		},
+		Sampling: &Sampling{
+			Initial:    2,
+			Thereafter: 3,
+			Tick:       time.Second,
+		},
+	}
+	l, ctx := New(context.Background(), options)
+
+	var sampled int
+	for i := 1; i <= 8; i++ {
+		result, err := l.Emit(ctx, LevelInfo, "tick %d", i)
+		assert.Nil(t, err)
+		if result.Sampled {
+			assert.True(t, result.Filtered)
+			sampled++
+		}
+	}
+	// Standard messages and bypassed messages are never sampled.
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.Stdf(ctx, "std %d", i))
+	}
+	assert.Nil(t, l.Infof(ctx, "tick %d", 9, Bypass()))
+
+	// The next window starts over.
+	now = now.Add(time.Second)
+	assert.Nil(t, l.Infof(ctx, "tick %d", 10))
+
+	assert.Equal(t, 4, sampled)
+	assert.Equal(t, []string{
+		"INFO tick 1",
+		"INFO tick 2",
+		"INFO tick 5",
+		"INFO tick 8",
+		"OUT std 0",
+		"OUT std 1",
+		"OUT std 2",
+		"INFO tick 9",
+		"INFO tick 10",
+	}, strings.Split(strings.TrimSpace(stdout.String()), "\n"))
+}
+
+func TestOptions_StacktraceLevel(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Err:               stderr,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+		StacktraceLevel:   LevelError,
+	}
+	l, ctx := New(context.Background(), options)
+
+	assert.Nil(t, l.Warning(ctx, "no trace"))
+	assert.Equal(t, "WARN loggy.TestOptions_StacktraceLevel no trace\n", stderr.String())
+
+	stderr.Reset()
+	assert.Nil(t, l.Critical(ctx, "trace"))
+	lines := strings.Split(stderr.String(), "\n")
+	assert.Equal(t, "CRIT loggy.TestOptions_StacktraceLevel trace", lines[0])
+	assert.Equal(t, "github.com/foresthoffman/loggy.TestOptions_StacktraceLevel", lines[1])
+	assert.Regexp(t, `^\t.*sampling_test.go:[0-9]+$`, lines[2])
+	assert.NotContains(t, stderr.String(), "loggy.(*logger)")
+}
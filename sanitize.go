@@ -0,0 +1,208 @@
+package loggy
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	// SanitizedCycle replaces any value that refers back to one of its parents.
+	SanitizedCycle = "<cycle>"
+	// SanitizedMaxDepth replaces any value nested deeper than the maximum depth.
+	SanitizedMaxDepth = "<max depth>"
+	// SanitizedTruncatedKey is the map key under which the number of omitted
+	// entries is stored, when a map is truncated.
+	SanitizedTruncatedKey = "<truncated>"
+)
+
+// Sanitize makes value safe to format, by bounding how deeply nested values are
+// walked, detecting reference cycles, and capping the number of items output
+// for slices, arrays, and maps. A limit < 0 disables that check.
+//
+// Values that are within all limits are returned unchanged. Otherwise a bounded
+// copy is returned, where structs and maps are converted to
+// map[string]interface{}, slices and arrays are converted to []interface{}, and
+// anything over the limits is replaced by one of the Sanitized* markers.
+func Sanitize(value interface{}, maxDepth, maxItems int) interface{} {
+	switch value.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16,
+		uint32, uint64, uintptr, float32, float64, complex64, complex128:
+		// Skip reflection for the common cases.
+		return value
+	}
+
+	s := &sanitizer{
+		maxDepth: maxDepth,
+		maxItems: maxItems,
+		visiting: make(map[uintptr]bool),
+	}
+	sanitized := s.sanitize(reflect.ValueOf(value), 0)
+	if !s.modified {
+		return value
+	}
+
+	return sanitized
+}
+
+type sanitizer struct {
+	maxDepth int
+	maxItems int
+	// The addresses of the pointers, maps, and slices being walked.
+	visiting map[uintptr]bool
+	// Whether any part of the value was replaced or truncated.
+	modified bool
+}
+
+func (s *sanitizer) sanitize(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if isSelfFormatting(v) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Ptr {
+			if s.visiting[v.Pointer()] {
+				s.modified = true
+				return SanitizedCycle
+			}
+			s.visiting[v.Pointer()] = true
+			defer delete(s.visiting, v.Pointer())
+		}
+		return s.sanitize(v.Elem(), depth)
+	case reflect.Struct:
+		if s.exceedsDepth(depth) {
+			return SanitizedMaxDepth
+		}
+		sanitized := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			sanitized[v.Type().Field(i).Name] = s.sanitize(v.Field(i), depth+1)
+		}
+		return sanitized
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if s.visiting[v.Pointer()] {
+			s.modified = true
+			return SanitizedCycle
+		}
+		if s.exceedsDepth(depth) {
+			return SanitizedMaxDepth
+		}
+		s.visiting[v.Pointer()] = true
+		defer delete(s.visiting, v.Pointer())
+
+		// Sort the keys, so the same entries are kept when truncating.
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = fmt.Sprintf("%v", s.sanitize(key, depth+1))
+		}
+		sort.Sort(mapKeys{keys: keys, names: names})
+
+		sanitized := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			if s.exceedsItems(i) {
+				sanitized[SanitizedTruncatedKey] = len(keys) - i
+				break
+			}
+			sanitized[names[i]] = s.sanitize(v.MapIndex(key), depth+1)
+		}
+		return sanitized
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return nil
+			}
+			if v.Len() > 0 {
+				if s.visiting[v.Pointer()] {
+					s.modified = true
+					return SanitizedCycle
+				}
+				s.visiting[v.Pointer()] = true
+				defer delete(s.visiting, v.Pointer())
+			}
+		}
+		if s.exceedsDepth(depth) {
+			return SanitizedMaxDepth
+		}
+
+		sanitized := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if s.exceedsItems(i) {
+				sanitized = append(sanitized, fmt.Sprintf("<%d more>", v.Len()-i))
+				break
+			}
+			sanitized = append(sanitized, s.sanitize(v.Index(i), depth+1))
+		}
+		return sanitized
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex()
+	case reflect.String:
+		return v.String()
+	}
+
+	// Channels, functions, and unsafe pointers.
+	return fmt.Sprintf("<%s>", v.Type())
+}
+
+func (s *sanitizer) exceedsDepth(depth int) bool {
+	if s.maxDepth >= 0 && depth > s.maxDepth {
+		s.modified = true
+		return true
+	}
+	return false
+}
+
+func (s *sanitizer) exceedsItems(count int) bool {
+	if s.maxItems >= 0 && count >= s.maxItems {
+		s.modified = true
+		return true
+	}
+	return false
+}
+
+// isSelfFormatting reports whether the value controls its own formatting, in
+// which case it's passed through as-is rather than being walked.
+func isSelfFormatting(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case error, fmt.Stringer, fmt.Formatter, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// mapKeys sorts map keys by their formatted names.
+type mapKeys struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (m mapKeys) Len() int           { return len(m.keys) }
+func (m mapKeys) Less(i, j int) bool { return m.names[i] < m.names[j] }
+func (m mapKeys) Swap(i, j int) {
+	m.keys[i], m.keys[j] = m.keys[j], m.keys[i]
+	m.names[i], m.names[j] = m.names[j], m.names[i]
+}
@@ -0,0 +1,142 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type sanitizeNode struct {
+	Name string
+	Next *sanitizeNode
+}
+
+type sanitizeNested struct {
+	Inner interface{}
+}
+
+func sanitizeCycle() *sanitizeNode {
+	node := &sanitizeNode{Name: "a"}
+	node.Next = &sanitizeNode{Name: "b", Next: node}
+	return node
+}
+
+func sanitizeSelfMap() map[string]interface{} {
+	m := map[string]interface{}{"name": "self"}
+	m["self"] = m
+	return m
+}
+
+var sanitizeTestCases = []struct {
+	Name     string
+	Value    interface{}
+	MaxDepth int
+	MaxItems int
+	Expected interface{}
+}{
+	{
+		Name:     "primitive",
+		Value:    42,
+		MaxDepth: 1,
+		MaxItems: 1,
+		Expected: 42,
+	},
+	{
+		Name:     "within-limits",
+		Value:    sanitizeNode{Name: "a"},
+		MaxDepth: 5,
+		MaxItems: 5,
+		Expected: sanitizeNode{Name: "a"},
+	},
+	{
+		Name:     "error",
+		Value:    errors.New("oops"),
+		MaxDepth: 0,
+		MaxItems: 0,
+		Expected: errors.New("oops"),
+	},
+	{
+		Name:     "pointer-cycle",
+		Value:    sanitizeCycle(),
+		MaxDepth: -1,
+		MaxItems: -1,
+		Expected: map[string]interface{}{
+			"Name": "a",
+			"Next": map[string]interface{}{
+				"Name": "b",
+				"Next": SanitizedCycle,
+			},
+		},
+	},
+	{
+		Name:     "map-cycle",
+		Value:    sanitizeSelfMap(),
+		MaxDepth: -1,
+		MaxItems: -1,
+		Expected: map[string]interface{}{
+			"name": "self",
+			"self": SanitizedCycle,
+		},
+	},
+	{
+		Name: "max-depth",
+		Value: sanitizeNested{
+			Inner: sanitizeNested{
+				Inner: sanitizeNested{Inner: 1},
+			},
+		},
+		MaxDepth: 1,
+		MaxItems: -1,
+		Expected: map[string]interface{}{
+			"Inner": map[string]interface{}{
+				"Inner": SanitizedMaxDepth,
+			},
+		},
+	},
+	{
+		Name:     "max-items-slice",
+		Value:    []int{1, 2, 3, 4, 5},
+		MaxDepth: -1,
+		MaxItems: 2,
+		Expected: []interface{}{int64(1), int64(2), "<3 more>"},
+	},
+	{
+		Name:     "max-items-map",
+		Value:    map[string]int{"c": 3, "a": 1, "b": 2},
+		MaxDepth: -1,
+		MaxItems: 2,
+		Expected: map[string]interface{}{
+			"a":                   int64(1),
+			"b":                   int64(2),
+			SanitizedTruncatedKey: 1,
+		},
+	},
+}
+
+func TestSanitize(t *testing.T) {
+	for _, testCase := range sanitizeTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			actual := Sanitize(testCase.Value, testCase.MaxDepth, testCase.MaxItems)
+			assert.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
+
+func TestLogger_Log_SanitizedTags(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		MaxValueItems:       3,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "self", sanitizeSelfMap())
+	_, ctx = l.AddTag(ctx, "ids", []int{1, 2, 3, 4})
+
+	assert.Nil(t, l.Info(ctx, "still here"))
+	assert.Equal(t, "INFO [ids:[1 2 3 <1 more>], self:map[name:self self:<cycle>]] still here\n", stdout.String())
+}
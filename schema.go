@@ -0,0 +1,177 @@
+package loggy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrSchemaMismatch is returned when an encoded entry doesn't match JSONSchema.
+var ErrSchemaMismatch = errors.New("entry doesn't match the JSON schema")
+
+// JSONSchema is the JSON Schema that each entry encoded by JSONEncoder matches,
+// for the entry layout identified by SchemaVersion. Consumers can use it to
+// generate parsers, or to validate the logs they ingest.
+const JSONSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/foresthoffman/loggy/schema/1",
+  "title": "loggy entry",
+  "type": "object",
+  "required": ["schema", "level", "message"],
+  "additionalProperties": false,
+  "properties": {
+    "schema": {"type": "integer", "enum": [1]},
+    "time": {"type": ["string", "number"], "minLength": 1},
+    "level": {"type": "string", "enum": ["OUT", "CRIT", "ERROR", "WARN", "INFO", "DEBUG", "TRACE"]},
+    "logger": {"type": "string", "minLength": 1},
+    "caller": {"type": "string", "minLength": 1},
+    "code": {"type": "string", "minLength": 1},
+    "docs": {"type": "string", "minLength": 1},
+    "prefix": {"type": "string", "minLength": 1},
+    "message": {"type": "string"},
+    "tags": {"type": "object", "minProperties": 1},
+    "stack": {"type": "string", "minLength": 1}
+  }
+}`
+
+// jsonSchema is the subset of JSON Schema used by JSONSchema.
+type jsonSchema struct {
+	Type                 jsonTypes              `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	MinLength            int                    `json:"minLength"`
+	MinProperties        int                    `json:"minProperties"`
+}
+
+// jsonTypes is the type keyword of a schema, which is either a single type, or a
+// list of the types allowed.
+type jsonTypes []string
+
+func (t *jsonTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = jsonTypes{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var (
+	parseSchemaOnce sync.Once
+	parsedSchema    *jsonSchema
+)
+
+// ValidateJSONEntry checks that the line, as encoded by JSONEncoder, matches
+// JSONSchema, returning an error wrapping ErrSchemaMismatch that describes the
+// first difference if it doesn't. See JSONEncoder.Validate.
+func ValidateJSONEntry(line []byte) error {
+	parseSchemaOnce.Do(func() {
+		parsedSchema = &jsonSchema{}
+		if err := json.Unmarshal([]byte(JSONSchema), parsedSchema); err != nil {
+			panic(fmt.Sprintf("loggy: invalid JSONSchema: %v", err))
+		}
+	})
+
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaMismatch, err)
+	}
+	return parsedSchema.validate("entry", value)
+}
+
+// validate checks the value against the schema, naming it by path in errors.
+func (s *jsonSchema) validate(path string, value interface{}) error {
+	mismatch := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s %s", ErrSchemaMismatch, path, fmt.Sprintf(format, args...))
+	}
+
+	matched := ""
+	for _, kind := range s.Type {
+		if jsonTypeMatches(kind, value) {
+			matched = kind
+			break
+		}
+	}
+	if matched == "" && len(s.Type) > 0 {
+		article := "a"
+		if strings.ContainsAny(s.Type[0][:1], "aeiou") {
+			article = "an"
+		}
+		return mismatch("must be %s %s", article, strings.Join(s.Type, " or "))
+	}
+
+	switch matched {
+	case "string":
+		if len(value.(string)) < s.MinLength {
+			return mismatch("must not be empty")
+		}
+	case "object":
+		object := value.(map[string]interface{})
+		if len(object) < s.MinProperties {
+			return mismatch("must not be empty")
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return mismatch("is missing %q", name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return mismatch("has unexpected key %q", name)
+				}
+				continue
+			}
+			if err := property.validate(path+"."+name, object[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return nil
+			}
+		}
+		return mismatch("must be one of %v", s.Enum)
+	}
+	return nil
+}
+
+// jsonTypeMatches reports whether the decoded value is of the JSON Schema type.
+func jsonTypeMatches(kind string, value interface{}) bool {
+	switch kind {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package loggy
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var validateJSONEntryTestCases = []struct {
+	Name     string
+	Line     string
+	Expected string
+}{
+	{
+		Name: "minimal",
+		Line: `{"schema":1,"level":"OUT","message":""}`,
+	},
+	{
+		Name: "full",
+		Line: `{"schema":1,"time":"2006-01-02T15:04:05Z","level":"ERROR","logger":"app","caller":"main.main",` +
+			`"code":"E1","docs":"https://example.com","prefix":"~","message":"oops","tags":{"user":"bob"},"stack":"main.main()"}`,
+	},
+	{
+		Name: "epoch-time",
+		Line: `{"schema":1,"time":1136214245.123456789,"level":"INFO","message":""}`,
+	},
+	{
+		Name:     "wrong-time-type",
+		Line:     `{"schema":1,"time":true,"level":"INFO","message":""}`,
+		Expected: "entry doesn't match the JSON schema: entry.time must be a string or number",
+	},
+	{
+		Name:     "not-json",
+		Line:     `level=INFO`,
+		Expected: "entry doesn't match the JSON schema: invalid character 'l' looking for beginning of value",
+	},
+	{
+		Name:     "missing-message",
+		Line:     `{"schema":1,"level":"INFO"}`,
+		Expected: `entry doesn't match the JSON schema: entry is missing "message"`,
+	},
+	{
+		Name:     "unknown-level",
+		Line:     `{"schema":1,"level":"FATAL","message":""}`,
+		Expected: "entry doesn't match the JSON schema: entry.level must be one of [OUT CRIT ERROR WARN INFO DEBUG TRACE]",
+	},
+	{
+		Name:     "old-schema",
+		Line:     `{"schema":0,"level":"INFO","message":""}`,
+		Expected: "entry doesn't match the JSON schema: entry.schema must be one of [1]",
+	},
+	{
+		Name:     "wrong-type",
+		Line:     `{"schema":1,"level":"INFO","message":2}`,
+		Expected: "entry doesn't match the JSON schema: entry.message must be a string",
+	},
+	{
+		Name:     "unexpected-key",
+		Line:     `{"schema":1,"level":"INFO","message":"","user":"bob"}`,
+		Expected: `entry doesn't match the JSON schema: entry has unexpected key "user"`,
+	},
+	{
+		Name:     "empty-tags",
+		Line:     `{"schema":1,"level":"INFO","message":"","tags":{}}`,
+		Expected: "entry doesn't match the JSON schema: entry.tags must not be empty",
+	},
+}
+
+func TestValidateJSONEntry(t *testing.T) {
+	for _, testCase := range validateJSONEntryTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			err := ValidateJSONEntry([]byte(testCase.Line))
+			if testCase.Expected == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.Expected)
+			assert.True(t, errors.Is(err, ErrSchemaMismatch))
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	var schema jsonSchema
+	assert.Nil(t, json.Unmarshal([]byte(JSONSchema), &schema))
+
+	// The schema must describe the current SchemaVersion, and every level.
+	assert.Equal(t, []interface{}{float64(SchemaVersion)}, schema.Properties["schema"].Enum)
+	assert.Len(t, schema.Properties["level"].Enum, len(LevelNames))
+	for _, name := range LevelNames {
+		assert.Contains(t, schema.Properties["level"].Enum, name)
+	}
+}
+
+func TestJSONEncoder_Encode_Validate(t *testing.T) {
+	encoder := JSONEncoder{Validate: true}
+
+	for _, testCase := range jsonEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+		})
+	}
+
+	_, err := encoder.Encode(Entry{Level: Level(42), Message: "hi"})
+	assert.EqualError(t, err, "entry doesn't match the JSON schema: entry.level must be one of [OUT CRIT ERROR WARN INFO DEBUG TRACE]")
+}
@@ -0,0 +1,77 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+)
+
+// SecurityEventType classifies security events, so that SIEM rules can match on
+// a stable set of values.
+type SecurityEventType string
+
+const (
+	// SecurityAuthnFailure indicates a failed authentication attempt, e.g. a bad
+	// password or an expired token.
+	SecurityAuthnFailure SecurityEventType = "authn_failure"
+	// SecurityAuthzDenial indicates that an authenticated actor was denied access.
+	SecurityAuthzDenial SecurityEventType = "authz_denial"
+	// SecurityInputValidation indicates rejected input that may be malicious, e.g.
+	// a path traversal attempt.
+	SecurityInputValidation SecurityEventType = "input_validation"
+)
+
+// SecurityLevels are the severities that each SecurityEventType is logged at.
+// Types that aren't listed are logged at LevelWarning.
+var SecurityLevels = map[SecurityEventType]Level{
+	SecurityAuthnFailure:    LevelWarning,
+	SecurityAuthzDenial:     LevelWarning,
+	SecurityInputValidation: LevelWarning,
+}
+
+// SecurityEvent describes a security-relevant occurrence. Each field is output
+// as a tag with a fixed name, prefixed with "security.", e.g. "security.type".
+type SecurityEvent struct {
+	// The kind of event. Required.
+	Type SecurityEventType
+	// Who triggered the event, e.g. a user ID, if known.
+	Actor string
+	// Where the event originated, e.g. the remote IP address.
+	Source string
+	// What was being accessed, e.g. a URL path or record ID.
+	Resource string
+	// Why the event occurred, e.g. "invalid password".
+	Reason string
+	// Optional details, output as additional tags.
+	Details map[string]interface{}
+}
+
+// Security logs a security event at the severity configured for its type in
+// SecurityLevels, along with any tags in the context.
+func (l *logger) Security(ctx context.Context, event SecurityEvent) error {
+	if event.Type == "" {
+		return errors.New("security event is missing the type")
+	}
+
+	fields := make(map[string]interface{}, len(event.Details)+5)
+	for name, value := range event.Details {
+		fields[name] = value
+	}
+	fields["security.type"] = string(event.Type)
+	for name, value := range map[string]string{
+		"security.actor":    event.Actor,
+		"security.source":   event.Source,
+		"security.resource": event.Resource,
+		"security.reason":   event.Reason,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+
+	severity, ok := SecurityLevels[event.Type]
+	if !ok {
+		severity = LevelWarning
+	}
+	_, err := l.emit(ctx, 2, severity, "security event", Fields(fields))
+	return err
+}
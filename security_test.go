@@ -0,0 +1,63 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var securityTestCases = []struct {
+	Name           string
+	Event          SecurityEvent
+	ExpectedStderr string
+	ExpectedErr    string
+}{
+	{
+		Name: "authn-failure",
+		Event: SecurityEvent{
+			Type:     SecurityAuthnFailure,
+			Actor:    "bob",
+			Source:   "10.0.0.1",
+			Resource: "/login",
+			Reason:   "invalid password",
+			Details:  map[string]interface{}{"attempt": 3},
+		},
+		ExpectedStderr: "WARN loggy.TestLogger_Security.func1 [attempt:3, security.actor:bob, security.reason:invalid password, security.resource:/login, security.source:10.0.0.1, security.type:authn_failure] security event\n",
+	},
+	{
+		Name: "optional-fields-omitted",
+		Event: SecurityEvent{
+			Type:   SecurityInputValidation,
+			Source: "10.0.0.2",
+		},
+		ExpectedStderr: "WARN loggy.TestLogger_Security.func1 [security.source:10.0.0.2, security.type:input_validation] security event\n",
+	},
+	{
+		Name:        "missing-type",
+		Event:       SecurityEvent{Actor: "bob"},
+		ExpectedErr: "security event is missing the type",
+	},
+}
+
+func TestLogger_Security(t *testing.T) {
+	for _, testCase := range securityTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			stderr := bytes.NewBuffer([]byte{})
+			options := Options{
+				Err:               stderr,
+				Threshold:         LevelWarning,
+				DisableTimestamps: true,
+			}
+			l, ctx := New(context.Background(), options)
+
+			err := l.Security(ctx, testCase.Event)
+			if testCase.ExpectedErr != "" {
+				assert.EqualError(t, err, testCase.ExpectedErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, testCase.ExpectedStderr, stderr.String())
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package loggy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var _ HookSink = &Sentry{}
+
+// DefaultSentryQueueSize is the queue size used when SentryOptions.QueueSize is
+// zero.
+const DefaultSentryQueueSize = 100
+
+// SentryLevels maps each level to the level of the Sentry events captured for
+// it.
+var SentryLevels = map[Level]string{
+	LevelStd:      "info",
+	LevelCritical: "fatal",
+	LevelError:    "error",
+	LevelWarning:  "warning",
+	LevelInfo:     "info",
+	LevelDebug:    "debug",
+	LevelTrace:    "debug",
+}
+
+// SentryOptions configures a Sentry.
+type SentryOptions struct {
+	// The project's Data Source Name, e.g.
+	// "https://public@o0.ingest.sentry.io/1234".
+	DSN string
+	// The least severe level that is captured, e.g. LevelWarning captures
+	// Critical, Error, and Warning entries. If zero, LevelError is used.
+	Threshold Level
+	// Optionally set as the events' environment and release, e.g. "production"
+	// and "app@1.2.3".
+	Environment string
+	Release     string
+	// The maximum number of events waiting to be sent. Events captured while the
+	// queue is full are dropped. If zero, DefaultSentryQueueSize is used.
+	QueueSize int
+	// The client events are sent with. If nil, a client with a 10 second timeout
+	// is used.
+	Client *http.Client
+	// How events are retried until Sentry acknowledges them. By default, they're
+	// sent one at a time, and dropped if the first attempt fails.
+	Delivery Delivery
+}
+
+// Sentry captures entries as Sentry events, sending them in the background,
+// without depending on the Sentry SDK. Register Capture as a hook of the
+// logger:
+//
+//	s, err := loggy.NewSentry(loggy.SentryOptions{DSN: dsn, Environment: "production"})
+//	logger.AddHook(nil, s.Capture)
+//	defer s.Close()
+//
+// Each event's message is the entry's message, with its prefix. Its tags are
+// the entry's tags, with values encoded as text and truncated to Sentry's limit
+// of 200 characters, along with the error code, if any, as "code". The entry's
+// stack trace is attached to the event, so set Options.StacktraceLevel to at
+// least the Threshold to see where entries were logged. Events that can't be
+// sent are dropped, and counted by Dropped, rather than failing the logging
+// call. It is safe for concurrent use.
+type Sentry struct {
+	options  SentryOptions
+	endpoint string
+	auth     string
+	poster   *poster
+}
+
+// NewSentry parses the DSN, then starts the goroutine that sends events.
+func NewSentry(options SentryOptions) (*Sentry, error) {
+	dsn, err := url.Parse(options.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Sentry DSN: %w", err)
+	}
+	key := dsn.User.Username()
+	slash := strings.LastIndex(dsn.Path, "/")
+	project := dsn.Path[slash+1:]
+	if key == "" || project == "" || dsn.Host == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: expected a key, host, and project ID")
+	}
+
+	if options.Threshold == LevelStd {
+		options.Threshold = LevelError
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultSentryQueueSize
+	}
+	return &Sentry{
+		options:  options,
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", dsn.Scheme, dsn.Host, dsn.Path[:slash], project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=loggy/1.0, sentry_key=%s", key),
+		poster:   newPoster(options.Client, options.QueueSize, options.Delivery),
+	}, nil
+}
+
+// Capture queues an event for the entry, if its level is at least as severe as
+// the Threshold. Std entries are never captured. It matches the signature of
+// AddHook, and always returns nil.
+func (s *Sentry) Capture(entry Entry) error {
+	if entry.Level == LevelStd || entry.Level > s.options.Threshold {
+		return nil
+	}
+	envelope, err := s.envelope(entry)
+	if err != nil {
+		s.poster.drop()
+		return nil
+	}
+	request, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		s.poster.drop()
+		return nil
+	}
+	request.Header.Set("Content-Type", "application/x-sentry-envelope")
+	request.Header.Set("X-Sentry-Auth", s.auth)
+	s.poster.post(request)
+	return nil
+}
+
+// Flush waits until every event captured so far has been acknowledged, or
+// dropped.
+func (s *Sentry) Flush() error {
+	s.poster.flush()
+	return nil
+}
+
+// Close sends every queued event, then stops the goroutine sending them.
+// Events captured afterwards are dropped. Calling Close more than once has no
+// effect.
+func (s *Sentry) Close() error {
+	s.poster.close()
+	return nil
+}
+
+// Dropped returns the number of events that were dropped, because the queue was
+// full, or they weren't acknowledged after every retry.
+func (s *Sentry) Dropped() int64 {
+	return atomic.LoadInt64(&s.poster.dropped)
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Message     sentryMessage     `json:"message"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Threads     *sentryThreads    `json:"threads,omitempty"`
+}
+
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type sentryThreads struct {
+	Values []sentryThread `json:"values"`
+}
+
+type sentryThread struct {
+	Current    bool             `json:"current"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"abs_path,omitempty"`
+	Line     int    `json:"lineno,omitempty"`
+}
+
+// envelope encodes an event for the entry as a Sentry envelope.
+func (s *Sentry) envelope(entry Entry) ([]byte, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	at := entry.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id[:]),
+		Timestamp:   at.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       SentryLevels[entry.Level],
+		Logger:      entry.Logger,
+		Transaction: entry.Caller,
+		Environment: s.options.Environment,
+		Release:     s.options.Release,
+		Message:     sentryMessage{Formatted: entry.Message},
+	}
+	if entry.Prefix != "" {
+		event.Message.Formatted = entry.Prefix + " " + entry.Message
+	}
+
+	tags := entry.AllTags()
+	if len(tags) > 0 || entry.Code != "" {
+		event.Tags = make(map[string]string, len(tags)+1)
+		tag := func(name string, value interface{}) {
+			text := fmt.Sprint(textValue(value))
+			if runes := []rune(text); len(runes) > 200 {
+				text = string(runes[:200])
+			}
+			event.Tags[name] = text
+		}
+		for name, value := range tags {
+			if err, ok := value.(error); ok {
+				for key, field := range ErrorFields(name, err, false) {
+					tag(key, field)
+				}
+				continue
+			}
+			tag(name, value)
+		}
+		if entry.Code != "" {
+			tag("code", entry.Code)
+		}
+	}
+	if frames := sentryFrames(entry.Stack); len(frames) > 0 {
+		event.Threads = &sentryThreads{Values: []sentryThread{{
+			Current:    true,
+			Stacktrace: sentryStacktrace{Frames: frames},
+		}}}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]string{"event_id": event.EventID})
+	if err != nil {
+		return nil, err
+	}
+	item, err := json.Marshal(map[string]interface{}{"type": "event", "length": len(payload)})
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := make([]byte, 0, len(header)+len(item)+len(payload)+3)
+	envelope = append(append(envelope, header...), '\n')
+	envelope = append(append(envelope, item...), '\n')
+	envelope = append(append(envelope, payload...), '\n')
+	return envelope, nil
+}
+
+// sentryFrames parses a stack trace, as formatted for Entry.Stack, into
+// frames, which Sentry expects to be ordered from the outermost call inwards.
+func sentryFrames(stack string) []sentryFrame {
+	if stack == "" {
+		return nil
+	}
+	lines := strings.Split(stack, "\n")
+	frames := make([]sentryFrame, 0, len(lines)/2)
+	for i := 0; i < len(lines); i++ {
+		frame := sentryFrame{Function: lines[i]}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+			i++
+			location := strings.TrimPrefix(lines[i], "\t")
+			if colon := strings.LastIndex(location, ":"); colon > 0 {
+				frame.Filename = location[:colon]
+				frame.Line, _ = strconv.Atoi(location[colon+1:])
+			}
+		}
+		frames = append([]sentryFrame{frame}, frames...)
+	}
+	return frames
+}
@@ -0,0 +1,115 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// sentryServer records the events posted to it.
+type sentryServer struct {
+	*httptest.Server
+	mux    sync.Mutex
+	auth   []string
+	events []map[string]interface{}
+}
+
+func newSentryServer(t *testing.T, status int) *sentryServer {
+	s := &sentryServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "/prefix/api/42/envelope/", r.URL.Path)
+		lines := bytes.Split(bytes.TrimSuffix(body, []byte{'\n'}), []byte{'\n'})
+		assert.Len(t, lines, 3)
+
+		var event map[string]interface{}
+		assert.Nil(t, json.Unmarshal(lines[2], &event))
+		s.mux.Lock()
+		s.auth = append(s.auth, r.Header.Get("X-Sentry-Auth"))
+		s.events = append(s.events, event)
+		s.mux.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *sentryServer) dsn() string {
+	return strings.Replace(s.URL, "://", "://public@", 1) + "/prefix/42"
+}
+
+func TestSentry(t *testing.T) {
+	server := newSentryServer(t, http.StatusOK)
+	sentry, err := NewSentry(SentryOptions{DSN: server.dsn(), Environment: "test"})
+	assert.Nil(t, err)
+
+	l, ctx := New(context.Background(), Options{
+		Out:             bytes.NewBuffer([]byte{}),
+		Err:             bytes.NewBuffer([]byte{}),
+		Threshold:       LevelInfo,
+		StacktraceLevel: LevelError,
+	})
+	l.AddHook(nil, sentry.Capture)
+	_, ctx = l.AddTag(ctx, "user", "bob")
+
+	assert.Nil(t, l.Warning(ctx, "ignored"))
+	assert.Nil(t, l.Log(ctx, LevelError, Code("E4321"), "oops"))
+	assert.Nil(t, sentry.Flush())
+
+	assert.Len(t, server.events, 1)
+	assert.Equal(t, []string{"Sentry sentry_version=7, sentry_client=loggy/1.0, sentry_key=public"}, server.auth)
+	event := server.events[0]
+	assert.Equal(t, "error", event["level"])
+	assert.Equal(t, "test", event["environment"])
+	assert.Equal(t, map[string]interface{}{"formatted": "oops"}, event["message"])
+	assert.Equal(t, map[string]interface{}{"user": "bob", "code": "E4321"}, event["tags"])
+	assert.Equal(t, "loggy.TestSentry", event["transaction"])
+	assert.Len(t, event["event_id"], 32)
+
+	frames := event["threads"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})["stacktrace"].(map[string]interface{})["frames"].([]interface{})
+	innermost := frames[len(frames)-1].(map[string]interface{})
+	assert.Equal(t, "github.com/foresthoffman/loggy.TestSentry", innermost["function"])
+	assert.True(t, strings.HasSuffix(innermost["abs_path"].(string), "sentry_test.go"))
+	assert.NotZero(t, innermost["lineno"])
+
+	assert.Nil(t, sentry.Close())
+	assert.Nil(t, sentry.Close())
+	assert.Nil(t, sentry.Flush())
+	assert.Nil(t, sentry.Capture(Entry{Level: LevelCritical}))
+	assert.Equal(t, int64(1), sentry.Dropped())
+}
+
+func TestSentry_Failures(t *testing.T) {
+	server := newSentryServer(t, http.StatusTooManyRequests)
+	sentry, err := NewSentry(SentryOptions{DSN: server.dsn(), Threshold: LevelWarning})
+	assert.Nil(t, err)
+	defer sentry.Close()
+
+	assert.Nil(t, sentry.Capture(Entry{Level: LevelWarning, Message: "rate limited"}))
+	assert.Nil(t, sentry.Capture(Entry{Level: LevelInfo, Message: "ignored"}))
+	assert.Nil(t, sentry.Flush())
+	assert.Len(t, server.events, 1)
+	assert.Equal(t, int64(1), sentry.Dropped())
+
+	for _, dsn := range []string{"", "https://o0.ingest.sentry.io/42", "https://public@o0.ingest.sentry.io/", "://"} {
+		_, err := NewSentry(SentryOptions{DSN: dsn})
+		assert.NotNil(t, err, dsn)
+	}
+}
+
+func TestSentryFrames(t *testing.T) {
+	frames := sentryFrames("main.inner\n\t/src/main.go:10\nmain.main\n\t/src/main.go:3")
+	assert.Equal(t, []sentryFrame{
+		{Function: "main.main", Filename: "/src/main.go", Line: 3},
+		{Function: "main.inner", Filename: "/src/main.go", Line: 10},
+	}, frames)
+	assert.Nil(t, sentryFrames(""))
+}
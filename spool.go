@@ -0,0 +1,294 @@
+package loggy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrSpoolFull is returned when data can't be delivered or spooled, because the
+// spool has reached its maximum size.
+var ErrSpoolFull = errors.New("spool is full")
+
+const (
+	// spoolRetryDelay is the delay before replaying the spool after a failed
+	// attempt, doubling with each failure, up to spoolMaxRetryDelay.
+	spoolRetryDelay    = time.Second
+	spoolMaxRetryDelay = time.Minute
+)
+
+var _ io.Writer = &SpoolWriter{}
+
+// SpoolWriter protects against an unreliable destination, such as a network
+// connection. When a write to the destination fails, the data is appended to a
+// spool file instead. Spooled data is replayed, in order, before any new data
+// once the destination accepts writes again. While anything is spooled, new
+// data is spooled behind it, and replays are retried with a backoff, rather
+// than on every write. Each spooled write is replayed as a write of its own, so
+// datagram destinations receive the same messages they would have. It is safe
+// for concurrent use.
+type SpoolWriter struct {
+	out     io.Writer
+	path    string
+	maxSize int64
+	mux     sync.Mutex
+	// The number of bytes waiting in the spool file, excluding the records'
+	// headers.
+	spooled int64
+	// Spooled entries older than this are dropped when replayed, if > 0.
+	ttl time.Duration
+	// The number of entries dropped for exceeding the TTL.
+	expired int64
+	// The delay before the next attempt to replay the spool, and when it's due.
+	delay      time.Duration
+	nextReplay time.Time
+	now        func() time.Time
+}
+
+// NewSpoolWriter creates a SpoolWriter for out, spooling to the file at path,
+// which is created when needed. Any data left in the spool by a previous run is
+// replayed before new data. A maxSize <= 0 allows the spool to grow without
+// limit.
+func NewSpoolWriter(out io.Writer, path string, maxSize int64) *SpoolWriter {
+	w := &SpoolWriter{
+		out:     out,
+		path:    path,
+		maxSize: maxSize,
+		delay:   spoolRetryDelay,
+		now:     time.Now,
+	}
+	if f, err := os.Open(path); err == nil {
+		reader := bufio.NewReader(f)
+		for {
+			record, err := readSpoolRecord(reader)
+			if err != nil {
+				break
+			}
+			w.spooled += int64(len(record.data))
+		}
+		_ = f.Close()
+	}
+	return w
+}
+
+// Write delivers p to the destination, or spools it if the destination is
+// unavailable. Data that is spooled counts as written.
+func (w *SpoolWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.spooled > 0 {
+		if w.now().Before(w.nextReplay) {
+			return w.spool(p)
+		}
+		if err := w.replay(); err != nil {
+			// Keep the spooled data in order, ahead of p.
+			return w.spool(p)
+		}
+	}
+
+	n, err := w.out.Write(p)
+	if err != nil {
+		if _, spoolErr := w.spool(p[n:]); spoolErr != nil {
+			return n, spoolErr
+		}
+		return len(p), nil
+	}
+	return n, nil
+}
+
+// Flush attempts to deliver any spooled data to the destination, regardless of
+// the delay before the next attempt.
+func (w *SpoolWriter) Flush() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.spooled == 0 {
+		return nil
+	}
+	return w.replay()
+}
+
+// SetTTL causes spooled entries older than ttl to be dropped when the spool is
+// replayed, rather than delivered long after they were logged, e.g. when a
+// destination comes back after an outage. The age of an entry is the time since
+// it was written to the SpoolWriter, which is recorded in the spool, so it
+// works with any encoder. A ttl <= 0 keeps every entry, which is the default.
+func (w *SpoolWriter) SetTTL(ttl time.Duration) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.ttl = ttl
+}
+
+// Expired returns the number of spooled entries that were dropped for exceeding
+// the TTL.
+func (w *SpoolWriter) Expired() int64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.expired
+}
+
+// Spooled returns the number of bytes waiting in the spool.
+func (w *SpoolWriter) Spooled() int64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.spooled
+}
+
+// spool appends p to the spool file, as a record of its own.
+func (w *SpoolWriter) spool(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if w.maxSize > 0 && w.spooled+int64(len(p)) > w.maxSize {
+		return 0, ErrSpoolFull
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := writeSpoolRecord(f, spoolRecord{time: w.now(), data: p}); err != nil {
+		return 0, err
+	}
+	w.spooled += int64(len(p))
+	return len(p), nil
+}
+
+// replay writes the spooled records to the destination, one at a time,
+// keeping whatever couldn't be delivered in the spool. After a failure, the
+// next replay by Write is delayed, backing off with each failure.
+func (w *SpoolWriter) replay() error {
+	err := w.replayRecords()
+	if err != nil {
+		w.nextReplay = w.now().Add(w.delay)
+		if w.delay *= 2; w.delay > spoolMaxRetryDelay {
+			w.delay = spoolMaxRetryDelay
+		}
+		return err
+	}
+	w.delay = spoolRetryDelay
+	w.nextReplay = time.Time{}
+	return nil
+}
+
+func (w *SpoolWriter) replayRecords() error {
+	f, err := os.Open(w.path)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(f)
+
+	var writeErr error
+	var remainder *spoolRecord
+	for {
+		record, err := readSpoolRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+		if w.ttl > 0 && record.time.Before(w.now().Add(-w.ttl)) {
+			w.expired++
+			w.spooled -= int64(len(record.data))
+			continue
+		}
+
+		// The record is held in memory while it's written.
+		memory.claim(len(record.data))
+		n, err := w.out.Write(record.data)
+		memory.release(len(record.data))
+		w.spooled -= int64(n)
+		if err == nil && n < len(record.data) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			record.data = record.data[n:]
+			remainder = &record
+			writeErr = err
+			break
+		}
+	}
+
+	if remainder == nil {
+		_ = f.Close()
+		w.spooled = 0
+		return os.Remove(w.path)
+	}
+	// Keep the undelivered records, starting with the rest of the one that
+	// failed.
+	err = w.rewrite(*remainder, reader)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+	return writeErr
+}
+
+// rewrite replaces the spool with the first record, followed by the rest of the
+// records left in the reader.
+func (w *SpoolWriter) rewrite(first spoolRecord, rest io.Reader) error {
+	tmp := w.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := writeSpoolRecord(f, first); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := io.Copy(f, rest); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
+
+// spoolRecord is a single spooled write. In the spool file, each record is a
+// header line, holding the time it was spooled as Unix nanoseconds and the
+// size of the data, followed by the data itself.
+type spoolRecord struct {
+	time time.Time
+	data []byte
+}
+
+func writeSpoolRecord(w io.Writer, record spoolRecord) error {
+	buf := make([]byte, 0, 32+len(record.data))
+	buf = append(buf, fmt.Sprintf("%d %d\n", record.time.UnixNano(), len(record.data))...)
+	_, err := w.Write(append(buf, record.data...))
+	return err
+}
+
+func readSpoolRecord(r *bufio.Reader) (spoolRecord, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && header == "" {
+			return spoolRecord{}, io.EOF
+		}
+		return spoolRecord{}, fmt.Errorf("corrupt spool record: %w", err)
+	}
+	var nanos int64
+	var size int
+	if _, err := fmt.Sscanf(header, "%d %d\n", &nanos, &size); err != nil || size < 0 {
+		return spoolRecord{}, fmt.Errorf("corrupt spool record header %q", header)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return spoolRecord{}, fmt.Errorf("corrupt spool record: %w", err)
+	}
+	return spoolRecord{time: time.Unix(0, nanos), data: data}, nil
+}
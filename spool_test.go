@@ -0,0 +1,168 @@
+package loggy
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// flakyWriter fails every write while down is true.
+type flakyWriter struct {
+	buf    bytes.Buffer
+	down   bool
+	writes int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.down {
+		return 0, errors.New("connection refused")
+	}
+	return w.buf.Write(p)
+}
+
+func TestSpoolWriter(t *testing.T) {
+	out := &flakyWriter{}
+	path := filepath.Join(t.TempDir(), "spool.log")
+	w := NewSpoolWriter(out, path, 0)
+	now := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	w.now = func() time.Time {
+		return now
+	}
+
+	write := func(s string) {
+		n, err := w.Write([]byte(s))
+		assert.Nil(t, err)
+		assert.Equal(t, len(s), n)
+	}
+
+	write("one\n")
+	out.down = true
+	write("two\n")
+	write("three\n")
+	assert.Equal(t, int64(10), w.Spooled())
+	assert.Equal(t, "one\n", out.buf.String())
+
+	// While waiting to retry, writes are spooled without trying the destination.
+	writes := out.writes
+	write("four\n")
+	write("five\n")
+	assert.Equal(t, writes, out.writes)
+
+	out.down = false
+	write("six\n")
+	assert.Equal(t, int64(24), w.Spooled())
+	assert.Equal(t, "one\n", out.buf.String())
+
+	now = now.Add(spoolRetryDelay)
+	write("seven\n")
+	assert.Equal(t, int64(0), w.Spooled())
+	assert.Equal(t, "one\ntwo\nthree\nfour\nfive\nsix\nseven\n", out.buf.String())
+	assert.NoFileExists(t, path)
+}
+
+func TestSpoolWriter_Backoff(t *testing.T) {
+	out := &flakyWriter{down: true}
+	path := filepath.Join(t.TempDir(), "spool.log")
+	w := NewSpoolWriter(out, path, 0)
+	now := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	w.now = func() time.Time {
+		return now
+	}
+
+	_, _ = w.Write([]byte("one\n"))
+	for i, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		writes := out.writes
+		_, _ = w.Write([]byte("again\n"))
+		assert.Equal(t, writes+1, out.writes, "attempt %d", i)
+		assert.Equal(t, now.Add(delay), w.nextReplay, "attempt %d", i)
+		now = now.Add(delay)
+	}
+}
+
+// recordWriter records each write separately, like a datagram socket.
+type recordWriter struct {
+	writes []string
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestSpoolWriter_Records(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool.log")
+	down := NewSpoolWriter(&flakyWriter{down: true}, path, 0)
+	_, _ = down.Write([]byte("one\n"))
+	_, _ = down.Write([]byte("two\ngoroutine 1 [running]:\n"))
+	_, _ = down.Write([]byte("three"))
+
+	// Each spooled write is replayed as a single write.
+	out := &recordWriter{}
+	w := NewSpoolWriter(out, path, 0)
+	assert.Equal(t, int64(36), w.Spooled())
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []string{"one\n", "two\ngoroutine 1 [running]:\n", "three"}, out.writes)
+}
+
+func TestSpoolWriter_Restart(t *testing.T) {
+	out := &flakyWriter{down: true}
+	path := filepath.Join(t.TempDir(), "spool.log")
+	w := NewSpoolWriter(out, path, 0)
+	_, err := w.Write([]byte("before restart\n"))
+	assert.Nil(t, err)
+
+	out.down = false
+	w = NewSpoolWriter(out, path, 0)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, "before restart\n", out.buf.String())
+}
+
+func TestSpoolWriter_Full(t *testing.T) {
+	out := &flakyWriter{down: true}
+	path := filepath.Join(t.TempDir(), "spool.log")
+	w := NewSpoolWriter(out, path, 8)
+
+	_, err := w.Write([]byte("fits\n"))
+	assert.Nil(t, err)
+	n, err := w.Write([]byte("too big\n"))
+	assert.Equal(t, ErrSpoolFull, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, int64(5), w.Spooled())
+}
+
+func TestSpoolWriter_TTL(t *testing.T) {
+	out := &flakyWriter{down: true}
+	path := filepath.Join(t.TempDir(), "spool.log")
+	w := NewSpoolWriter(out, path, 0)
+	now := time.Date(2006, time.January, 2, 15, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		return now
+	}
+	w.SetTTL(5 * time.Minute)
+
+	write := func(s string) {
+		_, err := w.Write([]byte(s))
+		assert.Nil(t, err)
+	}
+	// Entries expire by when they were spooled, whatever their encoding.
+	write(`{"level":"DEBUG","message":"stale"}` + "\n")
+	now = now.Add(time.Minute)
+	write("CRIT main.run stale\ngoroutine 1 [running]:\n")
+	now = now.Add(5 * time.Minute)
+	write(`{"level":"INFO","message":"fresh"}` + "\n")
+	write("untimed\n")
+	now = now.Add(4 * time.Minute)
+
+	assert.NotNil(t, w.Flush())
+	assert.Equal(t, int64(2), w.Expired())
+	assert.Equal(t, int64(len(`{"level":"INFO","message":"fresh"}`+"\nuntimed\n")), w.Spooled())
+
+	out.down = false
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, int64(2), w.Expired())
+	assert.Equal(t, `{"level":"INFO","message":"fresh"}`+"\nuntimed\n", out.buf.String())
+}
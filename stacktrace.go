@@ -0,0 +1,26 @@
+package loggy
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// stacktrace formats the calling goroutine's stack, starting skip frames above
+// the caller of stacktrace, with one function per line followed by its indented
+// file and line number.
+func stacktrace(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,46 @@
+package loggy
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// logStats counts the entries handled by a logger, and every logger derived
+// from it, for the summary logged at shutdown. See Options.ShutdownSummary.
+type logStats struct {
+	started time.Time
+	// The number of entries written at each level, including those that failed.
+	levels [LevelTrace + 1]int64
+	// The number of entries filtered out, e.g. by the threshold, and the number
+	// lost, e.g. to sampling or a full queue.
+	filtered    int64
+	dropped     int64
+	writeErrors int64
+}
+
+func (s *logStats) written(level Level) {
+	atomic.AddInt64(&s.levels[level], 1)
+}
+
+// summarize logs the statistics as a single standard message, bypassing all
+// filtering.
+func (l *logger) summarize(ctx context.Context) error {
+	options := l.currentOptions()
+	stats := l.root().stats
+
+	uptime := options.TimestampFunc().Sub(stats.started)
+	fields := map[string]interface{}{
+		"summary.uptime":       uptime,
+		"summary.filtered":     atomic.LoadInt64(&stats.filtered),
+		"summary.dropped":      atomic.LoadInt64(&stats.dropped),
+		"summary.write_errors": atomic.LoadInt64(&stats.writeErrors),
+	}
+	for level := range stats.levels {
+		fields["summary."+strings.ToLower(LevelNames[Level(level)])] = atomic.LoadInt64(&stats.levels[level])
+	}
+
+	_, err := l.emit(ctx, 2, LevelStd, "shutting down after %s", uptime, Bypass(), SkipCaller(), Fields(fields))
+	return err
+}
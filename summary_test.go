@@ -0,0 +1,65 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestManager_Close_ShutdownSummary(t *testing.T) {
+	now := loggyTestTime
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:               stdout,
+		Err:               failingWriter{},
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+		ShutdownSummary:   true,
+		Processors: []Processor{
+			func(ctx context.Context, entry *Entry) bool {
+				return entry.Message != "secret"
+			},
+		},
+		TimestampFunc: func() time.Time {
+			return now
+		},
+	}
+	m, ctx := NewManager(context.Background(), options)
+	db := m.Logger("db")
+
+	assert.Nil(t, db.Info(ctx, "connected"))
+	assert.Nil(t, db.Debug(ctx, "filtered"))
+	assert.Nil(t, db.Info(ctx, "secret"))
+	assert.Nil(t, m.Logger("").Std(ctx, "ready"))
+	assert.NotNil(t, db.Error(ctx, "lost"))
+	now = now.Add(90 * time.Second)
+	stdout.Reset()
+
+	assert.Nil(t, m.Close())
+	assert.Equal(t, "OUT [summary.crit:0, summary.debug:0, summary.dropped:1, summary.error:1, summary.filtered:1, "+
+		"summary.info:1, summary.out:1, summary.trace:0, summary.uptime:1m30s, summary.warn:0, summary.write_errors:1] shutting down after 1m30s\n", stdout.String())
+}
+
+func TestManager_Close_NoShutdownSummary(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:       stdout,
+		Threshold: LevelInfo,
+	}
+	m, ctx := NewManager(context.Background(), options)
+	assert.Nil(t, m.Logger("db").Info(ctx, "connected"))
+	stdout.Reset()
+
+	assert.Nil(t, m.Close())
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,363 @@
+package loggy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// syslogTimeFormat is the RFC 5424 timestamp layout, which allows at most six
+// fractional digits.
+const syslogTimeFormat = "2006-01-02T15:04:05.999999Z07:00"
+
+// DefaultSyslogID is the SD-ID that SyslogEncoder puts tags under when
+// SyslogEncoder.StructuredDataID is empty. 32473 is the private enterprise
+// number reserved for documentation by RFC 5612.
+const DefaultSyslogID = "loggy@32473"
+
+// syslogTimeout limits how long SyslogWriter waits to connect, or for a send to
+// complete, so that a stalled server can't block logging forever.
+const syslogTimeout = 5 * time.Second
+
+// syslogSockets are the local syslog sockets tried, in order, by NewSyslogWriter.
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// ErrNoSyslog is returned by NewSyslogWriter when no local syslog socket could be
+// found.
+var ErrNoSyslog = errors.New("no local syslog socket found")
+
+// SyslogFacility is the syslog facility, identifying the kind of program that
+// logged a message.
+type SyslogFacility int
+
+// The facilities defined by RFC 5424. Applications usually log to FacilityUser,
+// FacilityDaemon, or one of the local facilities.
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+)
+
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// SyslogSeverities maps each level to its syslog severity. Std messages are
+// notices, and Trace messages are debug messages, as syslog has nothing finer.
+var SyslogSeverities = map[Level]int{
+	LevelStd:      5, // notice
+	LevelCritical: 2, // crit
+	LevelError:    3, // err
+	LevelWarning:  4, // warning
+	LevelInfo:     6, // info
+	LevelDebug:    7, // debug
+	LevelTrace:    7, // debug
+}
+
+// SyslogEncoder encodes each entry as an RFC 5424 syslog message, for rsyslog,
+// syslog-ng, and the like. For example:
+//
+//	<11>1 2006-01-02T15:04:05Z host app 1234 E1234 [loggy@32473 caller="main.main" user="bob"] oops
+//
+// The priority combines the Facility with the entry's severity, as mapped by
+// SyslogSeverities. The entry's error code is the MSGID, and the logger, caller,
+// and tags are parameters of a single structured data element, sorted by name.
+// Empty header fields are output as "-". Each message ends with a newline,
+// which SyslogWriter strips or frames as the transport requires.
+type SyslogEncoder struct {
+	// The facility, e.g. FacilityLocal0. The zero value, FacilityKern, is meant
+	// for the kernel, so set a facility, or use NewSyslogEncoder.
+	Facility SyslogFacility
+	// The HOSTNAME, APP-NAME, and PROCID header fields. See NewSyslogEncoder.
+	Hostname string
+	AppName  string
+	ProcID   string
+	// The SD-ID of the structured data element, defaulting to DefaultSyslogID.
+	StructuredDataID string
+}
+
+// NewSyslogEncoder creates a SyslogEncoder for the facility, identifying
+// messages by the machine's hostname, the program's name, and its process ID.
+func NewSyslogEncoder(facility SyslogFacility) SyslogEncoder {
+	hostname, _ := os.Hostname()
+	return SyslogEncoder{
+		Facility: facility,
+		Hostname: hostname,
+		AppName:  filepath.Base(os.Args[0]),
+		ProcID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Encode implements Encoder.
+func (e SyslogEncoder) Encode(entry Entry) ([]byte, error) {
+	severity, ok := SyslogSeverities[entry.Level]
+	if !ok {
+		severity = SyslogSeverities[LevelStd]
+	}
+
+	buf := make([]byte, 0, 192+len(entry.Message))
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(e.Facility)*8+int64(severity), 10)
+	buf = append(buf, ">1 "...)
+	if entry.Time.IsZero() {
+		buf = append(buf, '-')
+	} else {
+		buf = entry.Time.AppendFormat(buf, syslogTimeFormat)
+	}
+	buf = appendSyslogHeader(append(buf, ' '), e.Hostname, 255)
+	buf = appendSyslogHeader(append(buf, ' '), e.AppName, 48)
+	buf = appendSyslogHeader(append(buf, ' '), e.ProcID, 128)
+	buf = appendSyslogHeader(append(buf, ' '), entry.Code, 32)
+	buf = e.appendStructuredData(append(buf, ' '), entry)
+
+	if entry.Prefix != "" {
+		buf = append(append(buf, ' '), entry.Prefix...)
+	}
+	if entry.Message != "" {
+		buf = append(append(buf, ' '), entry.Message...)
+	}
+	if entry.Stack != "" {
+		buf = append(append(buf, '\n'), entry.Stack...)
+	}
+	return append(buf, '\n'), nil
+}
+
+// appendStructuredData appends the entry's logger, caller, and tags as a single
+// structured data element, or "-" if there are none.
+func (e SyslogEncoder) appendStructuredData(buf []byte, entry Entry) []byte {
+	params := make(map[string]string)
+	for name, value := range entry.AllTags() {
+		if err, ok := value.(error); ok {
+			for key, field := range ErrorFields(name, err, false) {
+				params[syslogName(key)] = fmt.Sprint(textValue(field))
+			}
+			continue
+		}
+		params[syslogName(name)] = fmt.Sprint(textValue(value))
+	}
+	if entry.Logger != "" {
+		params["logger"] = entry.Logger
+	}
+	if entry.Caller != "" {
+		params["caller"] = entry.Caller
+	}
+	if len(params) == 0 {
+		return append(buf, '-')
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	id := e.StructuredDataID
+	if id == "" {
+		id = DefaultSyslogID
+	}
+	buf = append(append(buf, '['), syslogName(id)...)
+	for _, name := range names {
+		buf = append(append(append(buf, ' '), name...), `="`...)
+		for i := 0; i < len(params[name]); i++ {
+			switch c := params[name][i]; c {
+			case '"', '\\', ']':
+				buf = append(buf, '\\', c)
+			default:
+				buf = append(buf, c)
+			}
+		}
+		buf = append(buf, '"')
+	}
+	return append(buf, ']')
+}
+
+// appendSyslogHeader appends a header field, replacing the characters that
+// can't appear in one with '_', and truncating it to max bytes. An empty field
+// is output as "-".
+func appendSyslogHeader(buf []byte, value string, max int) []byte {
+	if value == "" {
+		return append(buf, '-')
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c > ' ' && c < 0x7f {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '_')
+		}
+	}
+	return buf
+}
+
+// syslogName returns a valid SD-ID or PARAM-NAME for the name: at most 32
+// printable ASCII characters, excluding '=', ' ', ']', and '"'.
+func syslogName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	b := []byte(name)
+	for i, c := range b {
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+var _ io.WriteCloser = &SyslogWriter{}
+
+// SyslogWriter sends each write, such as a message encoded by SyslogEncoder,
+// to a syslog server as a single message. Over TCP, messages are framed by
+// octet counting, as described by RFC 6587, over Unix stream sockets they're
+// delimited by newlines, with any newlines within them, e.g. in a stack trace,
+// escaped as "#012", as rsyslog does, and otherwise each message is sent as a
+// datagram. If a send fails, or doesn't complete within five seconds, the
+// writer reconnects and tries once more. It is safe for concurrent use.
+type SyslogWriter struct {
+	network string
+	address string
+	mux     sync.Mutex
+	conn    net.Conn
+	closed  bool
+}
+
+// NewSyslogWriter connects to the syslog server at the address, where network
+// is "udp", "tcp", "unix", or "unixgram", as for net.Dial. An empty network
+// connects to the local syslog daemon's socket, e.g. /dev/log. For example:
+//
+//	w, err := loggy.NewSyslogWriter("udp", "logs.example.com:514")
+//	logger, ctx := loggy.New(ctx, loggy.Options{Out: w, Err: w, Encoder: loggy.NewSyslogEncoder(loggy.FacilityLocal0)})
+func NewSyslogWriter(network, address string) (*SyslogWriter, error) {
+	w := &SyslogWriter{
+		network: network,
+		address: address,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write sends p as a single message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.conn != nil {
+		if err := w.send(p); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if err := w.send(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// send writes the framed message to the connection, giving up after
+// syslogTimeout.
+func (w *SyslogWriter) send(p []byte) error {
+	if err := w.conn.SetWriteDeadline(time.Now().Add(syslogTimeout)); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(w.frame(p))
+	return err
+}
+
+// Close closes the connection. Calling Close more than once has no effect.
+func (w *SyslogWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+// frame prepares the message for the connection's transport: over TCP it's
+// prefixed with its length, over other streams its newlines are escaped and it
+// ends with a newline, and datagrams have no trailing newline. The message is
+// copied, rather than appended to p.
+func (w *SyslogWriter) frame(p []byte) []byte {
+	message := bytes.TrimSuffix(p, []byte{'\n'})
+	switch w.conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6":
+		framed := strconv.AppendInt(make([]byte, 0, len(message)+8), int64(len(message)), 10)
+		return append(append(framed, ' '), message...)
+	case "unix":
+		framed := make([]byte, 0, len(message)+1)
+		for _, c := range message {
+			if c == '\n' {
+				framed = append(framed, "#012"...)
+				continue
+			}
+			framed = append(framed, c)
+		}
+		return append(framed, '\n')
+	}
+	return message
+}
+
+// connect dials the server, or the first local socket that accepts a
+// connection.
+func (w *SyslogWriter) connect() error {
+	if w.network != "" {
+		conn, err := net.DialTimeout(w.network, w.address, syslogTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+	for _, path := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.conn = conn
+				return nil
+			}
+		}
+	}
+	return ErrNoSyslog
+}
@@ -0,0 +1,199 @@
+package loggy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var syslogEncoderTestCases = []struct {
+	Name     string
+	Encoder  SyslogEncoder
+	Entry    Entry
+	Expected string
+}{
+	{
+		Name:     "minimal",
+		Entry:    Entry{Level: LevelStd},
+		Expected: "<5>1 - - - - - -\n",
+	},
+	{
+		Name: "every-field",
+		Encoder: SyslogEncoder{
+			Facility: FacilityLocal0,
+			Hostname: "web-1",
+			AppName:  "app",
+			ProcID:   "1234",
+		},
+		Entry: Entry{
+			Time:    loggyTestTime,
+			Level:   LevelError,
+			Logger:  "app.db",
+			Caller:  "main.main",
+			Code:    "E4321",
+			Prefix:  "~~~",
+			Message: "oops",
+			Tags:    map[string]interface{}{"user": "bob", "attempt": 3},
+			Stack:   "main.main\n\tmain.go:1",
+		},
+		Expected: `<131>1 2006-01-02T15:04:05.123456Z web-1 app 1234 E4321 ` +
+			`[loggy@32473 attempt="3" caller="main.main" logger="app.db" user="bob"] ~~~ oops` + "\nmain.main\n\tmain.go:1\n",
+	},
+	{
+		Name:     "severities",
+		Encoder:  SyslogEncoder{Facility: FacilityDaemon},
+		Entry:    Entry{Level: LevelTrace, Message: "hi"},
+		Expected: "<31>1 - - - - - - hi\n",
+	},
+	{
+		Name:     "invalid-header-characters",
+		Encoder:  SyslogEncoder{Facility: FacilityUser, Hostname: "web 1", AppName: strings.Repeat("a", 50)},
+		Entry:    Entry{Level: LevelCritical, Code: "bad\ncode"},
+		Expected: "<10>1 - web_1 " + strings.Repeat("a", 48) + " - bad_code -\n",
+	},
+	{
+		Name:    "structured-data-escaping",
+		Encoder: SyslogEncoder{Facility: FacilityUser, StructuredDataID: "app@1"},
+		Entry: Entry{
+			Level: LevelWarning,
+			Tags: map[string]interface{}{
+				"a=b": `say "hi" [x] \ y`,
+				"err": errors.New("timed out"),
+			},
+		},
+		Expected: `<12>1 - - - - - [app@1 a_b="say \"hi\" [x\] \\ y" err="timed out" errType="*errors.errorString"]` + "\n",
+	},
+}
+
+func TestSyslogEncoder_Encode(t *testing.T) {
+	for _, testCase := range syslogEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			encoded, err := testCase.Encoder.Encode(testCase.Entry)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.Expected, string(encoded))
+		})
+	}
+}
+
+func TestNewSyslogEncoder(t *testing.T) {
+	e := NewSyslogEncoder(FacilityUser)
+	assert.Equal(t, FacilityUser, e.Facility)
+	assert.NotEmpty(t, e.AppName)
+	assert.NotEmpty(t, e.ProcID)
+}
+
+func TestSyslogWriter_UDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	w, err := NewSyslogWriter("udp", server.LocalAddr().String())
+	assert.Nil(t, err)
+	l, ctx := New(context.Background(), Options{
+		Out:       w,
+		Err:       w,
+		Threshold: LevelInfo,
+		Encoder:   SyslogEncoder{Facility: FacilityUser, AppName: "app"},
+	})
+	defer l.Close()
+	assert.Nil(t, l.Warning(ctx, "low disk"))
+
+	buf := make([]byte, 1024)
+	assert.Nil(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := server.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^<12>1 \S+ - app - - \[loggy@32473 caller="\S+"\] low disk$`, string(buf[:n]))
+}
+
+func TestSyslogWriter_TCP(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer server.Close()
+
+	w, err := NewSyslogWriter("tcp", server.Addr().String())
+	assert.Nil(t, err)
+	conn, err := server.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	_, err = w.Write([]byte("<14>1 - - - - - - first\n"))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("<14>1 - - - - - - second\nline\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	_, err = w.Write([]byte("late\n"))
+	assert.Equal(t, ErrClosed, err)
+
+	received := make([]byte, 0, 128)
+	reader := bufio.NewReader(conn)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			break
+		}
+		received = append(received, b)
+	}
+	assert.Equal(t, "23 <14>1 - - - - - - first29 <14>1 - - - - - - second\nline", string(received))
+}
+
+func TestSyslogWriter_Local(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	server, err := net.ListenPacket("unixgram", path)
+	assert.Nil(t, err)
+	defer server.Close()
+
+	sockets := syslogSockets
+	syslogSockets = []string{filepath.Join(filepath.Dir(path), "missing"), path}
+	defer func() { syslogSockets = sockets }()
+
+	w, err := NewSyslogWriter("", "")
+	assert.Nil(t, err)
+	defer w.Close()
+	_, err = w.Write([]byte("<14>1 - - - - - - hi\n"))
+	assert.Nil(t, err)
+
+	buf := make([]byte, 1024)
+	n, _, err := server.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "<14>1 - - - - - - hi", string(buf[:n]))
+
+	syslogSockets = nil
+	_, err = NewSyslogWriter("", "")
+	assert.Equal(t, ErrNoSyslog, err)
+}
+
+func TestSyslogWriter_UnixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	server, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	defer server.Close()
+
+	w, err := NewSyslogWriter("unix", path)
+	assert.Nil(t, err)
+	conn, err := server.Accept()
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// The spare capacity must not be written to.
+	message := make([]byte, 0, 64)
+	message = append(message, "<10>1 - - - - - - panic\nmain.main"...)
+	spare := message[:cap(message)]
+	_, err = w.Write(message)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("<14>1 - - - - - - second\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	received, err := ioutil.ReadAll(conn)
+	assert.Nil(t, err)
+	assert.Equal(t, "<10>1 - - - - - - panic#012main.main\n<14>1 - - - - - - second\n", string(received))
+	assert.Equal(t, make([]byte, cap(message)-len(message)), spare[len(message):])
+}
@@ -0,0 +1,48 @@
+package loggy
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// textValue returns the text representation that a value defines for itself,
+// preferring encoding.TextMarshaler over fmt.Stringer. If the value implements
+// neither, or both fail (by returning an error or panicking), the value is
+// returned unchanged so it can be formatted by the fmt package instead.
+func textValue(value interface{}) interface{} {
+	if marshaler, ok := value.(encoding.TextMarshaler); ok {
+		if text, ok := marshalText(marshaler); ok {
+			return text
+		}
+	}
+	if stringer, ok := value.(fmt.Stringer); ok {
+		if text, ok := stringText(stringer); ok {
+			return text
+		}
+	}
+	return value
+}
+
+func marshalText(marshaler encoding.TextMarshaler) (text string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	b, err := marshaler.MarshalText()
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func stringText(stringer fmt.Stringer) (text string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	return stringer.String(), true
+}
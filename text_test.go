@@ -0,0 +1,91 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type textMarshalerValue struct {
+	err error
+}
+
+func (v textMarshalerValue) MarshalText() ([]byte, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	return []byte("marshaled"), nil
+}
+
+func (v textMarshalerValue) String() string {
+	return "stringified"
+}
+
+type stringerValue struct{}
+
+func (v *stringerValue) String() string {
+	return "pointer-stringer"
+}
+
+type plainValue struct {
+	A int
+}
+
+var textValueTestCases = []struct {
+	Name     string
+	Value    interface{}
+	Expected interface{}
+}{
+	{
+		Name:     "plain",
+		Value:    plainValue{A: 1},
+		Expected: plainValue{A: 1},
+	},
+	{
+		Name:     "text-marshaler",
+		Value:    textMarshalerValue{},
+		Expected: "marshaled",
+	},
+	{
+		Name:     "text-marshaler-error",
+		Value:    textMarshalerValue{err: errors.New("oops")},
+		Expected: "stringified",
+	},
+	{
+		Name:     "stringer",
+		Value:    &stringerValue{},
+		Expected: "pointer-stringer",
+	},
+	{
+		Name:     "time",
+		Value:    time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		Expected: "2006-01-02T15:04:05Z",
+	},
+}
+
+func TestTextValue(t *testing.T) {
+	for _, testCase := range textValueTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, textValue(testCase.Value))
+		})
+	}
+}
+
+func TestLogger_Log_TextTags(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	options := Options{
+		Out:                 stdout,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "marshaler", textMarshalerValue{})
+	_, ctx = l.AddTag(ctx, "stringer", &stringerValue{})
+
+	assert.Nil(t, l.Info(ctx, "custom"))
+	assert.Equal(t, "INFO [marshaler:marshaled, stringer:pointer-stringer] custom\n", stdout.String())
+}
@@ -0,0 +1,37 @@
+package loggy
+
+import (
+	"strconv"
+	"time"
+)
+
+// TimestampEncoder converts the time a message was logged into the bytes that
+// are output. See Options.TimestampEncoder.
+type TimestampEncoder = func(t time.Time) []byte
+
+// EpochSecondsEncoder outputs timestamps as the number of seconds since the Unix
+// epoch, e.g. "1136214245".
+func EpochSecondsEncoder(t time.Time) []byte {
+	return strconv.AppendInt(nil, t.Unix(), 10)
+}
+
+// EpochMillisEncoder outputs timestamps as the number of milliseconds since the
+// Unix epoch, e.g. "1136214245000".
+func EpochMillisEncoder(t time.Time) []byte {
+	return strconv.AppendInt(nil, t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// RFC3339NanoEncoder outputs timestamps in the time.RFC3339Nano format, e.g.
+// "2006-01-02T15:04:05.999999999Z".
+func RFC3339NanoEncoder(t time.Time) []byte {
+	return t.AppendFormat(nil, time.RFC3339Nano)
+}
+
+// appendTimestamp appends the time to buf, using the configured encoder, or the
+// configured format if no encoder was provided.
+func appendTimestamp(buf []byte, options *Options, t time.Time) []byte {
+	if options.TimestampEncoder != nil {
+		return append(buf, options.TimestampEncoder(t)...)
+	}
+	return t.AppendFormat(buf, options.TimestampFormat)
+}
@@ -0,0 +1,59 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var loggyTestTime = time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+
+var timestampEncoderTestCases = []struct {
+	Name     string
+	Encoder  TimestampEncoder
+	Expected string
+}{
+	{
+		Name:     "format",
+		Encoder:  nil,
+		Expected: "2006-01-02T15:04:05Z",
+	},
+	{
+		Name:     "epoch-seconds",
+		Encoder:  EpochSecondsEncoder,
+		Expected: "1136214245",
+	},
+	{
+		Name:     "epoch-millis",
+		Encoder:  EpochMillisEncoder,
+		Expected: "1136214245123",
+	},
+	{
+		Name:     "rfc3339-nano",
+		Encoder:  RFC3339NanoEncoder,
+		Expected: "2006-01-02T15:04:05.123456789Z",
+	},
+}
+
+func TestOptions_TimestampEncoder(t *testing.T) {
+	for _, testCase := range timestampEncoderTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			stdout := bytes.NewBuffer([]byte{})
+			options := Options{
+				Out:                 stdout,
+				Threshold:           LevelInfo,
+				DisableFunctionName: true,
+				TimestampEncoder:    testCase.Encoder,
+				TimestampFunc: func() time.Time {
+					return loggyTestTime
+				},
+			}
+			l, ctx := New(context.Background(), options)
+
+			assert.Nil(t, l.Info(ctx, "tick"))
+			assert.Equal(t, testCase.Expected+" INFO tick\n", stdout.String())
+		})
+	}
+}
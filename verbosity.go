@@ -0,0 +1,88 @@
+package loggy
+
+import (
+	"context"
+	"flag"
+	"strconv"
+)
+
+// Verbosity counts the conventional -q, -v, and -vv command-line flags, for
+// adjusting a CLI tool's threshold. See BindVerbosity.
+type Verbosity struct {
+	// The number of times -q was given.
+	Quiet int
+	// The number of times -v was given, where -vv counts twice.
+	Verbose int
+}
+
+// BindVerbosity defines the -q, -v, and -vv flags on fs, or flag.CommandLine if
+// fs is nil, counting them in the returned Verbosity once fs is parsed. The -q
+// and -v flags may be repeated. For example:
+//
+//	verbosity := loggy.BindVerbosity(nil)
+//	flag.Parse()
+//	logger, ctx := verbosity.New(context.Background(), loggy.Options{Threshold: loggy.LevelWarning})
+func BindVerbosity(fs *flag.FlagSet) *Verbosity {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	v := &Verbosity{}
+	fs.Var(&countFlag{count: &v.Quiet, step: 1}, "q", "log less; repeat to log even less")
+	fs.Var(&countFlag{count: &v.Verbose, step: 1}, "v", "log more; repeat to log even more")
+	fs.Var(&countFlag{count: &v.Verbose, step: 2}, "vv", "log much more, as with -v -v")
+	return v
+}
+
+// Threshold adjusts the threshold by one level for each -v, showing more
+// messages, and back by one level for each -q, showing fewer. Adjusting past
+// LevelStd disables logging, and adjusting past LevelTrace stops at LevelTrace.
+// A disabled threshold is returned unchanged.
+func (v *Verbosity) Threshold(threshold Level) Level {
+	if threshold < 0 {
+		return threshold
+	}
+	threshold += Level(v.Verbose - v.Quiet)
+	if threshold < LevelStd {
+		return -1
+	}
+	if threshold > LevelTrace {
+		return LevelTrace
+	}
+	return threshold
+}
+
+// New creates a logger with the options' threshold adjusted by Threshold. Call
+// it after the flags have been parsed.
+func (v *Verbosity) New(ctx context.Context, options Options) (*logger, context.Context) {
+	options.Threshold = v.Threshold(options.Threshold)
+	return New(ctx, options)
+}
+
+// countFlag is a boolean flag that adds step to count each time it's set.
+type countFlag struct {
+	count *int
+	step  int
+}
+
+func (f *countFlag) String() string {
+	if f == nil || f.count == nil {
+		return "0"
+	}
+	return strconv.Itoa(*f.count)
+}
+
+func (f *countFlag) Set(value string) error {
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	if on {
+		*f.count += f.step
+	}
+	return nil
+}
+
+// IsBoolFlag allows the flag to be given without a value.
+func (f *countFlag) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,64 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+var verbosityTestCases = []struct {
+	Name      string
+	Args      []string
+	Threshold Level
+	Expected  Level
+}{
+	{Name: "none", Threshold: LevelWarning, Expected: LevelWarning},
+	{Name: "verbose", Args: []string{"-v"}, Threshold: LevelWarning, Expected: LevelInfo},
+	{Name: "very-verbose", Args: []string{"-vv"}, Threshold: LevelWarning, Expected: LevelDebug},
+	{Name: "repeated-verbose", Args: []string{"-v", "-v"}, Threshold: LevelWarning, Expected: LevelDebug},
+	{Name: "trace", Args: []string{"-vv", "-v"}, Threshold: LevelWarning, Expected: LevelTrace},
+	{Name: "past-trace", Args: []string{"-vv", "-vv"}, Threshold: LevelWarning, Expected: LevelTrace},
+	{Name: "quiet", Args: []string{"-q"}, Threshold: LevelWarning, Expected: LevelError},
+	{Name: "past-std", Args: []string{"-q", "-q", "-q", "-q"}, Threshold: LevelWarning, Expected: -1},
+	{Name: "both", Args: []string{"-q", "-vv"}, Threshold: LevelWarning, Expected: LevelInfo},
+	{Name: "explicit-false", Args: []string{"-v=false"}, Threshold: LevelWarning, Expected: LevelWarning},
+	{Name: "disabled", Args: []string{"-v"}, Threshold: -1, Expected: -1},
+}
+
+func TestBindVerbosity(t *testing.T) {
+	for _, testCase := range verbosityTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			verbosity := BindVerbosity(fs)
+			assert.Nil(t, fs.Parse(testCase.Args))
+			assert.Equal(t, testCase.Expected, verbosity.Threshold(testCase.Threshold))
+		})
+	}
+}
+
+func TestBindVerbosity_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	BindVerbosity(fs)
+	assert.NotNil(t, fs.Parse([]string{"-v=loud"}))
+}
+
+func TestVerbosity_New(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	verbosity := BindVerbosity(fs)
+	assert.Nil(t, fs.Parse([]string{"-v"}))
+
+	l, ctx := verbosity.New(context.Background(), Options{
+		Out:                 stdout,
+		Threshold:           LevelWarning,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+	})
+	assert.Nil(t, l.Info(ctx, "shown"))
+	assert.Nil(t, l.Debug(ctx, "hidden"))
+	assert.Equal(t, "INFO shown\n", stdout.String())
+}
@@ -0,0 +1,281 @@
+package loggy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// Payload templates for common chat services, for WebhookOptions.Template.
+const (
+	// SlackWebhookTemplate posts a Slack message, with the details as a context
+	// block.
+	SlackWebhookTemplate = `{"text":{{json .Title}},"blocks":[` +
+		`{"type":"section","text":{"type":"mrkdwn","text":{{json (printf "*%s*" .Title)}}}}` +
+		`{{if .Details}},{"type":"context","elements":[{"type":"mrkdwn","text":{{json .Details}}}]}{{end}}]}`
+	// DiscordWebhookTemplate posts a Discord embed, colored by level.
+	DiscordWebhookTemplate = `{"embeds":[{"title":{{json .Title}},"color":{{.Color}}` +
+		`{{if .Details}},"description":{{json .Details}}{{end}}}]}`
+	// TeamsWebhookTemplate posts a Microsoft Teams message card, colored by level.
+	TeamsWebhookTemplate = `{"@type":"MessageCard","@context":"https://schema.org/extensions",` +
+		`"themeColor":{{json (printf "%06X" .Color)}},"summary":{{json .Title}},"title":{{json .Title}}` +
+		`{{if .Details}},"text":{{json .Details}}{{end}}}`
+)
+
+var _ HookSink = &Webhook{}
+
+// WebhookColors are the colors of alerts for each level, as 0xRRGGBB, for
+// templates to use as WebhookMessage.Color.
+var WebhookColors = map[Level]int{
+	LevelStd:      0x439FE0,
+	LevelCritical: 0xD00000,
+	LevelError:    0xE8590C,
+	LevelWarning:  0xF2C744,
+	LevelInfo:     0x439FE0,
+	LevelDebug:    0x9E9E9E,
+	LevelTrace:    0x9E9E9E,
+}
+
+// WebhookMessage is the data that a webhook's payload template is executed
+// with.
+type WebhookMessage struct {
+	Entry Entry
+	// The name of the entry's level, as in LevelNames.
+	Level string
+	// A one-line summary: the level, the logger's name, and the message, with
+	// its prefix, e.g. "CRIT app.db: connection lost".
+	Title string
+	// The error code, caller, and tags, one "name: value" per line, sorted by
+	// name, followed by a note of any suppressed alerts.
+	Details string
+	// The color for the entry's level, from WebhookColors.
+	Color int
+	// The number of alerts suppressed by the rate limit since the last one sent.
+	Suppressed int64
+}
+
+// WebhookOptions configures a Webhook.
+type WebhookOptions struct {
+	// The URL to post alerts to, e.g. a Slack incoming webhook.
+	URL string
+	// The least severe level that is posted. If zero, LevelCritical is used.
+	Threshold Level
+	// The text/template that produces each payload from a WebhookMessage, e.g.
+	// DiscordWebhookTemplate. Its json function encodes a value as JSON, quoting
+	// strings. If empty, SlackWebhookTemplate is used.
+	Template string
+	// The rate limit: at most Burst alerts are posted per Interval, and any more
+	// are suppressed, then counted in the next alert posted. If zero, 5 alerts
+	// per minute are allowed.
+	Burst    int
+	Interval time.Duration
+	// The maximum number of alerts waiting to be posted. If zero, 100 is used.
+	QueueSize int
+	// The client alerts are posted with. If nil, a client with a 10 second
+	// timeout is used.
+	Client *http.Client
+	// How alerts are retried until the webhook acknowledges them. By default,
+	// they're posted one at a time, and dropped if the first attempt fails.
+	Delivery Delivery
+}
+
+// Webhook posts entries as alerts to a webhook, such as a Slack, Discord, or
+// Microsoft Teams channel, in the background. Register Capture as a hook of the
+// logger:
+//
+//	w, err := loggy.NewWebhook(loggy.WebhookOptions{URL: url, Template: loggy.DiscordWebhookTemplate})
+//	logger.AddHook(nil, w.Capture)
+//	defer w.Close()
+//
+// Alerts are rate limited, so that a crash loop doesn't flood the channel.
+// Alerts that can't be posted are dropped, and counted by Dropped, rather than
+// failing the logging call. It is safe for concurrent use.
+type Webhook struct {
+	options  WebhookOptions
+	template *template.Template
+	poster   *poster
+	// The rate limit's token bucket.
+	mux        sync.Mutex
+	tokens     float64
+	refilled   time.Time
+	suppressed int64
+	now        func() time.Time
+}
+
+// NewWebhook parses the template, then starts the goroutine that posts alerts.
+func NewWebhook(options WebhookOptions) (*Webhook, error) {
+	if options.Threshold == LevelStd {
+		options.Threshold = LevelCritical
+	}
+	if options.Template == "" {
+		options.Template = SlackWebhookTemplate
+	}
+	if options.Burst <= 0 {
+		options.Burst = 5
+	}
+	if options.Interval <= 0 {
+		options.Interval = time.Minute
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = 100
+	}
+	payload, err := template.New("webhook").Funcs(template.FuncMap{"json": webhookJSON}).Parse(options.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook template: %w", err)
+	}
+
+	w := &Webhook{
+		options:  options,
+		template: payload,
+		poster:   newPoster(options.Client, options.QueueSize, options.Delivery),
+		tokens:   float64(options.Burst),
+		now:      time.Now,
+	}
+	w.refilled = w.now()
+	return w, nil
+}
+
+// Capture posts an alert for the entry, if its level is at least as severe as
+// the Threshold, and the rate limit allows. Std entries are never posted. It
+// matches the signature of AddHook, and always returns nil.
+func (w *Webhook) Capture(entry Entry) error {
+	if entry.Level == LevelStd || entry.Level > w.options.Threshold {
+		return nil
+	}
+	suppressed, ok := w.allow()
+	if !ok {
+		return nil
+	}
+
+	message := webhookMessage(entry, suppressed)
+	var payload bytes.Buffer
+	if err := w.template.Execute(&payload, message); err != nil {
+		w.poster.drop()
+		return nil
+	}
+	request, err := http.NewRequest(http.MethodPost, w.options.URL, &payload)
+	if err != nil {
+		w.poster.drop()
+		return nil
+	}
+	request.Header.Set("Content-Type", "application/json")
+	w.poster.post(request)
+	return nil
+}
+
+// Flush waits until every alert captured so far has been acknowledged, or
+// dropped.
+func (w *Webhook) Flush() error {
+	w.poster.flush()
+	return nil
+}
+
+// Close posts every queued alert, then stops the goroutine posting them.
+// Alerts captured afterwards are dropped. Calling Close more than once has no
+// effect.
+func (w *Webhook) Close() error {
+	w.poster.close()
+	return nil
+}
+
+// Dropped returns the number of alerts that were dropped, because the queue was
+// full, or they weren't acknowledged after every retry. Alerts suppressed by the rate limit aren't
+// included.
+func (w *Webhook) Dropped() int64 {
+	return atomic.LoadInt64(&w.poster.dropped)
+}
+
+// Suppressed returns the number of alerts suppressed by the rate limit since the
+// last alert was posted.
+func (w *Webhook) Suppressed() int64 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.suppressed
+}
+
+// allow takes a token from the rate limit's bucket, returning the number of
+// alerts suppressed before it, or reports that the alert must be suppressed.
+func (w *Webhook) allow() (int64, bool) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	now := w.now()
+	w.tokens += float64(w.options.Burst) * float64(now.Sub(w.refilled)) / float64(w.options.Interval)
+	if w.tokens > float64(w.options.Burst) {
+		w.tokens = float64(w.options.Burst)
+	}
+	w.refilled = now
+	if w.tokens < 1 {
+		w.suppressed++
+		return 0, false
+	}
+	w.tokens--
+	suppressed := w.suppressed
+	w.suppressed = 0
+	return suppressed, true
+}
+
+// webhookMessage prepares the data for the payload template.
+func webhookMessage(entry Entry, suppressed int64) WebhookMessage {
+	message := entry.Message
+	if entry.Prefix != "" {
+		message = entry.Prefix + " " + message
+	}
+	title := LevelNames[entry.Level]
+	if entry.Logger != "" {
+		title += " " + entry.Logger
+	}
+	title += ": " + message
+
+	details := map[string]string{}
+	for name, value := range entry.AllTags() {
+		if err, ok := value.(error); ok {
+			for key, field := range ErrorFields(name, err, false) {
+				details[key] = fmt.Sprint(textValue(field))
+			}
+			continue
+		}
+		details[name] = fmt.Sprint(textValue(value))
+	}
+	if entry.Code != "" {
+		details["code"] = entry.Code
+	}
+	if entry.Caller != "" {
+		details["caller"] = entry.Caller
+	}
+	names := make([]string, 0, len(details))
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		lines = append(lines, name+": "+details[name])
+	}
+	if suppressed > 0 {
+		lines = append(lines, fmt.Sprintf("(%d more alerts were suppressed by the rate limit)", suppressed))
+	}
+
+	return WebhookMessage{
+		Entry:      entry,
+		Level:      LevelNames[entry.Level],
+		Title:      title,
+		Details:    strings.Join(lines, "\n"),
+		Color:      WebhookColors[entry.Level],
+		Suppressed: suppressed,
+	}
+}
+
+// webhookJSON encodes a value as JSON, for templates.
+func webhookJSON(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	return string(b), err
+}
@@ -0,0 +1,116 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookServer records the payloads posted to it.
+type webhookServer struct {
+	*httptest.Server
+	mux      sync.Mutex
+	payloads []string
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		s.mux.Lock()
+		s.payloads = append(s.payloads, string(body))
+		s.mux.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWebhook(t *testing.T) {
+	server := newWebhookServer(t)
+	webhook, err := NewWebhook(WebhookOptions{URL: server.URL, Threshold: LevelError})
+	assert.Nil(t, err)
+
+	l, ctx := New(context.Background(), Options{
+		Name:                "app",
+		Out:                 bytes.NewBuffer([]byte{}),
+		Err:                 bytes.NewBuffer([]byte{}),
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+	})
+	l.AddHook(nil, webhook.Capture)
+	_, ctx = l.AddTag(ctx, "user", "bob")
+
+	assert.Nil(t, l.Warning(ctx, "ignored"))
+	assert.Nil(t, l.Log(ctx, LevelCritical, Code("E4321"), `disk "full"`))
+	assert.Nil(t, webhook.Close())
+	assert.Nil(t, webhook.Close())
+
+	assert.Equal(t, []string{`{"text":"CRIT app: disk \"full\"","blocks":[` +
+		`{"type":"section","text":{"type":"mrkdwn","text":"*CRIT app: disk \"full\"*"}},` +
+		`{"type":"context","elements":[{"type":"mrkdwn","text":"code: E4321\nuser: bob"}]}]}`}, server.payloads)
+	assert.Equal(t, int64(0), webhook.Dropped())
+}
+
+func TestWebhook_Templates(t *testing.T) {
+	entry := Entry{Level: LevelCritical, Message: "down", Fields: map[string]interface{}{"err": errors.New("timed out")}}
+	for _, text := range []string{SlackWebhookTemplate, DiscordWebhookTemplate, TeamsWebhookTemplate} {
+		server := newWebhookServer(t)
+		webhook, err := NewWebhook(WebhookOptions{URL: server.URL, Template: text})
+		assert.Nil(t, err)
+		assert.Nil(t, webhook.Capture(entry))
+		assert.Nil(t, webhook.Capture(Entry{Level: LevelWarning, Message: "ignored"}))
+		assert.Nil(t, webhook.Flush())
+		assert.Len(t, server.payloads, 1)
+		assert.True(t, json.Valid([]byte(server.payloads[0])), server.payloads[0])
+		assert.Nil(t, webhook.Close())
+	}
+
+	server := newWebhookServer(t)
+	webhook, err := NewWebhook(WebhookOptions{URL: server.URL, Template: DiscordWebhookTemplate})
+	assert.Nil(t, err)
+	defer webhook.Close()
+	assert.Nil(t, webhook.Capture(Entry{Level: LevelCritical, Message: "down"}))
+	assert.Nil(t, webhook.Flush())
+	assert.Equal(t, []string{`{"embeds":[{"title":"CRIT: down","color":13631488}]}`}, server.payloads)
+
+	_, err = NewWebhook(WebhookOptions{URL: server.URL, Template: "{{"})
+	assert.NotNil(t, err)
+}
+
+func TestWebhook_RateLimit(t *testing.T) {
+	server := newWebhookServer(t)
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:      server.URL,
+		Template: `{{.Title}} {{.Suppressed}}`,
+		Burst:    2,
+		Interval: time.Minute,
+	})
+	assert.Nil(t, err)
+	defer webhook.Close()
+	now := webhook.refilled
+	webhook.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, webhook.Capture(Entry{Level: LevelCritical, Message: "crash"}))
+	}
+	assert.Equal(t, int64(3), webhook.Suppressed())
+
+	// Half the interval refills one token.
+	now = now.Add(30 * time.Second)
+	assert.Nil(t, webhook.Capture(Entry{Level: LevelCritical, Message: "crash"}))
+	assert.Nil(t, webhook.Capture(Entry{Level: LevelCritical, Message: "crash"}))
+	assert.Nil(t, webhook.Flush())
+
+	assert.Equal(t, []string{"CRIT: crash 0", "CRIT: crash 0", "CRIT: crash 3"}, server.payloads)
+	assert.Equal(t, int64(1), webhook.Suppressed())
+}
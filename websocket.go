@@ -0,0 +1,87 @@
+package loggy
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WebSocketCloseCodes names the close codes defined by RFC 6455 and the IANA
+// WebSocket registry.
+var WebSocketCloseCodes = map[int]string{
+	1000: "normal closure",
+	1001: "going away",
+	1002: "protocol error",
+	1003: "unsupported data",
+	1005: "no status received",
+	1006: "abnormal closure",
+	1007: "invalid payload data",
+	1008: "policy violation",
+	1009: "message too big",
+	1010: "mandatory extension",
+	1011: "internal error",
+	1012: "service restart",
+	1013: "try again later",
+	1014: "bad gateway",
+	1015: "TLS handshake",
+}
+
+// WebSocket logs the lifecycle of a single WebSocket connection. It doesn't
+// depend on any particular WebSocket package; call its methods from the
+// package's upgrade, pong, and close handlers.
+type WebSocket struct {
+	logger Logger
+	opened time.Time
+}
+
+// UpgradeWebSocket tags the context with the connection's ID, remote address,
+// and path, as "ws.id", "ws.remote", and "ws.path", then logs the upgrade. Use
+// the returned context for all logs about the connection, so they can be
+// correlated with each other and with any tags already in the context, such as
+// request IDs.
+func UpgradeWebSocket(ctx context.Context, l Logger, r *http.Request, id string) (*WebSocket, context.Context) {
+	_, ctx = l.AddTag(ctx, "ws.id", id)
+	_, ctx = l.AddTag(ctx, "ws.remote", r.RemoteAddr)
+	_, ctx = l.AddTag(ctx, "ws.path", r.URL.Path)
+
+	ws := &WebSocket{
+		logger: l,
+		opened: l.Options().TimestampFunc(),
+	}
+	fields := map[string]interface{}{}
+	if protocol := r.Header.Get("Sec-WebSocket-Protocol"); protocol != "" {
+		fields["ws.protocol"] = protocol
+	}
+	_ = l.Logf(ctx, LevelInfo, "websocket opened", Fields(fields))
+
+	return ws, ctx
+}
+
+// Pong logs the round trip latency of a ping, at LevelDebug.
+func (ws *WebSocket) Pong(ctx context.Context, latency time.Duration) error {
+	return ws.logger.Logf(ctx, LevelDebug, "websocket pong", Fields(map[string]interface{}{
+		"ws.latency": latency,
+	}))
+}
+
+// Close logs the close code, its name, and how long the connection was open.
+// Normal closures are logged at LevelInfo, anything else at LevelWarning.
+func (ws *WebSocket) Close(ctx context.Context, code int, reason string) error {
+	fields := map[string]interface{}{
+		"ws.code":     code,
+		"ws.duration": ws.logger.Options().TimestampFunc().Sub(ws.opened),
+	}
+	if name, ok := WebSocketCloseCodes[code]; ok {
+		fields["ws.code_name"] = name
+	}
+	if reason != "" {
+		fields["ws.reason"] = reason
+	}
+
+	severity := LevelWarning
+	switch code {
+	case 1000, 1001:
+		severity = LevelInfo
+	}
+	return ws.logger.Logf(ctx, severity, "websocket closed", Fields(fields))
+}
@@ -0,0 +1,49 @@
+package loggy
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocket(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	stderr := bytes.NewBuffer([]byte{})
+	now := loggyTestTime
+	options := Options{
+		Out:                 stdout,
+		Err:                 stderr,
+		Threshold:           LevelDebug,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		TimestampFunc: func() time.Time {
+			return now
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	_, ctx = l.AddTag(ctx, "request", "abc")
+
+	r := httptest.NewRequest("GET", "/chat", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("Sec-WebSocket-Protocol", "chat.v1")
+	ws, ctx := UpgradeWebSocket(ctx, l, r, "conn-1")
+
+	assert.Nil(t, ws.Pong(ctx, 15*time.Millisecond))
+	now = now.Add(time.Minute)
+	assert.Nil(t, ws.Close(ctx, 1000, ""))
+	assert.Nil(t, ws.Close(ctx, 1011, "database unavailable"))
+
+	assert.Equal(t, []string{
+		"INFO [request:abc, ws.id:conn-1, ws.path:/chat, ws.protocol:chat.v1, ws.remote:10.0.0.1:1234] websocket opened",
+		"DEBUG [request:abc, ws.id:conn-1, ws.latency:15ms, ws.path:/chat, ws.remote:10.0.0.1:1234] websocket pong",
+		"INFO [request:abc, ws.code:1000, ws.code_name:normal closure, ws.duration:1m0s, ws.id:conn-1, ws.path:/chat, ws.remote:10.0.0.1:1234] websocket closed",
+	}, strings.Split(strings.TrimSpace(stdout.String()), "\n"))
+	assert.Equal(t,
+		"WARN [request:abc, ws.code:1011, ws.code_name:internal error, ws.duration:1m0s, ws.id:conn-1, ws.path:/chat, ws.reason:database unavailable, ws.remote:10.0.0.1:1234] websocket closed\n",
+		stderr.String(),
+	)
+}
@@ -0,0 +1,110 @@
+package loggy
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Window decides when a WindowWriter is active.
+type Window interface {
+	Active(t time.Time) bool
+}
+
+// DailyWindow is active between two times of day, e.g. business hours. A window
+// whose End is before its Start spans midnight, e.g. 22:00 to 06:00.
+type DailyWindow struct {
+	// The times of day the window opens and closes, as durations since midnight,
+	// e.g. 9*time.Hour. The window includes Start, but not End.
+	Start time.Duration
+	End   time.Duration
+	// The days the window opens on. If empty, it opens every day.
+	Days []time.Weekday
+	// The time zone the times of day are in. If nil, time.Local is used.
+	Location *time.Location
+}
+
+// Active reports whether t falls within the window.
+func (w DailyWindow) Active(t time.Time) bool {
+	location := w.Location
+	if location == nil {
+		location = time.Local
+	}
+	t = t.In(location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	sinceMidnight := t.Sub(midnight)
+
+	day := t.Weekday()
+	if w.Start <= w.End {
+		if sinceMidnight < w.Start || sinceMidnight >= w.End {
+			return false
+		}
+	} else if sinceMidnight < w.End {
+		// The early hours of a window that opened the day before.
+		day = (day + 6) % 7
+	} else if sinceMidnight < w.Start {
+		return false
+	}
+
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleWindow is active while it's switched on, e.g. for the duration of a
+// declared incident. It is safe for concurrent use.
+type ToggleWindow struct {
+	on int32
+}
+
+// Set switches the window on or off.
+func (w *ToggleWindow) Set(on bool) {
+	var flag int32
+	if on {
+		flag = 1
+	}
+	atomic.StoreInt32(&w.on, flag)
+}
+
+// Active reports whether the window is switched on, regardless of t.
+func (w *ToggleWindow) Active(t time.Time) bool {
+	return atomic.LoadInt32(&w.on) == 1
+}
+
+var _ io.Writer = &WindowWriter{}
+
+// WindowWriter forwards writes to out only while its Window is active, and
+// discards them otherwise. It's meant for streams that are only wanted some of
+// the time, e.g. a verbose destination during business hours:
+//
+//	verbose := loggy.NewWindowWriter(file, loggy.DailyWindow{Start: 9 * time.Hour, End: 17 * time.Hour})
+//	options.Out = io.MultiWriter(os.Stdout, verbose)
+type WindowWriter struct {
+	out    io.Writer
+	window Window
+	now    func() time.Time
+}
+
+// NewWindowWriter creates a WindowWriter for out.
+func NewWindowWriter(out io.Writer, window Window) *WindowWriter {
+	return &WindowWriter{
+		out:    out,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Write writes p to the destination if the window is active. Discarded data
+// counts as written.
+func (w *WindowWriter) Write(p []byte) (int, error) {
+	if !w.window.Active(w.now()) {
+		return len(p), nil
+	}
+	return w.out.Write(p)
+}
@@ -0,0 +1,94 @@
+package loggy
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var dailyWindowTestCases = []struct {
+	Name     string
+	Window   DailyWindow
+	Time     time.Time
+	Expected bool
+}{
+	{
+		Name:     "business-hours",
+		Window:   DailyWindow{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.UTC},
+		Time:     time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		Expected: true,
+	},
+	{
+		Name:     "after-hours",
+		Window:   DailyWindow{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.UTC},
+		Time:     time.Date(2006, time.January, 2, 17, 0, 0, 0, time.UTC),
+		Expected: false,
+	},
+	{
+		Name: "weekend",
+		Window: DailyWindow{
+			Start:    9 * time.Hour,
+			End:      17 * time.Hour,
+			Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Location: time.UTC,
+		},
+		Time:     time.Date(2006, time.January, 7, 12, 0, 0, 0, time.UTC),
+		Expected: false,
+	},
+	{
+		Name:     "time-zone",
+		Window:   DailyWindow{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.FixedZone("EST", -5*60*60)},
+		Time:     time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		Expected: true,
+	},
+	{
+		Name:     "overnight-evening",
+		Window:   DailyWindow{Start: 22 * time.Hour, End: 6 * time.Hour, Location: time.UTC},
+		Time:     time.Date(2006, time.January, 2, 23, 0, 0, 0, time.UTC),
+		Expected: true,
+	},
+	{
+		Name: "overnight-morning",
+		Window: DailyWindow{
+			Start:    22 * time.Hour,
+			End:      6 * time.Hour,
+			Days:     []time.Weekday{time.Friday},
+			Location: time.UTC,
+		},
+		// A Saturday morning, in the window that opened on Friday.
+		Time:     time.Date(2006, time.January, 7, 2, 0, 0, 0, time.UTC),
+		Expected: true,
+	},
+	{
+		Name:     "overnight-afternoon",
+		Window:   DailyWindow{Start: 22 * time.Hour, End: 6 * time.Hour, Location: time.UTC},
+		Time:     time.Date(2006, time.January, 2, 15, 0, 0, 0, time.UTC),
+		Expected: false,
+	},
+}
+
+func TestDailyWindow(t *testing.T) {
+	for _, testCase := range dailyWindowTestCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, testCase.Window.Active(testCase.Time))
+		})
+	}
+}
+
+func TestWindowWriter(t *testing.T) {
+	out := bytes.NewBuffer([]byte{})
+	incident := &ToggleWindow{}
+	w := NewWindowWriter(out, incident)
+
+	n, err := w.Write([]byte("before\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, n)
+
+	incident.Set(true)
+	_, _ = w.Write([]byte("during\n"))
+	incident.Set(false)
+	_, _ = w.Write([]byte("after\n"))
+
+	assert.Equal(t, "during\n", out.String())
+}
@@ -0,0 +1,104 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Worker describes a goroutine started by Logger.Go that is still running.
+type Worker struct {
+	// The name passed to Go.
+	Name string
+	// A random identifier for this goroutine, as tagged "worker.id".
+	ID string
+	// When the goroutine was started.
+	Started time.Time
+}
+
+// workers tracks the goroutines started by a logger, and every logger derived
+// from it.
+type workers struct {
+	mux     sync.Mutex
+	running map[string]Worker
+}
+
+// Go runs fn in a new goroutine, with a context tagged with the name and a
+// unique ID, as "worker.name" and "worker.id". The start is logged at
+// LevelInfo, as is the stop, along with the duration, as "worker.duration".
+// If fn returns an error other than a context cancellation, it's logged at
+// LevelError. A panic is logged at LevelCritical with its stack trace, and is
+// not resumed, so that one failed worker doesn't bring down the process. The
+// start is attributed to the function that called Go; the other messages are
+// logged from the goroutine, so they have no caller. The goroutine is listed
+// by Workers until fn returns.
+func (l *logger) Go(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	worker := Worker{
+		Name:    name,
+		ID:      newRunID(),
+		Started: l.currentOptions().TimestampFunc(),
+	}
+	_, ctx = l.AddTag(ctx, "worker.name", worker.Name)
+	_, ctx = l.AddTag(ctx, "worker.id", worker.ID)
+
+	registry := l.root().workers
+	registry.mux.Lock()
+	registry.running[worker.ID] = worker
+	registry.mux.Unlock()
+
+	_, _ = l.emit(ctx, 2, LevelInfo, "worker started")
+	go func() {
+		defer func() {
+			registry.mux.Lock()
+			delete(registry.running, worker.ID)
+			registry.mux.Unlock()
+		}()
+		duration := func() map[string]interface{} {
+			return map[string]interface{}{
+				"worker.duration": l.currentOptions().TimestampFunc().Sub(worker.Started),
+			}
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				fields := duration()
+				fields["panic"] = fmt.Sprint(r)
+				stack := strings.TrimSuffix(string(debug.Stack()), "\n")
+				_ = l.Logf(ctx, LevelCritical, "worker panicked", SkipCaller(), Fields(fields), withStack(stack))
+			}
+		}()
+
+		err := fn(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			fields := duration()
+			fields["error"] = err
+			_ = l.Logf(ctx, LevelError, "worker failed", SkipCaller(), Fields(fields))
+			return
+		}
+		_ = l.Logf(ctx, LevelInfo, "worker stopped", SkipCaller(), Fields(duration()))
+	}()
+}
+
+// Workers lists the goroutines started by Go, via this logger or any logger
+// related to it, that are still running, oldest first.
+func (l *logger) Workers() []Worker {
+	registry := l.root().workers
+	registry.mux.Lock()
+	defer registry.mux.Unlock()
+
+	running := make([]Worker, 0, len(registry.running))
+	for _, worker := range registry.running {
+		running = append(running, worker)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		if !running[i].Started.Equal(running[j].Started) {
+			return running[i].Started.Before(running[j].Started)
+		}
+		return running[i].Name < running[j].Name
+	})
+	return running
+}
@@ -0,0 +1,111 @@
+package loggy
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newWorkerTestLogger() (*logger, context.Context, *lockedBuffer) {
+	out := &lockedBuffer{}
+	options := Options{
+		Out:                 out,
+		Err:                 out,
+		Threshold:           LevelInfo,
+		DisableFunctionName: true,
+		DisableTimestamps:   true,
+		TimestampFunc: func() time.Time {
+			return loggyTestTime
+		},
+	}
+	l, ctx := New(context.Background(), options)
+	return l, ctx, out
+}
+
+func TestLogger_Go(t *testing.T) {
+	l, ctx, out := newWorkerTestLogger()
+	ctx, cancel := context.WithCancel(ctx)
+	started := make(chan string)
+
+	l.Go(ctx, "poller", func(ctx context.Context) error {
+		started <- l.Tag(ctx, "worker.id").(string)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	id := <-started
+	assert.Equal(t, []Worker{{Name: "poller", ID: id, Started: loggyTestTime}}, l.Delegate(Delegation{}).Workers())
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return len(l.Workers()) == 0
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return strings.Count(out.String(), "\n") == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t,
+		"INFO [worker.id:"+id+", worker.name:poller] worker started\n"+
+			"INFO [worker.duration:0s, worker.id:"+id+", worker.name:poller] worker stopped\n",
+		out.String(),
+	)
+}
+
+func TestLogger_Go_Error(t *testing.T) {
+	l, ctx, out := newWorkerTestLogger()
+
+	l.Go(ctx, "sync", func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "worker failed")
+	}, time.Second, time.Millisecond)
+	assert.Regexp(t,
+		regexp.MustCompile(`\nERROR \[error:unreachable \(\*errors.errorString\), worker.duration:0s, worker.id:[0-9a-f]{16}, worker.name:sync\] worker failed\n$`),
+		out.String(),
+	)
+}
+
+func TestLogger_Go_Panic(t *testing.T) {
+	l, ctx, out := newWorkerTestLogger()
+
+	l.Go(ctx, "crasher", func(ctx context.Context) error {
+		panic("boom")
+	})
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "worker panicked")
+	}, time.Second, time.Millisecond)
+	assert.Regexp(t,
+		regexp.MustCompile(`(?s)\nCRIT \[panic:boom, worker.duration:0s, worker.id:[0-9a-f]{16}, worker.name:crasher\] worker panicked\ngoroutine [0-9]+ \[running\]:\n.*loggy.TestLogger_Go_Panic`),
+		out.String(),
+	)
+	assert.Eventually(t, func() bool {
+		return len(l.Workers()) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestLogger_Go_Caller(t *testing.T) {
+	out := &lockedBuffer{}
+	l, ctx := New(context.Background(), Options{
+		Out:               out,
+		Err:               out,
+		Threshold:         LevelInfo,
+		DisableTimestamps: true,
+	})
+
+	l.Go(ctx, "crasher", func(ctx context.Context) error {
+		panic("boom")
+	})
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "worker panicked")
+	}, time.Second, time.Millisecond)
+
+	lines := strings.Split(out.String(), "\n")
+	assert.Contains(t, lines[0], "TestLogger_Go_Caller")
+	assert.Contains(t, lines[0], "worker started")
+	assert.NotContains(t, out.String(), "runtime.goexit")
+	assert.NotContains(t, out.String(), "runtime.gopanic():")
+	assert.Regexp(t, regexp.MustCompile(`(?m)^CRIT \[panic:boom, .*\] worker panicked$`), out.String())
+}
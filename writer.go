@@ -1,18 +1,40 @@
 package loggy
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 )
 
 var _ io.Writer = &Writer{}
 
 // WriteFn is a handler for processing each individual byte slice before it gets
-// sent to the target stream.
+// sent to the target stream. The out writer retries short writes to the target
+// stream, and passes Flush and Close through to it; its Unwrap method returns
+// the target stream itself, e.g. for type assertions.
 type WriteFn = func(out io.Writer, p []byte) error
 
 type Writer struct {
 	handler WriteFn
 	out     io.Writer
+	// Whether the handler is called once per line, rather than once per write.
+	lines bool
+}
+
+// LineError is returned by a line Writer when the handler fails to process one
+// of the lines in a write.
+type LineError struct {
+	// The 1-based number of the failed line, counted from the start of the write.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
 }
 
 func DefaultWriteFn(out io.Writer, p []byte) error {
@@ -29,15 +51,114 @@ func NewWriter(out io.Writer, fn WriteFn) *Writer {
 	}
 }
 
+// NewLineWriter creates a Writer that calls fn once for each line of a write,
+// including the trailing newline. When fn fails, the write stops and reports the
+// number of bytes in the lines that were processed, and any part of the failed
+// line that was written, along with a LineError.
+func NewLineWriter(out io.Writer, fn WriteFn) *Writer {
+	w := NewWriter(out, fn)
+	w.lines = true
+	return w
+}
+
+// Write passes p to the handler. When the handler fails, it reports the number
+// of bytes the handler wrote to the target stream, up to len(p).
 func (w *Writer) Write(p []byte) (n int, err error) {
 	if len(p) == 0 {
 		return
 	}
-	err = w.handler(w.out, p)
+	out := &fullWriter{out: w.out}
+	if w.lines {
+		return w.writeLines(out, p)
+	}
+	err = w.handler(out, p)
 	if err != nil {
+		n = minInt(out.written, len(p))
 		return
 	}
 
 	n = len(p)
 	return
 }
+
+func (w *Writer) writeLines(out *fullWriter, p []byte) (n int, err error) {
+	for line := 1; n < len(p); line++ {
+		end := bytes.IndexByte(p[n:], '\n') + 1
+		if end == 0 {
+			end = len(p) - n
+		}
+		out.written = 0
+		err = w.handler(out, p[n:n+end])
+		if err != nil {
+			n += minInt(out.written, end)
+			err = &LineError{Line: line, Err: err}
+			return
+		}
+		n += end
+	}
+	return
+}
+
+// Flush flushes the target stream, if it supports flushing or syncing.
+func (w *Writer) Flush() error {
+	return flushWriter(w.out)
+}
+
+// Close closes the target stream, if it's an io.Closer.
+func (w *Writer) Close() error {
+	if closer, ok := w.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// fullWriter retries short writes until every byte is written, or the
+// underlying writer returns an error.
+type fullWriter struct {
+	out io.Writer
+	// The number of bytes written to out.
+	written int
+}
+
+func (w *fullWriter) Write(p []byte) (n int, err error) {
+	for n < len(p) {
+		var written int
+		written, err = w.out.Write(p[n:])
+		n += written
+		w.written += written
+		if err != nil {
+			return
+		}
+		if written == 0 {
+			// Avoid retrying forever when no progress is made.
+			err = io.ErrShortWrite
+			return
+		}
+	}
+	return
+}
+
+// Unwrap returns the underlying writer.
+func (w *fullWriter) Unwrap() io.Writer {
+	return w.out
+}
+
+// Flush flushes the underlying writer, if it supports flushing or syncing.
+func (w *fullWriter) Flush() error {
+	return flushWriter(w.out)
+}
+
+// Close closes the underlying writer, if it's an io.Closer.
+func (w *fullWriter) Close() error {
+	if closer, ok := w.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package loggy
 import (
 	"bytes"
 	"errors"
+	"github.com/stretchr/testify/assert"
 	"io"
 	"testing"
 )
@@ -92,3 +93,95 @@ func TestWriter_Write(t *testing.T) {
 		})
 	}
 }
+
+// shortWriter accepts at most max bytes per write.
+type shortWriter struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		p = p[:w.max]
+	}
+	return w.buf.Write(p)
+}
+
+func TestWriter_Write_ShortWrites(t *testing.T) {
+	out := &shortWriter{max: 3}
+	w := NewWriter(out, DefaultWriteFn)
+
+	n, err := w.Write([]byte("Hello, short writes!"))
+	assert.Nil(t, err)
+	assert.Equal(t, 20, n)
+	assert.Equal(t, "Hello, short writes!", out.buf.String())
+}
+
+func TestWriter_Write_NoProgress(t *testing.T) {
+	out := &shortWriter{max: 0}
+	w := NewWriter(out, DefaultWriteFn)
+
+	_, err := w.Write([]byte("stuck"))
+	assert.Equal(t, io.ErrShortWrite, err)
+}
+
+func TestWriter_Write_Lines(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	failure := errors.New("no warnings allowed")
+	w := NewLineWriter(stdout, func(out io.Writer, p []byte) error {
+		if bytes.HasPrefix(p, []byte("WARN")) {
+			return failure
+		}
+		return DefaultWriteFn(out, p)
+	})
+
+	n, err := w.Write([]byte("INFO one\nINFO two"))
+	assert.Nil(t, err)
+	assert.Equal(t, 17, n)
+
+	n, err = w.Write([]byte("INFO three\nWARN four\nINFO five\n"))
+	assert.Equal(t, 11, n)
+	assert.Equal(t, &LineError{Line: 2, Err: failure}, err)
+	assert.True(t, errors.Is(err, failure))
+	assert.Equal(t, "line 2: no warnings allowed", err.Error())
+	assert.Equal(t, "INFO one\nINFO twoINFO three\n", stdout.String())
+}
+
+func TestWriter_Write_PartialFailure(t *testing.T) {
+	stdout := bytes.NewBuffer([]byte{})
+	failure := errors.New("out of space")
+	w := NewWriter(stdout, func(out io.Writer, p []byte) error {
+		if _, err := out.Write(p[:5]); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	n, err := w.Write([]byte("Hello, partial!"))
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, "Hello", stdout.String())
+}
+
+func TestWriter_Unwrap(t *testing.T) {
+	sink := &closeRecorder{}
+	flushed := []string{}
+	buffered := &struct {
+		io.Writer
+		*flushRecorder
+	}{sink, &flushRecorder{name: "sink", flushed: &flushed}}
+	w := NewWriter(buffered, func(out io.Writer, p []byte) error {
+		unwrapped := out.(interface{ Unwrap() io.Writer }).Unwrap()
+		assert.Equal(t, buffered, unwrapped)
+		if err := flushWriter(out); err != nil {
+			return err
+		}
+		return DefaultWriteFn(out, p)
+	})
+
+	_, err := w.Write([]byte("hello"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Flush())
+	assert.Equal(t, []string{"sink", "sink"}, flushed)
+	assert.Equal(t, "hello", sink.String())
+}